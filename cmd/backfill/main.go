@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"order/config"
+	"order/internal/backfill"
+	"order/internal/codec"
+	"order/internal/service"
+	"order/internal/storage"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func main() {
+	batchSize := flag.Int("batch", 500, "orders per transaction")
+	checkpointPath := flag.String("checkpoint", "backfill.checkpoint.json", "checkpoint file for resuming file inputs, stdin is never resumed (empty to disable)")
+	rejectsPath := flag.String("rejects", "backfill-rejects.ndjson", "report of rejected orders (NDJSON)")
+	progress := flag.Duration("progress", 10*time.Second, "progress report interval")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] <file|glob|-> ...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Ошибка env: %v", err)
+	}
+
+	inputs, err := backfill.Inputs(flag.Args(), os.Stdin)
+	if err != nil {
+		log.Fatalf("Invalid inputs: %v", err)
+	}
+
+	checkpoint, err := backfill.LoadCheckpoint(*checkpointPath)
+	if err != nil {
+		log.Fatalf("Failed to load checkpoint: %v", err)
+	}
+
+	// Новый прогон начинает отчёт заново, продолженный — дописывает
+	mode := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if len(checkpoint.Inputs) > 0 {
+		mode = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	rejects, err := os.OpenFile(*rejectsPath, mode, 0o644)
+	if err != nil {
+		log.Fatalf("Failed to open rejects report: %v", err)
+	}
+	defer rejects.Close()
+
+	// Получаем *sql.DB и repo
+	db, repo, err := storage.NewDatabaseConnection(cfg)
+	if err != nil {
+		log.Fatalf("Ошибка при подключении к базе данных: %v", err)
+	}
+	defer db.Close()
+
+	// Инициализация базы
+	if err := storage.PrepareDB(context.Background(), db, cfg.DB.AutoMigrate); err != nil {
+		log.Fatalf("Ошибка при инициализации базы: %v", err)
+	}
+
+	// Дампы старой системы — NDJSON; старые версии формы заказа приводятся к текущей
+	decoder, err := codec.NewDecoder(codec.FormatJSON, nil, codec.DefaultVersions)
+	if err != nil {
+		log.Fatalf("Failed to create message decoder: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	runner := backfill.New(decoder, service.NewService(repo), backfill.Options{
+		BatchSize:  *batchSize,
+		Progress:   *progress,
+		Checkpoint: checkpoint,
+		Rejects:    rejects,
+	})
+	summary, err := runner.Run(ctx, inputs)
+	log.Printf("Backfill summary: %s", summary)
+	if err != nil {
+		log.Printf("Backfill stopped: %v", err)
+		if *checkpointPath != "" {
+			log.Printf("Run the same command again to resume file inputs from %s", *checkpointPath)
+		}
+		rejects.Close()
+		db.Close()
+		os.Exit(1)
+	}
+	if summary.Rejected > 0 {
+		log.Printf("Rejected orders are listed in %s", *rejectsPath)
+	}
+}
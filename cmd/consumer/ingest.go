@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"log"
+	"order/config"
+	"order/internal/codec"
+	"order/internal/controller/kafka"
+	"order/internal/controller/nats"
+	"order/internal/service"
+	"strings"
+)
+
+// consumer — запущенный источник заказов
+type consumer struct {
+	name string
+	ctrl interface {
+		Consume(ctx context.Context) error
+		Shutdown(ctx context.Context) error
+		Close() error
+	}
+}
+
+// ingestion — всё, что нужно процессу для приёма заказов
+type ingestion struct {
+	consumers  []consumer
+	kafka      kafka.KafkaController // nil, если Kafka не используется
+	quarantine kafka.Quarantine      // nil, если Kafka не используется
+	replayer   kafka.Replayer        // nil, если Kafka не используется
+}
+
+// newIngestion создаёт контроллеры включённых источников заказов
+func newIngestion(cfg *config.Config, svc service.Service, health kafka.Health) ingestion {
+	// Декодер сообщений: JSON или Avro/Protobuf через Schema Registry
+	var registry codec.Registry
+	if cfg.Kafka.SchemaRegistryURL != "" {
+		registry = codec.NewRegistryClient(cfg.Kafka.SchemaRegistryURL)
+	}
+	decoder, err := codec.NewDecoder(codec.Format(cfg.Kafka.ValueFormat), registry, codec.DefaultVersions)
+	if err != nil {
+		log.Fatalf("Failed to create message decoder: %v", err)
+	}
+
+	var in ingestion
+	if cfg.Ingest.Enabled(config.SourceKafka) {
+		// Проверка Kafka конфигурации
+		bootstrapServers := cfg.Kafka.BootstrapServers()
+		log.Printf("Kafka bootstrap.servers: %s, topics: %s, group: %s",
+			bootstrapServers, strings.Join(cfg.Kafka.SubscribeTopics(), ","), cfg.Kafka.GroupName)
+
+		// Общие настройки клиентов Kafka (TLS, SASL, KAFKA_PROPERTIES)
+		kafkaConfig, err := kafka.NewConfigMap(cfg.Kafka, bootstrapServers)
+		if err != nil {
+			log.Fatalf("Invalid Kafka configuration: %v", err)
+		}
+
+		// Карантин для сообщений неизвестной версии схемы из Kafka (у NATS — свой субъект)
+		in.quarantine, err = kafka.NewQuarantine(kafkaConfig, cfg.Kafka.QuarantineTopicName())
+		if err != nil {
+			log.Fatalf("Failed to create quarantine producer: %v", err)
+		}
+
+		// Создание Kafka-контроллера
+		in.kafka, err = kafka.NewKafkaController(
+			kafkaConfig,
+			cfg.Kafka.GroupName,
+			cfg.Kafka.SubscribeTopics(),
+			decoder,
+			in.quarantine,
+			svc,
+			cfg.Cache.Partitioned(),
+			health,
+		)
+		if err != nil {
+			log.Fatalf("Failed to create Kafka controller: %v", err)
+		}
+		in.consumers = append(in.consumers, consumer{name: "Kafka", ctrl: in.kafka})
+
+		// Replay диапазонов топика по запросу оператора
+		in.replayer = kafka.NewReplayer(kafkaConfig, cfg.Kafka.GroupName, decoder, svc)
+	}
+
+	// Создание JetStream-контроллера
+	if cfg.Ingest.Enabled(config.SourceNats) {
+		log.Printf("NATS url: %s, stream: %s, subjects: %s, durable: %s, quarantine: %s",
+			cfg.Nats.URL, cfg.Nats.Stream, strings.Join(cfg.Nats.Subjects, ","), cfg.Nats.Durable, cfg.Nats.QuarantineSubject)
+
+		natsCtrl, err := nats.NewNatsController(cfg.Nats, decoder, svc, health)
+		if err != nil {
+			log.Fatalf("Failed to create NATS controller: %v", err)
+		}
+		in.consumers = append(in.consumers, consumer{name: "NATS", ctrl: natsCtrl})
+	}
+	return in
+}
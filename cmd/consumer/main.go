@@ -2,91 +2,150 @@ package main
 
 import (
 	"context"
+	"errors"
+	"expvar"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"order/config"
+	"order/internal/breaker"
 	v1 "order/internal/controller/http/v1"
-	"order/internal/controller/kafka"
+	"order/internal/lifecycle"
+	"order/internal/rates"
 	"order/internal/service"
 	"order/internal/storage"
 	"os"
 	"os/signal"
-	"strings"
+	"sync"
 	"syscall"
 )
 
 func main() {
-	cfg, err := config.Load()
+	role := flag.String("role", "", "process role: all, api or ingest (overrides APP_ROLE)")
+	flag.Parse()
+
+	cfg, err := config.Parse()
 	if err != nil {
 		log.Fatalf("Ошибка env: %v", err)
 	}
-
-	// Проверка Kafka конфигурации
-	log.Printf("Kafka host: %s, ports: %s,%s,%s, topic: %s, group: %s",
-		cfg.Kafka.Host, cfg.Kafka.Port1, cfg.Kafka.Port2, cfg.Kafka.Port3,
-		cfg.Kafka.Topic, cfg.Kafka.GroupName)
-
-	// Формирование bootstrap.servers
-	var builder strings.Builder
-	fmt.Fprintf(&builder, "%s:%s,%s:%s,%s:%s",
-		cfg.Kafka.Host, cfg.Kafka.Port1,
-		cfg.Kafka.Host, cfg.Kafka.Port2,
-		cfg.Kafka.Host, cfg.Kafka.Port3,
-	)
-	bootstrapServers := builder.String()
-	log.Printf("Kafka bootstrap.servers: %s", bootstrapServers)
-	if bootstrapServers == "" {
-		log.Fatal("Kafka bootstrap.servers is empty")
+	if *role != "" {
+		cfg.App.Role = *role
 	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Ошибка env: %v", err)
+	}
+	log.Printf("Starting %s in role %s", cfg.App.Name, cfg.App.Role)
 
 	// Получаем *sql.DB и repo
 	db, repo, err := storage.NewDatabaseConnection(cfg)
 	if err != nil {
 		log.Fatalf("Ошибка при подключении к базе данных: %v", err)
 	}
-	defer db.Close()
 
 	// Инициализация базы
-	if err := storage.InitDB(context.Background(), db); err != nil {
+	if err := storage.PrepareDB(context.Background(), db, cfg.DB.AutoMigrate); err != nil {
 		log.Fatalf("Ошибка при инициализации базы: %v", err)
 	}
 
+	// Предохранитель базы: пока она недоступна, вызовы хранилища отклоняются сразу,
+	// а консюмеры приостанавливают чтение до успешной пробы
+	dbBreaker := breaker.New(breaker.Config{
+		Name:             "database",
+		FailureThreshold: cfg.DB.BreakerThreshold,
+		OpenTimeout:      cfg.DB.BreakerOpenTimeout,
+		MaxOpenTimeout:   cfg.DB.BreakerMaxOpenTimeout,
+		IsFailure:        storage.IsUnavailable,
+	})
+	repo = storage.NewBreakerStore(repo, dbBreaker)
+
 	// Создание сервиса
 	svc := service.NewService(repo)
 
-	// Загрузка кэша из БД
-	if err := svc.LoadCacheFromDB(context.Background()); err != nil {
-		log.Fatalf("Failed to load cache from DB: %v", err)
+	// Storage публикует order_uid каждого записанного заказа; процессы с REST API вытесняют
+	// или обновляют эти заказы в кэше. Подписка оформляется до загрузки кэша, чтобы не
+	// пропустить изменения, сделанные во время загрузки
+	var listener *storage.Listener
+	if cfg.App.ServesAPI() {
+		listener, err = storage.NewListener(cfg.DB.DSN(), svc, cfg.Cache.Refresh())
+		if err != nil {
+			log.Fatalf("Failed to listen for order notifications: %v", err)
+		}
 	}
 
-	// Создание Kafka-контроллера
-	kafkaCtrl, err := kafka.NewKafkaController(
-		bootstrapServers,
-		cfg.Kafka.GroupName,
-		cfg.Kafka.Topic,
-		svc,
-	)
-	if err != nil {
-		log.Fatalf("Failed to create Kafka controller: %v", err)
+	// Загрузка кэша из БД; в режиме partitioned кэш прогревается после назначения партиций,
+	// а процессу без REST API кэш не нужен
+	switch {
+	case !cfg.App.ServesAPI():
+	case cfg.Cache.Partitioned():
+		log.Println("Cache mode: partitioned, cache will be warmed after partition assignment")
+	default:
+		if err := svc.LoadCacheFromDB(context.Background()); err != nil {
+			log.Fatalf("Failed to load cache from DB: %v", err)
+		}
+	}
+
+	// Источники заказов: Consume каждого запускается в отдельной горутине
+	var in ingestion
+	if cfg.App.Ingests() {
+		in = newIngestion(cfg, svc, dbBreaker)
 	}
-	defer kafkaCtrl.Close()
 
-	// Создание HTTP-хендлера и роутера
-	handler := v1.NewHandler(svc)
-	router := v1.NewRouter(handler, cfg)
+	// Создание HTTP-хендлеров и роутера; процесс без REST API отдаёт только служебные эндпоинты
+	var handler *v1.Handler
+	if cfg.App.ServesAPI() {
+		handler = v1.NewHandler(svc)
+	}
+	var admin *v1.AdminHandler
+	if in.replayer != nil {
+		admin = v1.NewAdminHandler(in.replayer)
+	}
+	consumerAdmin := v1.NewConsumerAdminHandler(in.kafka, svc)
+	status := v1.NewStatusHandler(dbBreaker, in.kafka)
+	router := v1.NewRouter(handler, admin, consumerAdmin, status, cfg)
 	cors := v1.Cors(router, cfg)
 
-	// Контекст для грациозного завершения
+	// Метрики в /debug/vars
+	expvar.Publish("status", expvar.Func(func() any { return status.Snapshot() }))
+
+	// Контекст консюмеров; остановка идёт через Shutdown, отмена — крайняя мера
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Запуск консюмера в отдельной горутине
-	go func() {
-		if err := kafkaCtrl.Consume(ctx); err != nil {
-			log.Printf("Kafka consumer stopped: %v", err)
+	// Запуск консюмеров в отдельных горутинах
+	for _, c := range in.consumers {
+		go func() {
+			if err := c.ctrl.Consume(ctx); err != nil {
+				log.Printf("%s consumer stopped: %v", c.name, err)
+			}
+		}()
+	}
+	if listener != nil {
+		go func() {
+			if err := listener.Run(ctx); err != nil {
+				log.Printf("Order notifications listener stopped: %v", err)
+			}
+		}()
+	}
+
+	// Курсы валют для отчётов загружает процесс, принимающий заказы, если задан RATES_PROVIDER
+	var ratesDone chan struct{}
+	ratesCtx, stopRates := context.WithCancel(ctx)
+	defer stopRates()
+	if cfg.App.Ingests() {
+		provider, err := rates.NewProvider(cfg.Rates)
+		if err != nil {
+			log.Fatalf("Failed to create rates provider: %v", err)
 		}
-	}()
+		if provider != nil {
+			ratesDone = make(chan struct{})
+			syncer := rates.NewSyncer(provider, repo, cfg.Rates.SyncInterval, cfg.Rates.SyncDays)
+			go func() {
+				defer close(ratesDone)
+				syncer.Run(ratesCtx)
+			}()
+		}
+	}
 
 	// Запуск HTTP-сервера
 	server := &http.Server{
@@ -103,15 +162,84 @@ func main() {
 	// Ожидание сигналов для грациозного завершения
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
-	<-sigs
-
-	// Отмена контекста для остановки консюмера
-	cancel()
-
-	// Остановка HTTP-сервера
-	if err := server.Shutdown(context.Background()); err != nil {
-		log.Printf("Server shutdown error: %v", err)
+	sig := <-sigs
+	log.Printf("Received %s, shutting down (deadline %s)", sig, cfg.App.ShutdownTimeout)
+
+	// Порядок важен: сначала перестаём читать и дообрабатываем заказы, затем отдаём
+	// отложенные сообщения, закрываем HTTP и только потом базу, которой пользуются все остальные
+	var steps []lifecycle.Step
+	if len(in.consumers) > 0 {
+		steps = append(steps,
+			lifecycle.Step{Name: "drain consumers", Run: func(ctx context.Context) error {
+				return drainConsumers(ctx, in.consumers)
+			}},
+			lifecycle.Step{Name: "close consumers", Run: func(context.Context) error {
+				var errs []error
+				for _, c := range in.consumers {
+					if err := c.ctrl.Close(); err != nil {
+						errs = append(errs, fmt.Errorf("%s: %w", c.name, err))
+					}
+				}
+				return errors.Join(errs...)
+			}},
+		)
+	}
+	if in.quarantine != nil {
+		steps = append(steps, lifecycle.Step{Name: "flush quarantine", Run: in.quarantine.Close})
+	}
+	steps = append(steps,
+		lifecycle.Step{Name: "stop HTTP server", Run: func(ctx context.Context) error {
+			if err := server.Shutdown(ctx); err != nil {
+				server.Close() // Срок истёк — обрываем оставшиеся соединения
+				return err
+			}
+			return nil
+		}},
+	)
+	if listener != nil {
+		steps = append(steps, lifecycle.Step{Name: "stop notifications listener", Run: func(context.Context) error {
+			cancel()
+			return listener.Close()
+		}})
+	}
+	if ratesDone != nil {
+		steps = append(steps, lifecycle.Step{Name: "stop rates sync", Run: func(ctx context.Context) error {
+			stopRates()
+			select {
+			case <-ratesDone:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}})
+	}
+	steps = append(steps,
+		lifecycle.Step{Name: "close database", Run: func(context.Context) error {
+			return db.Close()
+		}},
+	)
+	if _, err := lifecycle.Shutdown(cfg.App.ShutdownTimeout, steps...); err != nil {
+		log.Printf("Application stopped with errors: %v", err)
+		os.Exit(1)
 	}
 
 	log.Println("Application stopped")
 }
+
+// drainConsumers останавливает чтение во всех источниках параллельно, чтобы медленный
+// источник не съедал срок остальных
+func drainConsumers(ctx context.Context, consumers []consumer) error {
+	errs := make([]error, len(consumers))
+	var wg sync.WaitGroup
+	for i, c := range consumers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.ctrl.Shutdown(ctx); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", c.name, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
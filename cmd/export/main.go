@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"order/config"
+	"order/internal/export"
+	"order/internal/storage"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+func main() {
+	format := flag.String("format", string(export.FormatCSV), "output format: csv, ndjson or parquet")
+	rows := flag.String("rows", string(export.RowsPerItem), "one row per item or per order: item, order")
+	columns := flag.String("columns", "", "comma-separated columns (default: all columns of the rows mode)")
+	filter := flag.String("filter", "", "export only orders matching <lookup key>=<value>, e.g. customer_id=test")
+	out := flag.String("out", "-", "output file (- for stdout)")
+	listColumns := flag.Bool("list-columns", false, "print available columns and exit")
+	flag.Parse()
+
+	opts := export.Options{Format: export.Format(*format), Rows: export.Rows(*rows)}
+	if *columns != "" {
+		opts.Columns = strings.Split(*columns, ",")
+	}
+	if *listColumns {
+		fmt.Println(strings.Join(export.ColumnNames(opts.Rows), "\n"))
+		return
+	}
+	if err := opts.Validate(); err != nil {
+		log.Fatalf("Invalid export options: %v", err)
+	}
+
+	var f storage.Filter
+	if *filter != "" {
+		field, value, ok := strings.Cut(*filter, "=")
+		if !ok {
+			log.Fatalf("Invalid filter %q: expected <lookup key>=<value>", *filter)
+		}
+		f = storage.Filter{Field: storage.LookupField(field), Value: value}
+	}
+	if err := f.Validate(); err != nil {
+		log.Fatalf("Invalid filter: %v", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Ошибка env: %v", err)
+	}
+
+	// Выгрузка только читает: миграции не применяются
+	db, repo, err := storage.NewDatabaseConnection(cfg)
+	if err != nil {
+		log.Fatalf("Ошибка при подключении к базе данных: %v", err)
+	}
+	defer db.Close()
+
+	output := os.Stdout
+	if *out != "-" {
+		if output, err = os.Create(*out); err != nil {
+			log.Fatalf("Failed to create output file: %v", err)
+		}
+	}
+	w := bufio.NewWriter(output)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	summary, err := export.Export(w, repo.Orders(ctx, f), opts)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil && output != os.Stdout {
+		err = output.Close()
+	}
+	if err != nil {
+		log.Printf("Export failed after %d orders: %v", summary.Orders, err)
+		db.Close()
+		os.Exit(1)
+	}
+	log.Printf("Exported %d orders (%d rows) as %s", summary.Orders, summary.Rows, opts.Format)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"order/config"
+	"order/internal/backfill"
+	"order/internal/export"
+	"order/internal/restore"
+	"order/internal/service"
+	"order/internal/storage"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func main() {
+	format := flag.String("format", string(export.FormatNDJSON), "input format: csv or ndjson (export layout)")
+	conflict := flag.String("conflict", string(service.ConflictSkip), "existing orders: skip, overwrite or fail")
+	dryRun := flag.Bool("dry-run", false, "validate inputs and report conflicts without writing")
+	batchSize := flag.Int("batch", 500, "orders per transaction")
+	rejectsPath := flag.String("rejects", "import-rejects.ndjson", "report of rejected orders (NDJSON, - for stdout)")
+	progress := flag.Duration("progress", 10*time.Second, "progress report interval")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] <file|glob|-> ...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	opts := restore.Options{
+		Format:    export.Format(*format),
+		Conflict:  service.Conflict(*conflict),
+		DryRun:    *dryRun,
+		BatchSize: *batchSize,
+		Progress:  *progress,
+	}
+	if err := opts.Validate(); err != nil {
+		log.Fatalf("Invalid import options: %v", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Ошибка env: %v", err)
+	}
+
+	inputs, err := backfill.Inputs(flag.Args(), os.Stdin)
+	if err != nil {
+		log.Fatalf("Invalid inputs: %v", err)
+	}
+
+	var rejects io.WriteCloser = os.Stdout
+	if *rejectsPath != "-" {
+		if rejects, err = os.Create(*rejectsPath); err != nil {
+			log.Fatalf("Failed to open rejects report: %v", err)
+		}
+		defer rejects.Close()
+	}
+	opts.Rejects = rejects
+
+	// Получаем *sql.DB и repo
+	db, repo, err := storage.NewDatabaseConnection(cfg)
+	if err != nil {
+		log.Fatalf("Ошибка при подключении к базе данных: %v", err)
+	}
+	defer db.Close()
+
+	// Пробный прогон не меняет базу, в том числе её схему
+	if !*dryRun {
+		if err := storage.PrepareDB(context.Background(), db, cfg.DB.AutoMigrate); err != nil {
+			log.Fatalf("Ошибка при инициализации базы: %v", err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	summary, err := restore.New(service.NewService(repo), opts).Run(ctx, inputs)
+	log.Printf("Import summary: %s", summary)
+	if err != nil {
+		log.Printf("Import stopped: %v", err)
+		rejects.Close()
+		db.Close()
+		os.Exit(1)
+	}
+	if summary.Rejected > 0 || summary.UnidentifiedRows > 0 {
+		log.Printf("Rejected orders and rows without order_uid are listed in %s", *rejectsPath)
+	}
+}
@@ -0,0 +1,552 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"order/internal/backfill"
+	"order/internal/controller/kafka"
+	"order/internal/entity"
+	"order/internal/export"
+	"order/internal/rates"
+	"order/internal/report"
+	"order/internal/restore"
+	"order/internal/service"
+	"order/internal/storage"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func runGet(ctx context.Context, env *env, args []string) error {
+	fs := newFlagSet("get")
+	if err := parse(fs, args, 1, 1); err != nil {
+		return err
+	}
+	reader, err := env.reader()
+	if err != nil {
+		return err
+	}
+	order, err := reader.GetOrder(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return env.out.print(order, func(t *tableWriter) {
+		orderTable(t, []entity.Order{order})
+		t.blank()
+		t.row("DELIVERY", "")
+		t.row("name", order.Delivery.Name)
+		t.row("phone", order.Delivery.Phone)
+		t.row("email", order.Delivery.Email)
+		address := slices.DeleteFunc([]string{order.Delivery.Zip, order.Delivery.Region, order.Delivery.City, order.Delivery.Address},
+			func(part string) bool { return part == "" })
+		t.row("address", strings.Join(address, ", "))
+		t.blank()
+		t.row("PAYMENT", "")
+		t.row("transaction", order.Payment.Transaction)
+		t.row("amount", fmt.Sprintf("%s %s", order.Payment.Amount, order.Payment.Currency))
+		t.row("paid at", entity.FormatTime(order.Payment.PaymentDt))
+		t.row("provider", order.Payment.Provider)
+		t.row("bank", order.Payment.Bank)
+		t.blank()
+		t.row("CHRT_ID", "NM_ID", "NAME", "BRAND", "SIZE", "PRICE", "SALE", "TOTAL", "STATUS")
+		for _, item := range order.Items {
+			t.row(item.ChrtID, item.NmID, item.Name, item.Brand, item.Size, item.Price, item.Sale, item.TotalPrice, item.Status)
+		}
+	})
+}
+
+func runList(ctx context.Context, env *env, args []string) error {
+	fs := newFlagSet("list")
+	limit := fs.Int("limit", storage.DefaultLookupLimit, "maximum number of orders (0 lists all orders when no key is given)")
+	if err := parse(fs, args, 0, 1); err != nil {
+		return err
+	}
+	reader, err := env.reader()
+	if err != nil {
+		return err
+	}
+
+	var orders []entity.Order
+	if fs.NArg() == 1 {
+		field, value, ok := strings.Cut(fs.Arg(0), "=")
+		if !ok {
+			fs.Usage()
+			return errUsage
+		}
+		orders, err = reader.FindOrders(ctx, storage.Lookup{Field: storage.LookupField(field), Value: value, Limit: *limit})
+		if err != nil {
+			return err
+		}
+	} else {
+		// Без ключа — все заказы по возрастанию order_uid, обход прерывается на limit
+		for order, err := range reader.Orders(ctx, storage.Filter{}) {
+			if err != nil {
+				return err
+			}
+			orders = append(orders, order)
+			if len(orders) == *limit {
+				break
+			}
+		}
+	}
+	if orders == nil {
+		orders = []entity.Order{}
+	}
+	return env.out.print(orders, func(t *tableWriter) { orderTable(t, orders) })
+}
+
+func runSearch(ctx context.Context, env *env, args []string) error {
+	fs := newFlagSet("search")
+	limit := fs.Int("limit", 0, "page size (default 20)")
+	offset := fs.Int("offset", 0, "number of hits to skip")
+	if err := parse(fs, args, 1, -1); err != nil {
+		return err
+	}
+	reader, err := env.reader()
+	if err != nil {
+		return err
+	}
+	page, err := reader.SearchOrders(ctx, storage.Search{Text: strings.Join(fs.Args(), " "), Limit: *limit, Offset: *offset})
+	if err != nil {
+		return err
+	}
+	return env.out.print(page, func(t *tableWriter) {
+		t.row("RANK", "ORDER_UID", "CUSTOMER", "DATE_CREATED", "HIGHLIGHTS")
+		for _, hit := range page.Hits {
+			t.row(strconv.FormatFloat(hit.Rank, 'f', 3, 64), hit.Order.OrderUID, hit.Order.CustomerID,
+				entity.FormatTime(hit.Order.DateCreated), strings.Join(hit.Highlights, " | "))
+		}
+		t.blank()
+		t.row(fmt.Sprintf("%d-%d of %d", page.Offset+min(1, len(page.Hits)), page.Offset+len(page.Hits), page.Total))
+	})
+}
+
+// orderTable — краткая таблица заказов
+func orderTable(t *tableWriter, orders []entity.Order) {
+	t.row("ORDER_UID", "TRACK_NUMBER", "CUSTOMER", "DATE_CREATED", "ITEMS", "AMOUNT")
+	for _, order := range orders {
+		t.row(order.OrderUID, order.TrackNumber, order.CustomerID, entity.FormatTime(order.DateCreated), len(order.Items),
+			fmt.Sprintf("%s %s", order.Payment.Amount, order.Payment.Currency))
+	}
+}
+
+// runExport пишет выгрузку сам (из БД) или отдаёт ответ /orders/export как есть (через API)
+func runExport(ctx context.Context, env *env, args []string) error {
+	fs := newFlagSet("export")
+	format := fs.String("format", string(export.FormatCSV), "output format: csv, ndjson or parquet")
+	rows := fs.String("rows", string(export.RowsPerItem), "one row per item or per order: item, order")
+	columns := fs.String("columns", "", "comma-separated columns (default: all columns of the rows mode)")
+	filter := fs.String("filter", "", "export only orders matching <lookup key>=<value>")
+	out := fs.String("out", "-", "output file (- for stdout)")
+	if err := parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+	opts := export.Options{Format: export.Format(*format), Rows: export.Rows(*rows)}
+	if *columns != "" {
+		opts.Columns = strings.Split(*columns, ",")
+	}
+	var f storage.Filter
+	if *filter != "" {
+		field, value, ok := strings.Cut(*filter, "=")
+		if !ok {
+			return fmt.Errorf("invalid filter %q: expected <lookup key>=<value>", *filter)
+		}
+		f = storage.Filter{Field: storage.LookupField(field), Value: value}
+	}
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	if err := f.Validate(); err != nil {
+		return err
+	}
+
+	output := os.Stdout
+	if *out != "-" {
+		var err error
+		if output, err = os.Create(*out); err != nil {
+			return err
+		}
+		defer output.Close()
+	}
+	w := bufio.NewWriter(output)
+
+	if env.apiURL != "" {
+		api, _ := env.api()
+		if err := api.Export(ctx, w, f, opts); err != nil {
+			return err
+		}
+	} else {
+		store, err := env.database()
+		if err != nil {
+			return err
+		}
+		summary, err := export.Export(w, store.Orders(ctx, f), opts)
+		if err != nil {
+			return fmt.Errorf("export failed after %d orders: %w", summary.Orders, err)
+		}
+		fmt.Fprintf(os.Stderr, "Exported %d orders (%d rows) as %s\n", summary.Orders, summary.Rows, opts.Format)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if output != os.Stdout {
+		return output.Close()
+	}
+	return nil
+}
+
+// runImport — то же, что cmd/import, но не применяет миграции: схема должна быть актуальной
+func runImport(ctx context.Context, env *env, args []string) error {
+	fs := newFlagSet("import")
+	format := fs.String("format", string(export.FormatNDJSON), "input format: csv or ndjson (export layout)")
+	conflict := fs.String("conflict", string(service.ConflictSkip), "existing orders: skip, overwrite or fail")
+	dryRun := fs.Bool("dry-run", false, "validate inputs and report conflicts without writing")
+	batchSize := fs.Int("batch", 500, "orders per transaction")
+	rejectsPath := fs.String("rejects", "import-rejects.ndjson", "report of rejected orders (NDJSON)")
+	if err := parse(fs, args, 1, -1); err != nil {
+		return err
+	}
+	opts := restore.Options{
+		Format:    export.Format(*format),
+		Conflict:  service.Conflict(*conflict),
+		DryRun:    *dryRun,
+		BatchSize: *batchSize,
+	}
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	inputs, err := backfill.Inputs(fs.Args(), os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	store, err := env.database()
+	if err != nil {
+		return err
+	}
+	if err := storage.CheckSchema(ctx, env.db); err != nil {
+		return err
+	}
+
+	rejects, err := os.Create(*rejectsPath)
+	if err != nil {
+		return err
+	}
+	defer rejects.Close()
+	opts.Rejects = rejects
+
+	summary, runErr := restore.New(service.NewService(store), opts).Run(ctx, inputs)
+	err = env.out.print(summary, func(t *tableWriter) {
+		t.row("INPUTS", "ORDERS", "NEW", "UPDATED", "SKIPPED", "REJECTED", "NO_UID_ROWS", "DRY_RUN", "TOOK")
+		t.row(summary.Inputs, summary.Orders, summary.New, summary.Updated, summary.Skipped, summary.Rejected,
+			summary.UnidentifiedRows, summary.DryRun, summary.Duration.Round(time.Millisecond))
+	})
+	if runErr != nil {
+		return runErr
+	}
+	if summary.Rejected > 0 || summary.UnidentifiedRows > 0 {
+		fmt.Fprintf(os.Stderr, "Rejected orders and rows without order_uid are listed in %s\n", *rejectsPath)
+	}
+	return err
+}
+
+func runReplay(ctx context.Context, env *env, args []string) error {
+	fs := newFlagSet("replay")
+	topic := fs.String("topic", "", "topic to replay (required)")
+	from := fs.String("from", "", "start offset or RFC 3339 time (default: beginning of the partition)")
+	to := fs.String("to", "", "end offset or RFC 3339 time, exclusive (default: end of the partition)")
+	partition := fs.Int("partition", -1, "replay only this partition (default: all partitions)")
+	if err := parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if *topic == "" {
+		fs.Usage()
+		return errUsage
+	}
+	req := kafka.ReplayRequest{Topic: *topic}
+	var err error
+	if req.From, err = parseBound(*from); err != nil {
+		return err
+	}
+	if req.To, err = parseBound(*to); err != nil {
+		return err
+	}
+	if *partition >= 0 {
+		req.Ranges = []kafka.PartitionRange{{Partition: int32(*partition), From: req.From, To: req.To}}
+	}
+
+	api, err := env.api()
+	if err != nil {
+		return err
+	}
+	report, err := api.Replay(ctx, req)
+	if err != nil {
+		return err
+	}
+	return env.out.print(report, func(t *tableWriter) {
+		t.row("TOPIC", "NEW", "UPDATED", "UNCHANGED", "REJECTED", "FAILED")
+		t.row(report.Topic, report.New, report.Updated, report.Unchanged, report.Rejected, report.Failed)
+		t.blank()
+		t.row("PARTITION", "FROM_OFFSET", "TO_OFFSET", "PROCESSED", "COMPLETE")
+		for _, p := range report.Partitions {
+			t.row(p.Partition, p.FromOffset, p.ToOffset, p.Processed, p.Complete)
+		}
+	})
+}
+
+// parseBound разбирает границу replay: число — смещение, иначе время RFC 3339
+func parseBound(value string) (kafka.Bound, error) {
+	if value == "" {
+		return kafka.Bound{}, nil
+	}
+	if offset, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return kafka.Bound{Offset: &offset}, nil
+	}
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return kafka.Bound{}, fmt.Errorf("invalid bound %q: expected an offset or RFC 3339 time", value)
+	}
+	return kafka.Bound{Time: &at}, nil
+}
+
+// runMigrate меняет схему встроенными миграциями; всегда печатает итоговое состояние
+func runMigrate(ctx context.Context, env *env, args []string) error {
+	fs := newFlagSet("migrate")
+	if err := parse(fs, args, 1, 2); err != nil {
+		return err
+	}
+	action, arg := fs.Arg(0), fs.Arg(1)
+	// status и up не принимают аргумент, to и force требуют его, down — по желанию
+	switch {
+	case (action == "status" || action == "up") && arg != "",
+		(action == "to" || action == "force") && arg == "",
+		!slices.Contains([]string{"status", "up", "down", "to", "force"}, action):
+		fs.Usage()
+		return errUsage
+	}
+	if _, err := env.database(); err != nil {
+		return err
+	}
+
+	if action != "status" {
+		m, err := storage.NewMigrator(ctx, env.db)
+		if err != nil {
+			return err
+		}
+		err = migrate(m, action, arg)
+		if closeErr := m.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	status, err := storage.GetMigrationStatus(ctx, env.db)
+	if err != nil {
+		return err
+	}
+	return env.out.print(status, func(t *tableWriter) {
+		t.row("VERSION", "LATEST", "DIRTY", "PENDING")
+		t.row(status.Version, status.Latest, status.Dirty, status.Pending())
+	})
+}
+
+func migrate(m *storage.Migrator, action, arg string) error {
+	switch action {
+	case "up":
+		return m.Up()
+	case "down":
+		steps := 1
+		if arg != "" {
+			n, err := strconv.Atoi(arg)
+			if err != nil || n <= 0 {
+				return fmt.Errorf("invalid number of steps %q", arg)
+			}
+			steps = n
+		}
+		return m.Down(steps)
+	case "to":
+		version, err := strconv.ParseUint(arg, 10, 0)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", arg)
+		}
+		return m.To(uint(version))
+	default: // force
+		version, err := strconv.Atoi(arg)
+		if err != nil || version < -1 {
+			return fmt.Errorf("invalid version %q", arg)
+		}
+		return m.Force(version)
+	}
+}
+
+func runCache(ctx context.Context, env *env, args []string) error {
+	fs := newFlagSet("cache")
+	if err := parse(fs, args, 1, 1); err != nil {
+		return err
+	}
+	if fs.Arg(0) != "flush" {
+		fs.Usage()
+		return errUsage
+	}
+	api, err := env.api()
+	if err != nil {
+		return err
+	}
+	resp, err := api.FlushCache(ctx)
+	if err != nil {
+		return err
+	}
+	return env.out.print(resp, func(t *tableWriter) {
+		t.row("EVICTED", "SIZE")
+		t.row(resp.Evicted, resp.Size)
+	})
+}
+
+// rateRow — курс для вывода: десятичной строкой, а не дробью big.Rat
+type rateRow struct {
+	Day      string `json:"day"`
+	Base     string `json:"base"`
+	Currency string `json:"currency"`
+	Rate     string `json:"rate"`
+}
+
+// runRates загружает курсы из CSV-файла или источника RATES_PROVIDER либо показывает сохранённые
+func runRates(ctx context.Context, env *env, args []string) error {
+	fs := newFlagSet("rates")
+	file := fs.String("file", "", "CSV file with day,base,currency,rate (load; default: RATES_PROVIDER)")
+	fromFlag := fs.String("from", "", "first day, YYYY-MM-DD (default: RATES_SYNC_DAYS before -to)")
+	toFlag := fs.String("to", "", "last day, YYYY-MM-DD (default: today)")
+	// Действие идёт перед флагами: rates load -file f
+	if len(args) == 0 || (args[0] != "load" && args[0] != "list") {
+		fs.Usage()
+		return errUsage
+	}
+	action := args[0]
+	if err := parse(fs, args[1:], 0, 0); err != nil {
+		return err
+	}
+	store, err := env.database()
+	if err != nil {
+		return err
+	}
+	// Таблица exchange_rates появилась в миграции 000006
+	if err := storage.CheckSchema(ctx, env.db); err != nil {
+		return err
+	}
+	to, err := parseDay("to", *toFlag, entity.Day(time.Now()))
+	if err != nil {
+		return err
+	}
+	from, err := parseDay("from", *fromFlag, to.AddDate(0, 0, 1-env.cfg.Rates.SyncDays))
+	if err != nil {
+		return err
+	}
+
+	if action == "load" {
+		var provider rates.Provider
+		if *file != "" {
+			provider = rates.NewFile(*file)
+		} else if provider, err = rates.NewProvider(env.cfg.Rates); err != nil {
+			return err
+		}
+		if provider == nil {
+			return fmt.Errorf("no rates source: set -file or RATES_PROVIDER")
+		}
+		n, err := rates.Sync(ctx, provider, store, from, to)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Loaded %d rates from %s to %s\n", n, from.Format(time.DateOnly), to.Format(time.DateOnly))
+	}
+
+	saved, err := store.Rates(ctx, from, to)
+	if err != nil {
+		return err
+	}
+	rows := make([]rateRow, len(saved))
+	for i, r := range saved {
+		rows[i] = rateRow{Day: r.Day.Format(time.DateOnly), Base: r.Base, Currency: r.Currency, Rate: entity.FormatRate(r.Rate)}
+	}
+	return env.out.print(rows, func(t *tableWriter) {
+		t.row("DAY", "BASE", "CURRENCY", "RATE")
+		for _, r := range rows {
+			t.row(r.Day, r.Base, r.Currency, r.Rate)
+		}
+	})
+}
+
+// runRevenue строит отчёт сам (из БД) или запрашивает его у API
+func runRevenue(ctx context.Context, env *env, args []string) error {
+	fs := newFlagSet("revenue")
+	fromFlag := fs.String("from", "", "first day, YYYY-MM-DD (default: 30 days before -to)")
+	toFlag := fs.String("to", "", "last day, YYYY-MM-DD (default: today)")
+	group := fs.String("group", string(report.GroupDay), "period: day, month or total")
+	if err := parse(fs, args, 1, 1); err != nil {
+		return err
+	}
+	to, err := parseDay("to", *toFlag, entity.Day(time.Now()))
+	if err != nil {
+		return err
+	}
+	from, err := parseDay("from", *fromFlag, to.AddDate(0, 0, -29))
+	if err != nil {
+		return err
+	}
+	q := report.Query{Currency: strings.ToUpper(fs.Arg(0)), From: from, To: to, Group: report.Group(*group)}
+
+	var revenue report.Revenue
+	if env.apiURL != "" {
+		api, err := env.api()
+		if err != nil {
+			return err
+		}
+		revenue, err = api.Revenue(ctx, q)
+		if err != nil {
+			return err
+		}
+	} else {
+		store, err := env.database()
+		if err != nil {
+			return err
+		}
+		if revenue, err = report.BuildRevenue(ctx, store, q); err != nil {
+			return err
+		}
+	}
+
+	return env.out.print(revenue, func(t *tableWriter) {
+		t.row("PERIOD", "CURRENCY", "ORDERS", "AMOUNT", "CONVERTED")
+		for _, p := range revenue.Periods {
+			for _, c := range p.Currencies {
+				t.row(p.Start, c.Currency, c.Orders, c.Amount, c.Converted)
+			}
+			t.row(p.Start, "total", p.Orders, "", p.Total)
+		}
+		t.blank()
+		t.row(fmt.Sprintf("Total %s %s: %d orders, %s to %s", revenue.Total, revenue.Currency, revenue.Orders, revenue.From, revenue.To))
+		if len(revenue.Unconverted) > 0 {
+			t.blank()
+			t.row("UNCONVERTED", "CURRENCY", "ORDERS", "AMOUNT", "REASON")
+			for _, u := range revenue.Unconverted {
+				t.row(u.Day, u.Currency, u.Orders, u.Amount, u.Reason)
+			}
+		}
+	})
+}
+
+// parseDay разбирает день флага name; пустое значение — def
+func parseDay(name, value string, def time.Time) (time.Time, error) {
+	if value == "" {
+		return def, nil
+	}
+	t, err := entity.ParseTime(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid -%s: %w", name, err)
+	}
+	return entity.Day(t), nil
+}
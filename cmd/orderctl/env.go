@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"iter"
+	"order/config"
+	"order/internal/client"
+	"order/internal/entity"
+	"order/internal/storage"
+)
+
+// orderReader — чтение заказов, общее для БД (storage.Store) и HTTP API (client.Client)
+type orderReader interface {
+	GetOrder(ctx context.Context, orderUID string) (entity.Order, error)
+	FindOrders(ctx context.Context, lookup storage.Lookup) ([]entity.Order, error)
+	SearchOrders(ctx context.Context, search storage.Search) (storage.SearchPage, error)
+	Orders(ctx context.Context, filter storage.Filter) iter.Seq2[entity.Order, error]
+}
+
+var (
+	errNeedsAPI = errors.New("this command talks to a running instance: set -api or ORDERCTL_API")
+	errNeedsDB  = errors.New("this command needs a database connection: run it without -api")
+)
+
+// env — соединения подкоманды; открываются по требованию
+type env struct {
+	apiURL string
+	token  string
+	out    *printer
+
+	cfg   *config.Config
+	db    *sql.DB
+	store storage.Store
+}
+
+// reader возвращает HTTP API, если задан -api, иначе БД
+func (e *env) reader() (orderReader, error) {
+	if e.apiURL != "" {
+		return e.api()
+	}
+	return e.database()
+}
+
+func (e *env) api() (*client.Client, error) {
+	if e.apiURL == "" {
+		return nil, errNeedsAPI
+	}
+	return client.New(e.apiURL, e.token), nil
+}
+
+// database подключается к БД по настройкам из окружения (.env), как сервис
+func (e *env) database() (storage.Store, error) {
+	if e.apiURL != "" {
+		return nil, errNeedsDB
+	}
+	if e.store != nil {
+		return e.store, nil
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	e.cfg = cfg
+	e.db, e.store, err = storage.NewDatabaseConnection(cfg)
+	return e.store, err
+}
+
+func (e *env) close() {
+	if e.db != nil {
+		e.db.Close()
+		e.db = nil
+	}
+}
@@ -0,0 +1,119 @@
+// orderctl — командная строка оператора сервиса заказов: чтение, поиск, выгрузка и загрузка
+// заказов, replay из Kafka, миграции схемы, курсы валют, отчёт о выручке и сброс кэша.
+// Работает напрямую с БД (настройки из .env, как у сервиса) или через HTTP API запущенного
+// экземпляра (-api)
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+)
+
+// command — подкоманда orderctl
+type command struct {
+	usage string // аргументы после имени подкоманды
+	help  string
+	run   func(ctx context.Context, env *env, args []string) error
+}
+
+// commands заполняется в init: команды сами ссылаются на commands через newFlagSet
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"get":     {"<order_uid>", "show an order", runGet},
+		"list":    {"[-limit n] [<lookup key>=<value>]", "list orders, optionally by a lookup key (newest first)", runList},
+		"search":  {"[-limit n] [-offset n] <query>", "full-text search", runSearch},
+		"export":  {"[-format f] [-rows r] [-columns a,b] [-filter key=value] [-out file]", "export orders (see cmd/export)", runExport},
+		"import":  {"[-format f] [-conflict c] [-dry-run] <file|glob|-> ...", "import export files (database only)", runImport},
+		"replay":  {"-topic t [-from offset|time] [-to offset|time] [-partition p]", "replay a Kafka range (API only)", runReplay},
+		"migrate": {"status | up | down [n] | to <version> | force <version>", "show or change the schema version (database only)", runMigrate},
+		"cache":   {"flush", "flush the cache of a running instance (API only)", runCache},
+		"rates":   {"load [-file f] [-from d] [-to d] | list [-from d] [-to d]", "load or show exchange rates (database only)", runRates},
+		"revenue": {"[-from d] [-to d] [-group g] <currency>", "revenue converted to a currency at daily rates", runRevenue},
+	}
+}
+
+// errUsage — неверные аргументы подкоманды; usage уже напечатан
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	log.SetFlags(0)
+	apiURL := flag.String("api", os.Getenv("ORDERCTL_API"), "HTTP API base URL, e.g. http://localhost:8080 (default: connect to the database)")
+	token := flag.String("token", os.Getenv("ADMIN_TOKEN"), "admin token for /admin endpoints")
+	output := flag.String("o", string(outputTable), "output format: table, json or yaml")
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+	out, err := newPrinter(outputFormat(*output), os.Stdout)
+	if err != nil {
+		log.Fatalf("orderctl: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	env := &env{apiURL: *apiURL, token: *token, out: out}
+	defer env.close()
+	if err := cmd.run(ctx, env, flag.Args()[1:]); err != nil {
+		env.close()
+		if errors.Is(err, errUsage) {
+			os.Exit(2)
+		}
+		log.Printf("orderctl %s: %v", flag.Arg(0), err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	w := flag.CommandLine.Output()
+	fmt.Fprintf(w, "Usage: %s [flags] <command> [args]\n\nCommands:\n", os.Args[0])
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-8s %s\n", name, commands[name].help)
+	}
+	fmt.Fprintf(w, "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+// newFlagSet создаёт флаги подкоманды; ошибки разбора печатаются вместе с её usage
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s %s\n", os.Args[0], name, commands[name].usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse разбирает флаги подкоманды и проверяет число позиционных аргументов
+func parse(fs *flag.FlagSet, args []string, minArgs, maxArgs int) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() < minArgs || (maxArgs >= 0 && fs.NArg() > maxArgs) {
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
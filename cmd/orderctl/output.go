@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+type outputFormat string
+
+const (
+	outputTable outputFormat = "table"
+	outputJSON  outputFormat = "json"
+	outputYAML  outputFormat = "yaml"
+)
+
+// printer печатает результат подкоманды в выбранном формате
+type printer struct {
+	format outputFormat
+	w      io.Writer
+}
+
+func newPrinter(format outputFormat, w io.Writer) (*printer, error) {
+	switch format {
+	case outputTable, outputJSON, outputYAML:
+		return &printer{format: format, w: w}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// print выводит v как JSON или YAML (с именами полей из json-тегов) либо таблицей через table
+func (p *printer) print(v any, table func(t *tableWriter)) error {
+	switch p.format {
+	case outputJSON:
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(p.w, "%s\n", data)
+		return err
+	case outputYAML:
+		return writeYAML(p.w, v)
+	default:
+		t := &tableWriter{w: tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)}
+		table(t)
+		return t.w.Flush()
+	}
+}
+
+// writeYAML кодирует v через JSON: так YAML получает те же имена и порядок полей.
+// JSON — подмножество YAML, поэтому он разбирается в дерево узлов, у которого сбрасывается
+// стиль: кодировщик выберет блочный вид и сам закавычит строки, похожие на числа
+func writeYAML(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	resetStyle(&node)
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
+
+// tableWriter — таблица с выравниванием колонок
+type tableWriter struct {
+	w *tabwriter.Writer
+}
+
+func (t *tableWriter) row(cells ...any) {
+	text := make([]string, len(cells))
+	for i, cell := range cells {
+		text[i] = strings.ReplaceAll(fmt.Sprint(cell), "\t", " ")
+	}
+	fmt.Fprintln(t.w, strings.Join(text, "\t"))
+}
+
+// blank отделяет таблицы друг от друга
+func (t *tableWriter) blank() {
+	t.w.Flush()
+	fmt.Fprintln(t.w)
+}
@@ -2,15 +2,14 @@ package main
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 	"log"
 	"math/rand"
 	"order/config"
+	"order/internal/codec"
+	kafkactrl "order/internal/controller/kafka"
 	"os"
 	"os/signal"
 	"strconv"
-	"strings"
 	"syscall"
 	"time"
 
@@ -79,24 +78,39 @@ func main() {
 	if err != nil {
 		log.Fatalf("Ошибка env: %v", err)
 	}
-	var builder strings.Builder
-	builder.Grow(64)
-	fmt.Fprintf(&builder, "%s:%s,%s:%s,%s:%s",
-		cfg.Kafka.Host, cfg.Kafka.Port1,
-		cfg.Kafka.Host, cfg.Kafka.Port2,
-		cfg.Kafka.Host, cfg.Kafka.Port3,
-	)
-	brokers := builder.String()
+	brokers := cfg.Kafka.BootstrapServers()
 	topic := cfg.Kafka.Topic
 	if brokers == "" || topic == "" {
 		log.Fatal("KAFKA_BROKERS or KAFKA_TOPIC not set")
 	}
-	log.Printf("Kafka brokers: %s, topic: %s", brokers, topic)
+	log.Printf("Kafka brokers: %s, topic: %s, format: %s", brokers, topic, cfg.Kafka.ValueFormat)
 
-	// Инициализация продюсера
-	producer, err := kafka.NewProducer(&kafka.ConfigMap{
-		"bootstrap.servers": brokers,
-	})
+	// Энкодер сообщений; для Avro/Protobuf схема регистрируется в subject "<topic>-value"
+	var registry codec.Registry
+	if cfg.Kafka.SchemaRegistryURL != "" {
+		registry = codec.NewRegistryClient(cfg.Kafka.SchemaRegistryURL)
+	}
+	encoder, err := codec.NewEncoder(codec.Format(cfg.Kafka.ValueFormat), registry, topic+"-value")
+	if err != nil {
+		log.Fatalf("Failed to create message encoder: %v", err)
+	}
+
+	var headers []kafka.Header
+	for key, value := range encoder.Headers() {
+		headers = append(headers, kafka.Header{Key: key, Value: []byte(value)})
+	}
+
+	// Инициализация продюсера с общими настройками безопасности
+	producerConfig, err := kafkactrl.NewConfigMap(cfg.Kafka, brokers)
+	if err != nil {
+		log.Fatalf("Invalid Kafka configuration: %v", err)
+	}
+	// Партиционирование по order_uid как у Java-клиента: на нём держится
+	// режим кэша partitioned (если KAFKA_PROPERTIES не задают своё)
+	if _, ok := producerConfig["partitioner"]; !ok {
+		producerConfig["partitioner"] = "murmur2_random"
+	}
+	producer, err := kafka.NewProducer(&producerConfig)
 	if err != nil {
 		log.Fatalf("Failed to create producer: %v", err)
 	}
@@ -134,9 +148,10 @@ func main() {
 			default:
 				// Генерация тестового заказа
 				order := generateOrder()
-				data, err := json.Marshal(order)
+				data, err := encoder.Encode(ctx, order)
 				if err != nil {
-					log.Printf("Failed to marshal order: %v", err)
+					log.Printf("Failed to encode order: %v", err)
+					time.Sleep(2 * time.Second)
 					continue
 				}
 
@@ -145,6 +160,7 @@ func main() {
 					TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny},
 					Value:          data,
 					Key:            []byte(order.OrderUID),
+					Headers:        headers,
 				}, deliveryChan)
 				if err != nil {
 					log.Printf("Failed to produce message: %v", err)
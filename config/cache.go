@@ -0,0 +1,48 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Режимы кэша для CACHE_MODE
+const (
+	CacheAll         = "all"
+	CachePartitioned = "partitioned"
+)
+
+// Реакции на изменение заказа для CACHE_INVALIDATION
+const (
+	InvalidateEvict   = "evict"
+	InvalidateRefresh = "refresh"
+)
+
+// Partitioned сообщает, что кэш держит только заказы назначенных партиций
+func (c Cache) Partitioned() bool {
+	return c.Mode == CachePartitioned
+}
+
+// Refresh сообщает, что изменённые заказы перечитываются из БД, а не вытесняются
+func (c Cache) Refresh() bool {
+	return c.Invalidation == InvalidateRefresh
+}
+
+// Validate проверяет режим кэша; partitioned имеет смысл только при чтении из Kafka
+func (c Cache) Validate(ingest Ingest) error {
+	switch c.Invalidation {
+	case InvalidateEvict, InvalidateRefresh, "":
+	default:
+		return fmt.Errorf("unknown cache invalidation %q", c.Invalidation)
+	}
+	switch c.Mode {
+	case CacheAll, "":
+		return nil
+	case CachePartitioned:
+		if !ingest.Enabled(SourceKafka) {
+			return errors.New("CACHE_MODE=partitioned requires kafka in INGEST_SOURCES")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown cache mode %q", c.Mode)
+	}
+}
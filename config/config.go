@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/caarlos0/env/v10"
 	"github.com/joho/godotenv"
@@ -10,10 +11,28 @@ import (
 type (
 	DatabaseType string
 	Config       struct {
-		App   App
-		DB    Database
-		Front Frontend
-		Kafka Kafka
+		App    App
+		Cache  Cache
+		DB     Database
+		Front  Frontend
+		Ingest Ingest
+		Kafka  Kafka
+		Nats   Nats
+		Rates  Rates
+	}
+
+	Cache struct {
+		// all — каждый экземпляр кэширует все заказы; partitioned — только заказы
+		// назначенных ему партиций Kafka
+		Mode string `env:"CACHE_MODE" envDefault:"all"`
+		// Реакция на изменение заказа другим экземпляром: evict — вытеснить из кэша,
+		// refresh — перечитать из БД
+		Invalidation string `env:"CACHE_INVALIDATION" envDefault:"evict"`
+	}
+
+	Ingest struct {
+		// Источники заказов через запятую: kafka, nats
+		Sources []string `env:"INGEST_SOURCES" envDefault:"kafka"`
 	}
 
 	App struct {
@@ -21,31 +40,113 @@ type (
 		Port        string `env:"APP_PORT" envDefault:":8080"`
 		Version     string `env:"APP_VERSION" envDefault:"1.0.0"`
 		Environment string `env:"APP_ENV" envDefault:"dev"`
+		// Роль процесса: all, api или ingest (см. role.go)
+		Role string `env:"APP_ROLE" envDefault:"all"`
+		// Срок на остановку: дообработку заказов, отправку отложенных сообщений и закрытие HTTP
+		ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" envDefault:"30s"`
+		// Токен для /admin/*; если не задан, админка отключена
+		AdminToken string `env:"ADMIN_TOKEN"`
 	}
 
 	Database struct {
 		Type     DatabaseType `env:"DB_TYPE" envDefault:"postgres"`
-		SType    string       `env:"DB_TYPE",required`
-		Host     string       `env:"DB_HOST",required`
-		User     string       `env:"DB_USER",required`
-		Password string       `env:"DB_PASSWORD",required`
-		Name     string       `env:"DB_NAME",required`
-		Port     string       `env:"DB_PORT",required`
-		Mode     string       `env:"DB_SSLMODE",required`
+		SType    string       `env:"DB_TYPE,required"`
+		Host     string       `env:"DB_HOST,required"`
+		User     string       `env:"DB_USER,required"`
+		Password string       `env:"DB_PASSWORD,required"`
+		Name     string       `env:"DB_NAME,required"`
+		Port     string       `env:"DB_PORT,required"`
+		Mode     string       `env:"DB_SSLMODE,required"`
+		// Предохранитель: после DB_BREAKER_THRESHOLD ошибок подряд обращения к базе
+		// приостанавливаются; пауза до пробы удваивается до DB_BREAKER_MAX_OPEN_TIMEOUT
+		BreakerThreshold      int           `env:"DB_BREAKER_THRESHOLD" envDefault:"5"`
+		BreakerOpenTimeout    time.Duration `env:"DB_BREAKER_OPEN_TIMEOUT" envDefault:"1s"`
+		BreakerMaxOpenTimeout time.Duration `env:"DB_BREAKER_MAX_OPEN_TIMEOUT" envDefault:"30s"`
+		// Применять миграции при старте. false — схему меняют DBA (orderctl migrate),
+		// а процесс только проверяет, что она актуальна, и иначе не стартует
+		AutoMigrate bool `env:"DB_AUTO_MIGRATE" envDefault:"true"`
 	}
 
 	Frontend struct {
-		Host string `env:"FRONT_HOST",required`
-		Port string `env:"FRONT_PORT",required`
+		Host string `env:"FRONT_HOST,required"`
+		Port string `env:"FRONT_PORT,required"`
 	}
 
 	Kafka struct {
-		Host    string `env:"KAFKA_HOST",required`
-		Port1   string `env:"KAFKA_PORT_1" default:"9091"`
-		Port2   string `env:"KAFKA_PORT_2" default:"9092"`
-		Port3   string `env:"KAFKA_PORT_3" default:"9093"`
-		Topic   string `env:"KAFKA_TOPIC" default:"orders"`
-		GroupName string `env:"KAFKA_GROUP_NAME" default:"order-group"`
+		// Список брокеров "host:port,host:port"; если не задан, используется
+		// устаревшая схема KAFKA_HOST + KAFKA_PORT_1..3
+		Brokers []string `env:"KAFKA_BROKERS"`
+		Host    string   `env:"KAFKA_HOST"`
+		Port1   string   `env:"KAFKA_PORT_1" envDefault:"9091"`
+		Port2   string   `env:"KAFKA_PORT_2" envDefault:"9092"`
+		Port3   string   `env:"KAFKA_PORT_3" envDefault:"9093"`
+		// Топик, в который пишет эмулятор; консюмер подписывается на него, если не задан KAFKA_TOPICS
+		Topic string `env:"KAFKA_TOPIC" envDefault:"orders"`
+		// Топики подписки консюмера; элементы, начинающиеся с "^", — регулярные выражения
+		Topics    []string `env:"KAFKA_TOPICS"`
+		GroupName string   `env:"KAFKA_GROUP_NAME" envDefault:"order-group"`
+		// Формат значений сообщений: json, avro, protobuf или auto
+		ValueFormat       string `env:"KAFKA_VALUE_FORMAT" envDefault:"json"`
+		SchemaRegistryURL string `env:"SCHEMA_REGISTRY_URL"`
+		// Топик для сообщений неизвестной версии схемы; по умолчанию "<topic>-quarantine"
+		QuarantineTopic string `env:"KAFKA_QUARANTINE_TOPIC"`
+
+		Security KafkaSecurity
+		// Произвольные свойства librdkafka в виде "key=value,key=value";
+		// применяются последними и переопределяют всё остальное
+		Properties map[string]string `env:"KAFKA_PROPERTIES" envKeyValSeparator:"="`
+	}
+
+	KafkaSecurity struct {
+		// PLAINTEXT, SSL, SASL_PLAINTEXT или SASL_SSL
+		Protocol string `env:"KAFKA_SECURITY_PROTOCOL" envDefault:"PLAINTEXT"`
+		// PLAIN, SCRAM-SHA-256, SCRAM-SHA-512 или OAUTHBEARER
+		SASLMechanism string `env:"KAFKA_SASL_MECHANISM"`
+		SASLUsername  string `env:"KAFKA_SASL_USERNAME"`
+		SASLPassword  string `env:"KAFKA_SASL_PASSWORD"`
+
+		// OAUTHBEARER через OIDC (client credentials)
+		OAuthTokenURL     string `env:"KAFKA_OAUTH_TOKEN_URL"`
+		OAuthClientID     string `env:"KAFKA_OAUTH_CLIENT_ID"`
+		OAuthClientSecret string `env:"KAFKA_OAUTH_CLIENT_SECRET"`
+		OAuthScope        string `env:"KAFKA_OAUTH_SCOPE"`
+
+		CALocation   string `env:"KAFKA_SSL_CA_LOCATION"`
+		CertLocation string `env:"KAFKA_SSL_CERT_LOCATION"`
+		KeyLocation  string `env:"KAFKA_SSL_KEY_LOCATION"`
+		KeyPassword  string `env:"KAFKA_SSL_KEY_PASSWORD"`
+	}
+
+	Nats struct {
+		URL string `env:"NATS_URL" envDefault:"nats://localhost:4222"`
+		// Стрим JetStream, в который другая команда публикует заказы
+		Stream   string   `env:"NATS_STREAM" envDefault:"ORDERS"`
+		Subjects []string `env:"NATS_SUBJECTS" envDefault:"orders.>"`
+		// Имя durable-консюмера: позиция чтения переживает перезапуск сервиса
+		Durable string `env:"NATS_DURABLE" envDefault:"order-service"`
+		// Время на подтверждение, после которого сообщение доставляется повторно
+		AckWait time.Duration `env:"NATS_ACK_WAIT" envDefault:"30s"`
+		// Максимум доставок одного сообщения; -1 — без ограничения
+		MaxDeliver int `env:"NATS_MAX_DELIVER" envDefault:"5"`
+		// Задержка повторной доставки после ошибки обработки
+		NakDelay time.Duration `env:"NATS_NAK_DELAY" envDefault:"1s"`
+		// Субъект для сообщений неизвестной версии схемы; его должен захватывать какой-либо стрим
+		QuarantineSubject string `env:"NATS_QUARANTINE_SUBJECT" envDefault:"orders-quarantine"`
+	}
+
+	Rates struct {
+		// Источник курсов валют: frankfurter, file или пусто — курсы загружаются только
+		// вручную (orderctl rates load)
+		Provider string `env:"RATES_PROVIDER"`
+		URL      string `env:"RATES_URL" envDefault:"https://api.frankfurter.dev/v1"`
+		// Базовая валюта запроса к провайдеру
+		Base string `env:"RATES_BASE" envDefault:"EUR"`
+		// CSV-файл для RATES_PROVIDER=file (см. rates.ReadCSV)
+		File string `env:"RATES_FILE"`
+		// Процессы, принимающие заказы, раз в RATES_SYNC_INTERVAL загружают курсы
+		// за последние RATES_SYNC_DAYS дней
+		SyncInterval time.Duration `env:"RATES_SYNC_INTERVAL" envDefault:"6h"`
+		SyncDays     int           `env:"RATES_SYNC_DAYS" envDefault:"7"`
 	}
 )
 
@@ -55,6 +156,19 @@ const (
 )
 
 func Load() (*Config, error) {
+	cfg, err := Parse()
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Parse читает конфигурацию без проверки; позволяет переопределить поля
+// (например, роль из флага) до вызова Validate
+func Parse() (*Config, error) {
 	// запуск .env
 	if err := godotenv.Load(); err != nil {
 		return nil, fmt.Errorf("error while load .env file: %w", err)
@@ -66,3 +180,40 @@ func Load() (*Config, error) {
 	}
 	return cfg, nil
 }
+
+// Validate проверяет конфигурацию; настройки брокеров проверяются,
+// только если процесс принимает заказы
+func (cfg *Config) Validate() error {
+	if err := cfg.App.Validate(); err != nil {
+		return fmt.Errorf("invalid app config: %w", err)
+	}
+	if err := cfg.Rates.Validate(); err != nil {
+		return fmt.Errorf("invalid rates config: %w", err)
+	}
+	if err := validateCacheRole(cfg.App, cfg.Cache); err != nil {
+		return fmt.Errorf("invalid cache config: %w", err)
+	}
+	if !cfg.App.Ingests() {
+		if err := cfg.Cache.Validate(cfg.Ingest); err != nil {
+			return fmt.Errorf("invalid cache config: %w", err)
+		}
+		return nil
+	}
+	if err := cfg.Ingest.Validate(); err != nil {
+		return fmt.Errorf("invalid ingest config: %w", err)
+	}
+	if err := cfg.Cache.Validate(cfg.Ingest); err != nil {
+		return fmt.Errorf("invalid cache config: %w", err)
+	}
+	if cfg.Ingest.Enabled(SourceKafka) {
+		if err := cfg.Kafka.Validate(); err != nil {
+			return fmt.Errorf("invalid kafka config: %w", err)
+		}
+	}
+	if cfg.Ingest.Enabled(SourceNats) {
+		if err := cfg.Nats.Validate(); err != nil {
+			return fmt.Errorf("invalid nats config: %w", err)
+		}
+	}
+	return nil
+}
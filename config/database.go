@@ -0,0 +1,15 @@
+package config
+
+import "fmt"
+
+// DSN возвращает строку подключения к базе
+func (d Database) DSN() string {
+	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
+		d.User,
+		d.Password,
+		d.Host,
+		d.Port,
+		d.Name,
+		d.Mode,
+	)
+}
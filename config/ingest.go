@@ -0,0 +1,81 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Источники заказов для INGEST_SOURCES
+const (
+	SourceKafka = "kafka"
+	SourceNats  = "nats"
+)
+
+// Enabled сообщает, включён ли источник
+func (i Ingest) Enabled(source string) bool {
+	for _, s := range i.Sources {
+		if strings.EqualFold(strings.TrimSpace(s), source) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate проверяет, что включён хотя бы один известный источник
+func (i Ingest) Validate() error {
+	enabled := 0
+	for _, s := range i.Sources {
+		switch strings.ToLower(strings.TrimSpace(s)) {
+		case "":
+		case SourceKafka, SourceNats:
+			enabled++
+		default:
+			return fmt.Errorf("unknown ingest source %q", s)
+		}
+	}
+	if enabled == 0 {
+		return errors.New("no ingest sources configured: set INGEST_SOURCES")
+	}
+	return nil
+}
+
+// Validate проверяет настройки JetStream-консюмера
+func (n Nats) Validate() error {
+	switch {
+	case n.URL == "":
+		return errors.New("NATS_URL is required")
+	case n.Stream == "":
+		return errors.New("NATS_STREAM is required")
+	case n.Durable == "":
+		return errors.New("NATS_DURABLE is required")
+	case n.AckWait <= 0:
+		return fmt.Errorf("NATS_ACK_WAIT must be positive, got %s", n.AckWait)
+	case n.MaxDeliver == 0 || n.MaxDeliver < -1:
+		return fmt.Errorf("NATS_MAX_DELIVER must be positive or -1, got %d", n.MaxDeliver)
+	case n.QuarantineSubject == "":
+		return errors.New("NATS_QUARANTINE_SUBJECT is required")
+	}
+	for _, filter := range n.Subjects {
+		if subjectMatches(filter, n.QuarantineSubject) {
+			return fmt.Errorf("NATS subject filter %q matches quarantine subject %s", filter, n.QuarantineSubject)
+		}
+	}
+	return nil
+}
+
+// subjectMatches сообщает, подходит ли субъект под фильтр NATS с подстановками * и >
+func subjectMatches(filter, subject string) bool {
+	f, s := strings.Split(filter, "."), strings.Split(subject, ".")
+	for i, token := range f {
+		switch {
+		case token == ">":
+			return len(s) > i
+		case i >= len(s):
+			return false
+		case token != "*" && token != s[i]:
+			return false
+		}
+	}
+	return len(f) == len(s)
+}
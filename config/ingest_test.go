@@ -0,0 +1,85 @@
+package config
+
+import (
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func TestIngest(t *testing.T) {
+    both := Ingest{Sources: []string{"kafka", " NATS "}}
+    assert.NoError(t, both.Validate())
+    assert.True(t, both.Enabled(SourceKafka))
+    assert.True(t, both.Enabled(SourceNats))
+
+    kafkaOnly := Ingest{Sources: []string{"kafka"}}
+    assert.False(t, kafkaOnly.Enabled(SourceNats))
+
+    assert.ErrorContains(t, Ingest{Sources: []string{"rabbitmq"}}.Validate(), "unknown ingest source")
+    assert.ErrorContains(t, Ingest{}.Validate(), "no ingest sources")
+}
+
+func TestNats_Validate(t *testing.T) {
+    valid := Nats{URL: "nats://localhost:4222", Stream: "ORDERS", Subjects: []string{"orders.>"}, Durable: "order-service", AckWait: 30 * time.Second, MaxDeliver: 5, QuarantineSubject: "orders-quarantine"}
+    assert.NoError(t, valid.Validate())
+
+    unlimited := valid
+    unlimited.MaxDeliver = -1
+    assert.NoError(t, unlimited.Validate())
+
+    noDurable := valid
+    noDurable.Durable = ""
+    assert.ErrorContains(t, noDurable.Validate(), "NATS_DURABLE")
+
+    noAckWait := valid
+    noAckWait.AckWait = 0
+    assert.ErrorContains(t, noAckWait.Validate(), "NATS_ACK_WAIT")
+
+    noQuarantine := valid
+    noQuarantine.QuarantineSubject = ""
+    assert.ErrorContains(t, noQuarantine.Validate(), "NATS_QUARANTINE_SUBJECT")
+
+    for _, filter := range []string{"orders.>", "orders.*", "orders.quarantine", ">"} {
+        subscribed := valid
+        subscribed.Subjects = []string{filter}
+        subscribed.QuarantineSubject = "orders.quarantine"
+        assert.ErrorContains(t, subscribed.Validate(), "matches quarantine subject", filter)
+    }
+    for _, filter := range []string{"orders.*.eu", "orders.quarantine.>", "orders"} {
+        other := valid
+        other.Subjects = []string{filter}
+        other.QuarantineSubject = "orders.quarantine"
+        assert.NoError(t, other.Validate(), filter)
+    }
+}
+
+func TestCache_Validate(t *testing.T) {
+    kafka := Ingest{Sources: []string{"kafka"}}
+    nats := Ingest{Sources: []string{"nats"}}
+
+    assert.NoError(t, Cache{Mode: CacheAll}.Validate(nats))
+    assert.NoError(t, Cache{Mode: CachePartitioned}.Validate(kafka))
+    assert.True(t, Cache{Mode: CachePartitioned}.Partitioned())
+    assert.ErrorContains(t, Cache{Mode: CachePartitioned}.Validate(nats), "requires kafka")
+    assert.ErrorContains(t, Cache{Mode: "lru"}.Validate(kafka), "unknown cache mode")
+    assert.ErrorContains(t, Cache{Mode: CacheAll, Invalidation: "ignore"}.Validate(kafka), "unknown cache invalidation")
+    assert.True(t, Cache{Invalidation: InvalidateRefresh}.Refresh())
+}
+
+func TestRates_Validate(t *testing.T) {
+    assert.NoError(t, Rates{}.Validate())
+
+    valid := Rates{Provider: RatesFrankfurter, URL: "https://api.frankfurter.dev/v1", Base: "EUR", SyncInterval: 6 * time.Hour, SyncDays: 7}
+    assert.NoError(t, valid.Validate())
+
+    noFile := valid
+    noFile.Provider = RatesFile
+    assert.ErrorContains(t, noFile.Validate(), "RATES_FILE")
+
+    noInterval := valid
+    noInterval.SyncInterval = 0
+    assert.ErrorContains(t, noInterval.Validate(), "RATES_SYNC_INTERVAL")
+
+    assert.ErrorContains(t, Rates{Provider: "ecb"}.Validate(), "unknown rates provider")
+}
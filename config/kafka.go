@@ -0,0 +1,97 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// BrokerList возвращает брокеров из KAFKA_BROKERS, а без него — из KAFKA_HOST и KAFKA_PORT_1..3
+func (k Kafka) BrokerList() []string {
+	var brokers []string
+	for _, b := range k.Brokers {
+		if b = strings.TrimSpace(b); b != "" {
+			brokers = append(brokers, b)
+		}
+	}
+	if len(brokers) > 0 || k.Host == "" {
+		return brokers
+	}
+
+	for _, port := range []string{k.Port1, k.Port2, k.Port3} {
+		if port != "" {
+			brokers = append(brokers, net.JoinHostPort(k.Host, port))
+		}
+	}
+	return brokers
+}
+
+// BootstrapServers — значение bootstrap.servers для librdkafka
+func (k Kafka) BootstrapServers() string {
+	return strings.Join(k.BrokerList(), ",")
+}
+
+// SubscribeTopics возвращает топики подписки консюмера
+func (k Kafka) SubscribeTopics() []string {
+	var topics []string
+	for _, t := range k.Topics {
+		if t = strings.TrimSpace(t); t != "" {
+			topics = append(topics, t)
+		}
+	}
+	if len(topics) == 0 && k.Topic != "" {
+		topics = []string{k.Topic}
+	}
+	return topics
+}
+
+// QuarantineTopicName возвращает топик карантина (по умолчанию "<KAFKA_TOPIC>-quarantine")
+func (k Kafka) QuarantineTopicName() string {
+	if k.QuarantineTopic != "" {
+		return k.QuarantineTopic
+	}
+	return k.Topic + "-quarantine"
+}
+
+// Validate проверяет список брокеров и топики подписки
+func (k Kafka) Validate() error {
+	brokers := k.BrokerList()
+	if len(brokers) == 0 {
+		return errors.New("no brokers configured: set KAFKA_BROKERS or KAFKA_HOST")
+	}
+	for _, b := range brokers {
+		host, port, err := net.SplitHostPort(b)
+		if err != nil {
+			return fmt.Errorf("invalid broker address %q: %w", b, err)
+		}
+		if n, err := strconv.Atoi(port); host == "" || err != nil || n <= 0 || n > 65535 {
+			return fmt.Errorf("invalid broker address %q", b)
+		}
+	}
+
+	topics := k.SubscribeTopics()
+	if len(topics) == 0 {
+		return errors.New("no topics configured: set KAFKA_TOPICS or KAFKA_TOPIC")
+	}
+	quarantine := k.QuarantineTopicName()
+	for _, t := range topics {
+		if !strings.HasPrefix(t, "^") {
+			if t == quarantine {
+				return fmt.Errorf("quarantine topic %s must not be subscribed", t)
+			}
+			continue
+		}
+		re, err := regexp.Compile(t)
+		if err != nil {
+			return fmt.Errorf("invalid topic pattern %q: %w", t, err)
+		}
+		// Иначе консюмер снова прочитает отложенные сообщения
+		if re.MatchString(quarantine) {
+			return fmt.Errorf("topic pattern %q matches quarantine topic %s", t, quarantine)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func TestKafka_BrokerList(t *testing.T) {
+    t.Run("Broker list wins over legacy variables", func(t *testing.T) {
+        k := Kafka{Brokers: []string{"b1:9092", " b2:9093 ", ""}, Host: "legacy", Port1: "9091"}
+        assert.Equal(t, []string{"b1:9092", "b2:9093"}, k.BrokerList())
+        assert.Equal(t, "b1:9092,b2:9093", k.BootstrapServers())
+    })
+
+    t.Run("Legacy host and ports", func(t *testing.T) {
+        k := Kafka{Host: "10.0.0.1", Port1: "9091", Port2: "9092", Port3: "9093"}
+        assert.Equal(t, "10.0.0.1:9091,10.0.0.1:9092,10.0.0.1:9093", k.BootstrapServers())
+    })
+}
+
+func TestKafka_SubscribeTopics(t *testing.T) {
+    assert.Equal(t, []string{"orders"}, Kafka{Topic: "orders"}.SubscribeTopics())
+    assert.Equal(t, []string{"a", "^orders\\..*"}, Kafka{Topic: "orders", Topics: []string{"a", "^orders\\..*"}}.SubscribeTopics())
+}
+
+func TestKafka_Validate(t *testing.T) {
+    valid := Kafka{Brokers: []string{"b1:9092"}, Topic: "orders"}
+    assert.NoError(t, valid.Validate())
+
+    for name, k := range map[string]Kafka{
+        "no brokers":               {Topic: "orders"},
+        "missing port":             {Brokers: []string{"b1"}, Topic: "orders"},
+        "bad port":                 {Brokers: []string{"b1:http"}, Topic: "orders"},
+        "empty host":               {Brokers: []string{":9092"}, Topic: "orders"},
+        "no topics":                {Brokers: []string{"b1:9092"}},
+        "invalid pattern":          {Brokers: []string{"b1:9092"}, Topic: "orders", Topics: []string{"^orders[("}},
+        "pattern matches quarantine": {Brokers: []string{"b1:9092"}, Topic: "orders", Topics: []string{"^orders.*"}},
+        "quarantine subscribed":    {Brokers: []string{"b1:9092"}, Topic: "orders", Topics: []string{"orders-quarantine"}},
+    } {
+        assert.Error(t, k.Validate(), name)
+    }
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Источники курсов для RATES_PROVIDER
+const (
+	RatesFrankfurter = "frankfurter"
+	RatesFile        = "file"
+)
+
+// Validate проверяет источник курсов и параметры периодической загрузки
+func (r Rates) Validate() error {
+	switch r.Provider {
+	case "":
+		return nil
+	case RatesFrankfurter:
+		if r.URL == "" {
+			return errors.New("RATES_URL is required for the frankfurter provider")
+		}
+		if len(r.Base) != 3 {
+			return fmt.Errorf("RATES_BASE must be a currency code, got %q", r.Base)
+		}
+	case RatesFile:
+		if r.File == "" {
+			return errors.New("RATES_FILE is required for the file provider")
+		}
+	default:
+		return fmt.Errorf("unknown rates provider %q", r.Provider)
+	}
+	if r.SyncInterval <= 0 {
+		return fmt.Errorf("RATES_SYNC_INTERVAL must be positive, got %s", r.SyncInterval)
+	}
+	if r.SyncDays <= 0 {
+		return fmt.Errorf("RATES_SYNC_DAYS must be positive, got %d", r.SyncDays)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Роли процесса для APP_ROLE (или флага -role)
+const (
+	RoleAll    = "all"    // REST API и приём заказов в одном процессе
+	RoleAPI    = "api"    // только REST API; Kafka и NATS не используются
+	RoleIngest = "ingest" // только приём заказов и служебные эндпоинты
+)
+
+// ServesAPI сообщает, отдаёт ли процесс заказы по REST
+func (a App) ServesAPI() bool {
+	return a.Role == RoleAll || a.Role == RoleAPI
+}
+
+// Ingests сообщает, читает ли процесс заказы из брокеров
+func (a App) Ingests() bool {
+	return a.Role == RoleAll || a.Role == RoleIngest
+}
+
+func (a App) Validate() error {
+	switch a.Role {
+	case RoleAll, RoleAPI, RoleIngest:
+		return nil
+	default:
+		return fmt.Errorf("unknown role %q: expected %s, %s or %s", a.Role, RoleAll, RoleAPI, RoleIngest)
+	}
+}
+
+// validateCacheRole: кэш по партициям держит заказы назначенных партиций и нужен только
+// процессу, который одновременно читает Kafka и отдаёт заказы
+func validateCacheRole(app App, cache Cache) error {
+	if cache.Partitioned() && app.Role != RoleAll {
+		return errors.New("CACHE_MODE=partitioned requires APP_ROLE=all")
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func TestApp_Role(t *testing.T) {
+    all := App{Role: RoleAll}
+    assert.True(t, all.ServesAPI())
+    assert.True(t, all.Ingests())
+
+    api := App{Role: RoleAPI}
+    assert.True(t, api.ServesAPI())
+    assert.False(t, api.Ingests())
+
+    ingest := App{Role: RoleIngest}
+    assert.False(t, ingest.ServesAPI())
+    assert.True(t, ingest.Ingests())
+
+    assert.NoError(t, api.Validate())
+    assert.ErrorContains(t, App{Role: "worker"}.Validate(), "unknown role")
+}
+
+func TestConfig_Validate(t *testing.T) {
+    t.Run("API role ignores broker settings", func(t *testing.T) {
+        cfg := &Config{App: App{Role: RoleAPI}, Cache: Cache{Mode: CacheAll}}
+        assert.NoError(t, cfg.Validate())
+    })
+
+    t.Run("Ingest role requires sources", func(t *testing.T) {
+        cfg := &Config{App: App{Role: RoleIngest}, Cache: Cache{Mode: CacheAll}}
+        assert.ErrorContains(t, cfg.Validate(), "no ingest sources")
+    })
+
+    t.Run("Partitioned cache requires role all", func(t *testing.T) {
+        cfg := &Config{App: App{Role: RoleAPI}, Cache: Cache{Mode: CachePartitioned}}
+        assert.ErrorContains(t, cfg.Validate(), "APP_ROLE=all")
+    })
+}
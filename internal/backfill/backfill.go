@@ -0,0 +1,250 @@
+package backfill
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"order/internal/codec"
+	"order/internal/entity"
+	"order/internal/service"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Причины отклонения строк в отчёте
+const (
+	RejectDecode  = "decode"  // строка не разбирается как заказ
+	RejectInvalid = "invalid" // заказ не прошёл валидацию
+	RejectStorage = "storage" // хранилище отвергло заказ
+)
+
+// maxLineSize — предел длины одной строки NDJSON
+const maxLineSize = 64 << 20
+
+// Reject — строка отчёта об отклонённом заказе (NDJSON)
+type Reject struct {
+	Input    string `json:"input"`
+	Line     int64  `json:"line"`
+	OrderUID string `json:"order_uid,omitempty"`
+	Kind     string `json:"kind"`
+	Reason   string `json:"reason"`
+}
+
+type Options struct {
+	// Размер пачки, сохраняемой одной транзакцией
+	BatchSize int
+	// Интервал отчёта о прогрессе в логе; 0 — без отчётов
+	Progress time.Duration
+	// Позиции входов; nil — без чекпоинта
+	Checkpoint *Checkpoint
+	// Куда писать отклонённые заказы в формате NDJSON; nil — не писать
+	Rejects io.Writer
+}
+
+// Summary — итог прогона
+type Summary struct {
+	Inputs        int              `json:"inputs"`
+	SkippedInputs int              `json:"skipped_inputs"` // завершены в прошлых прогонах
+	Lines         int64            `json:"lines"`
+	ResumedLines  int64            `json:"resumed_lines"` // пропущены по чекпоинту
+	Saved         int64            `json:"saved"`
+	Rejected      int64            `json:"rejected"`
+	RejectsByKind map[string]int64 `json:"rejects_by_kind"`
+	Duration      time.Duration    `json:"duration"`
+}
+
+func (s Summary) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "inputs: %d (skipped %d), lines: %d (resumed %d), saved: %d, rejected: %d, took %s",
+		s.Inputs, s.SkippedInputs, s.Lines, s.ResumedLines, s.Saved, s.Rejected, s.Duration.Round(time.Millisecond))
+	kinds := make([]string, 0, len(s.RejectsByKind))
+	for kind := range s.RejectsByKind {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		fmt.Fprintf(&b, "\n  rejected (%s): %d", kind, s.RejectsByKind[kind])
+	}
+	return b.String()
+}
+
+// Backfill загружает заказы из NDJSON через тот же декодер и сервис, что и консюмер
+type Backfill struct {
+	decoder codec.Decoder
+	service service.Service
+	opts    Options
+	rejects *json.Encoder
+
+	summary      Summary
+	start        time.Time
+	lastProgress time.Time
+}
+
+func New(decoder codec.Decoder, service service.Service, opts Options) *Backfill {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	if opts.Checkpoint == nil {
+		opts.Checkpoint, _ = LoadCheckpoint("")
+	}
+	b := &Backfill{decoder: decoder, service: service, opts: opts}
+	if opts.Rejects != nil {
+		b.rejects = json.NewEncoder(opts.Rejects)
+	}
+	return b
+}
+
+// batch — заказы и отклонения, которые фиксируются вместе с позицией чекпоинта
+type batch struct {
+	input   string
+	id      *Identity // nil — вход без чекпоинта (stdin)
+	orders  []entity.Order
+	lines   []int64
+	rejects []Reject
+	last    int64 // последняя прочитанная строка
+}
+
+// Run обрабатывает входы по порядку. При ошибке хранилища или отмене контекста
+// прогон прерывается; повторный запуск с тем же чекпоинтом продолжит с последней пачки
+func (b *Backfill) Run(ctx context.Context, inputs []Input) (Summary, error) {
+	b.summary = Summary{RejectsByKind: make(map[string]int64)}
+	b.start = time.Now()
+	b.lastProgress = b.start
+
+	for _, in := range inputs {
+		b.summary.Inputs++
+		if err := b.runInput(ctx, in); err != nil {
+			b.summary.Duration = time.Since(b.start)
+			return b.summary, err
+		}
+	}
+	b.summary.Duration = time.Since(b.start)
+	return b.summary, nil
+}
+
+func (b *Backfill) runInput(ctx context.Context, in Input) error {
+	var pos Position
+	var id *Identity
+	if in.Resumable() {
+		identity, err := in.Identity()
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", in.Name, err)
+		}
+		pos = b.opts.Checkpoint.Position(in.Name, identity)
+		id = &identity
+	}
+	if pos.Done {
+		b.summary.SkippedInputs++
+		log.Printf("Skipping %s: already loaded", in.Name)
+		return nil
+	}
+
+	rc, err := in.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", in.Name, err)
+	}
+	defer rc.Close()
+
+	scanner := bufio.NewScanner(rc)
+	scanner.Buffer(make([]byte, 0, 1<<20), maxLineSize)
+
+	current := &batch{input: in.Name, id: id, last: pos.Line}
+	var line int64
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line++
+		if line <= pos.Line {
+			b.summary.ResumedLines++
+			continue
+		}
+		b.summary.Lines++
+		current.last = line
+
+		value := scanner.Bytes()
+		if len(bytes.TrimSpace(value)) == 0 {
+			continue
+		}
+		order, err := b.decoder.Decode(ctx, value, nil)
+		if err != nil {
+			current.rejects = append(current.rejects, Reject{Input: in.Name, Line: line, Kind: RejectDecode, Reason: err.Error()})
+		} else {
+			current.orders = append(current.orders, order)
+			current.lines = append(current.lines, line)
+		}
+
+		if len(current.orders) >= b.opts.BatchSize {
+			if err := b.flush(ctx, current, false); err != nil {
+				return err
+			}
+			current = &batch{input: in.Name, id: id, last: line}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read %s at line %d: %w", in.Name, line+1, err)
+	}
+	return b.flush(ctx, current, true)
+}
+
+// flush сохраняет пачку, пишет отклонения и только после этого сдвигает чекпоинт
+func (b *Backfill) flush(ctx context.Context, current *batch, done bool) error {
+	if len(current.orders) > 0 {
+		results, err := b.service.ProcessBatch(ctx, current.orders)
+		if err != nil {
+			return fmt.Errorf("failed to save batch ending at %s:%d: %w", current.input, current.last, err)
+		}
+		for i, err := range results {
+			if err == nil {
+				b.summary.Saved++
+				continue
+			}
+			kind := RejectStorage
+			if errors.Is(err, service.ErrInvalidOrder) {
+				kind = RejectInvalid
+			}
+			current.rejects = append(current.rejects, Reject{
+				Input:    current.input,
+				Line:     current.lines[i],
+				OrderUID: current.orders[i].OrderUID,
+				Kind:     kind,
+				Reason:   err.Error(),
+			})
+		}
+	}
+
+	sort.Slice(current.rejects, func(i, j int) bool { return current.rejects[i].Line < current.rejects[j].Line })
+	for _, r := range current.rejects {
+		b.summary.Rejected++
+		b.summary.RejectsByKind[r.Kind]++
+		if b.rejects != nil {
+			if err := b.rejects.Encode(r); err != nil {
+				return fmt.Errorf("failed to write rejects report: %w", err)
+			}
+		}
+	}
+
+	if current.id != nil {
+		if err := b.opts.Checkpoint.Save(current.input, Position{Identity: *current.id, Line: current.last, Done: done}); err != nil {
+			return err
+		}
+	}
+	b.reportProgress(current.input, current.last)
+	return nil
+}
+
+func (b *Backfill) reportProgress(input string, line int64) {
+	if b.opts.Progress <= 0 || time.Since(b.lastProgress) < b.opts.Progress {
+		return
+	}
+	b.lastProgress = time.Now()
+	elapsed := time.Since(b.start).Seconds()
+	log.Printf("Backfill progress: %s:%d, saved %d, rejected %d (%.0f lines/s)",
+		input, line, b.summary.Saved, b.summary.Rejected, float64(b.summary.Lines)/elapsed)
+}
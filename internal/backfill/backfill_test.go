@@ -0,0 +1,221 @@
+package backfill
+
+import (
+    "bytes"
+    "compress/gzip"
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "order/internal/codec"
+    "order/internal/entity"
+    "order/internal/service"
+    "order/internal/storage"
+    "order/internal/storage/memory"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func ndjson(t *testing.T, uids ...string) []byte {
+    var buf bytes.Buffer
+    for _, uid := range uids {
+        if strings.HasPrefix(uid, "!") {
+            buf.WriteString(uid[1:] + "\n") // Битая строка как есть
+            continue
+        }
+        line, err := json.Marshal(entity.Order{OrderUID: uid})
+        require.NoError(t, err)
+        buf.Write(append(line, '\n'))
+    }
+    return buf.Bytes()
+}
+
+func writeFile(t *testing.T, path string, data []byte, compress bool) {
+    if compress {
+        var buf bytes.Buffer
+        gz := gzip.NewWriter(&buf)
+        _, err := gz.Write(data)
+        require.NoError(t, err)
+        require.NoError(t, gz.Close())
+        data = buf.Bytes()
+    }
+    require.NoError(t, os.WriteFile(path, data, 0o644))
+}
+
+func identityOf(t *testing.T, path string) Identity {
+    fi, err := os.Stat(path)
+    require.NoError(t, err)
+    return Identity{Size: fi.Size(), ModTime: fi.ModTime().UTC()}
+}
+
+func newBackfill(t *testing.T, store storage.Store, opts Options) *Backfill {
+    decoder, err := codec.NewDecoder(codec.FormatJSON, nil, nil)
+    require.NoError(t, err)
+    return New(decoder, service.NewService(store), opts)
+}
+
+func TestInputs(t *testing.T) {
+    dir := t.TempDir()
+    writeFile(t, filepath.Join(dir, "b.ndjson"), ndjson(t, "uid-b"), false)
+    writeFile(t, filepath.Join(dir, "a.ndjson.gz"), ndjson(t, "uid-a"), true)
+
+    inputs, err := Inputs([]string{filepath.Join(dir, "*"), filepath.Join(dir, "b.ndjson"), "-"}, strings.NewReader("stdin"))
+    require.NoError(t, err)
+    var names []string
+    for _, in := range inputs {
+        names = append(names, in.Name)
+    }
+    assert.Equal(t, []string{filepath.Join(dir, "a.ndjson.gz"), filepath.Join(dir, "b.ndjson"), Stdin}, names)
+
+    // gzip распаковывается прозрачно
+    rc, err := inputs[0].Open()
+    require.NoError(t, err)
+    defer rc.Close()
+    var buf bytes.Buffer
+    _, err = buf.ReadFrom(rc)
+    require.NoError(t, err)
+    assert.Equal(t, ndjson(t, "uid-a"), buf.Bytes())
+
+    _, err = Inputs([]string{filepath.Join(dir, "*.csv")}, nil)
+    assert.ErrorContains(t, err, "no files match")
+}
+
+func TestBackfill_Run(t *testing.T) {
+    ctx := context.Background()
+    dir := t.TempDir()
+    writeFile(t, filepath.Join(dir, "part-1.ndjson"), ndjson(t, "uid-1", "!{broken", "uid-2", "!"), false)
+    writeFile(t, filepath.Join(dir, "part-2.ndjson.gz"), ndjson(t, "uid-3", "uid-4", "uid-5"), true)
+
+    inputs, err := Inputs([]string{filepath.Join(dir, "part-*"), "-"}, bytes.NewReader(ndjson(t, "uid-6")))
+    require.NoError(t, err)
+
+    checkpoint, err := LoadCheckpoint(filepath.Join(dir, "checkpoint.json"))
+    require.NoError(t, err)
+    var rejects bytes.Buffer
+    store := memory.NewStore()
+
+    summary, err := newBackfill(t, store, Options{BatchSize: 2, Checkpoint: checkpoint, Rejects: &rejects}).Run(ctx, inputs)
+    require.NoError(t, err)
+    assert.Equal(t, 3, summary.Inputs)
+    assert.Equal(t, int64(8), summary.Lines)
+    assert.Equal(t, int64(6), summary.Saved)
+    assert.Equal(t, int64(1), summary.Rejected)
+    assert.Equal(t, map[string]int64{RejectDecode: 1}, summary.RejectsByKind)
+
+    orders, err := storage.Collect(store.Orders(ctx, storage.Filter{}))
+    require.NoError(t, err)
+    assert.Len(t, orders, 6)
+
+    var reject Reject
+    require.NoError(t, json.Unmarshal(rejects.Bytes(), &reject))
+    assert.Equal(t, filepath.Join(dir, "part-1.ndjson"), reject.Input)
+    assert.Equal(t, int64(2), reject.Line)
+    assert.Equal(t, RejectDecode, reject.Kind)
+
+    // Чекпоинт сохранён на диск: повторный прогон ничего не перечитывает
+    checkpoint, err = LoadCheckpoint(filepath.Join(dir, "checkpoint.json"))
+    require.NoError(t, err)
+    part1 := filepath.Join(dir, "part-1.ndjson")
+    assert.Equal(t, Position{Identity: identityOf(t, part1), Line: 4, Done: true}, checkpoint.Inputs[part1])
+    // Stdin не возобновляется: в чекпоинте его позиции нет
+    assert.NotContains(t, checkpoint.Inputs, Stdin)
+
+    inputs, err = Inputs([]string{filepath.Join(dir, "part-*")}, nil)
+    require.NoError(t, err)
+    summary, err = newBackfill(t, store, Options{Checkpoint: checkpoint}).Run(ctx, inputs)
+    require.NoError(t, err)
+    assert.Equal(t, 2, summary.SkippedInputs)
+    assert.Equal(t, int64(0), summary.Lines)
+}
+
+// brokenStore перестаёт сохранять после failAfter успешных пачек
+type brokenStore struct {
+    storage.Store
+    mu        sync.Mutex
+    failAfter int
+    batches   int
+    saved     []string
+}
+
+func (s *brokenStore) SaveOrders(ctx context.Context, orders []entity.Order) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if s.batches >= s.failAfter {
+        return errors.New("connection refused")
+    }
+    s.batches++
+    for _, order := range orders {
+        s.saved = append(s.saved, order.OrderUID)
+    }
+    return s.Store.SaveOrders(ctx, orders)
+}
+
+func (s *brokenStore) SaveOrder(context.Context, entity.Order) error {
+    return errors.New("connection refused")
+}
+
+func TestBackfill_Resume(t *testing.T) {
+    ctx := context.Background()
+    dir := t.TempDir()
+    var uids []string
+    for i := 1; i <= 7; i++ {
+        uids = append(uids, fmt.Sprintf("uid-%d", i))
+    }
+    path := filepath.Join(dir, "orders.ndjson.gz")
+    writeFile(t, path, ndjson(t, uids...), true)
+    inputs, err := Inputs([]string{path}, nil)
+    require.NoError(t, err)
+    checkpointPath := filepath.Join(dir, "checkpoint.json")
+
+    // Хранилище падает на третьей пачке
+    store := &brokenStore{Store: memory.NewStore(), failAfter: 2}
+    checkpoint, err := LoadCheckpoint(checkpointPath)
+    require.NoError(t, err)
+    summary, err := newBackfill(t, store, Options{BatchSize: 2, Checkpoint: checkpoint}).Run(ctx, inputs)
+    assert.ErrorContains(t, err, "connection refused")
+    assert.Equal(t, int64(4), summary.Saved)
+
+    // Продолжение с чекпоинта: первые четыре строки не отправляются повторно
+    store.failAfter = 100
+    checkpoint, err = LoadCheckpoint(checkpointPath)
+    require.NoError(t, err)
+    assert.Equal(t, Position{Identity: identityOf(t, path), Line: 4}, checkpoint.Inputs[path])
+    summary, err = newBackfill(t, store, Options{BatchSize: 2, Checkpoint: checkpoint}).Run(ctx, inputs)
+    require.NoError(t, err)
+    assert.Equal(t, int64(4), summary.ResumedLines)
+    assert.Equal(t, int64(3), summary.Saved)
+    assert.Equal(t, uids, store.saved)
+}
+
+func TestBackfill_ChangedInput(t *testing.T) {
+    ctx := context.Background()
+    dir := t.TempDir()
+    path := filepath.Join(dir, "orders.ndjson")
+    writeFile(t, path, ndjson(t, "uid-1", "uid-2"), false)
+    inputs, err := Inputs([]string{path}, nil)
+    require.NoError(t, err)
+    checkpointPath := filepath.Join(dir, "checkpoint.json")
+
+    store := memory.NewStore()
+    checkpoint, err := LoadCheckpoint(checkpointPath)
+    require.NoError(t, err)
+    _, err = newBackfill(t, store, Options{Checkpoint: checkpoint}).Run(ctx, inputs)
+    require.NoError(t, err)
+
+    // Файл перезаписан под тем же именем: позиция из чекпоинта к нему не относится
+    writeFile(t, path, ndjson(t, "uid-3", "uid-4", "uid-5"), false)
+    checkpoint, err = LoadCheckpoint(checkpointPath)
+    require.NoError(t, err)
+    summary, err := newBackfill(t, store, Options{Checkpoint: checkpoint}).Run(ctx, inputs)
+    require.NoError(t, err)
+    assert.Equal(t, 0, summary.SkippedInputs)
+    assert.Equal(t, int64(0), summary.ResumedLines)
+    assert.Equal(t, int64(3), summary.Saved)
+    assert.Equal(t, Position{Identity: identityOf(t, path), Line: 3, Done: true}, checkpoint.Inputs[path])
+}
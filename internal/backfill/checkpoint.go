@@ -0,0 +1,96 @@
+package backfill
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Identity — размер и время изменения файла входа. Позиция действительна,
+// только пока файл не изменился: иначе строки с тем же номером уже другие
+type Identity struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// Position — сколько строк входа уже обработано (сохранено или отклонено)
+type Position struct {
+	Identity
+	Line int64 `json:"line"`
+	Done bool  `json:"done"`
+}
+
+// Checkpoint хранит позиции по входам, чтобы прерванный прогон продолжался с места остановки.
+// Stdin в чекпоинт не попадает (см. Input.Resumable)
+type Checkpoint struct {
+	path   string
+	Inputs map[string]Position `json:"inputs"`
+}
+
+// LoadCheckpoint читает файл чекпоинта; пустой путь отключает чекпоинт,
+// отсутствующий файл означает новый прогон
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	cp := &Checkpoint{path: path, Inputs: make(map[string]Position)}
+	if path == "" {
+		return cp, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cp, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %s: %w", path, err)
+	}
+	if cp.Inputs == nil {
+		cp.Inputs = make(map[string]Position)
+	}
+	return cp, nil
+}
+
+// Position возвращает позицию входа, если файл с тех пор не изменился; иначе вход
+// загружается с начала
+func (c *Checkpoint) Position(input string, id Identity) Position {
+	pos, ok := c.Inputs[input]
+	if !ok {
+		return Position{}
+	}
+	if pos.Size != id.Size || !pos.ModTime.Equal(id.ModTime) {
+		log.Printf("Input %s changed since the checkpoint, loading it from the start", input)
+		return Position{}
+	}
+	return pos
+}
+
+// Save атомарно перезаписывает файл чекпоинта
+func (c *Checkpoint) Save(input string, pos Position) error {
+	c.Inputs[input] = pos
+	if c.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return os.Rename(tmp.Name(), c.path)
+}
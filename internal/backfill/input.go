@@ -0,0 +1,107 @@
+package backfill
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Stdin — имя входа для стандартного ввода
+const Stdin = "-"
+
+// Input — один источник NDJSON: файл (возможно, сжатый gzip) или stdin
+type Input struct {
+	Name string
+	open func() (io.ReadCloser, error)
+	stat func() (os.FileInfo, error) // nil для stdin
+}
+
+// Inputs раскрывает аргументы командной строки: пути, glob-шаблоны и "-" для stdin.
+// Шаблон без совпадений считается ошибкой, чтобы опечатка не превращалась в пустой прогон
+func Inputs(args []string, stdin io.Reader) ([]Input, error) {
+	var inputs []Input
+	seen := make(map[string]bool)
+	add := func(in Input) {
+		if !seen[in.Name] {
+			seen[in.Name] = true
+			inputs = append(inputs, in)
+		}
+	}
+
+	for _, arg := range args {
+		if arg == Stdin {
+			add(Input{Name: Stdin, open: func() (io.ReadCloser, error) { return io.NopCloser(stdin), nil }})
+			continue
+		}
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", arg, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %q", arg)
+		}
+		sort.Strings(matches)
+		for _, path := range matches {
+			add(Input{
+				Name: path,
+				open: func() (io.ReadCloser, error) { return os.Open(path) },
+				stat: func() (os.FileInfo, error) { return os.Stat(path) },
+			})
+		}
+	}
+	return inputs, nil
+}
+
+// Resumable сообщает, можно ли продолжить вход с позиции чекпоинта.
+// Stdin читается один раз, и при повторном запуске на нём может быть уже другой поток
+func (in Input) Resumable() bool {
+	return in.stat != nil
+}
+
+// Identity возвращает размер и время изменения файла, по которым чекпоинт узнаёт вход
+func (in Input) Identity() (Identity, error) {
+	if in.stat == nil {
+		return Identity{}, fmt.Errorf("input %s has no identity", in.Name)
+	}
+	fi, err := in.stat()
+	if err != nil {
+		return Identity{}, err
+	}
+	return Identity{Size: fi.Size(), ModTime: fi.ModTime().UTC()}, nil
+}
+
+// Open открывает вход; gzip распознаётся по сигнатуре, а не по расширению
+func (in Input) Open() (io.ReadCloser, error) {
+	rc, err := in.open()
+	if err != nil {
+		return nil, err
+	}
+	br := bufio.NewReaderSize(rc, 1<<20)
+	magic, _ := br.Peek(2)
+	if len(magic) < 2 || magic[0] != 0x1f || magic[1] != 0x8b {
+		return readCloser{Reader: br, close: rc.Close}, nil
+	}
+
+	gz, err := gzip.NewReader(br)
+	if err != nil {
+		rc.Close()
+		return nil, fmt.Errorf("failed to open gzip stream %s: %w", in.Name, err)
+	}
+	return readCloser{Reader: gz, close: func() error {
+		gz.Close()
+		return rc.Close()
+	}}, nil
+}
+
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (r readCloser) Close() error {
+	return r.close()
+}
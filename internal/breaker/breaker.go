@@ -0,0 +1,187 @@
+package breaker
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// ErrOpen возвращается вместо вызова, пока предохранитель разомкнут
+var ErrOpen = errors.New("circuit breaker is open")
+
+type State string
+
+const (
+	StateClosed   State = "closed"    // вызовы проходят
+	StateOpen     State = "open"      // вызовы отклоняются до следующей пробы
+	StateHalfOpen State = "half-open" // проходит один пробный вызов
+)
+
+type Config struct {
+	Name string
+	// Число ошибок подряд, после которого предохранитель размыкается
+	FailureThreshold int
+	// Пауза до первой пробы; после каждой неудачной пробы удваивается до MaxOpenTimeout
+	OpenTimeout    time.Duration
+	MaxOpenTimeout time.Duration
+	// IsFailure решает, говорит ли ошибка о сбое зависимости (nil — любая ошибка)
+	IsFailure func(error) bool
+}
+
+// Stats — снимок состояния для /status и метрик
+type Stats struct {
+	Name                string    `json:"name"`
+	State               State     `json:"state"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	Trips               int64     `json:"trips"`    // сколько раз размыкался
+	Rejected            int64     `json:"rejected"` // вызовов отклонено без обращения к зависимости
+	LastError           string    `json:"last_error,omitempty"`
+	OpenedAt            time.Time `json:"opened_at,omitzero"`
+	NextProbe           time.Time `json:"next_probe,omitzero"`
+}
+
+// Breaker — предохранитель вокруг вызовов зависимости (например, базы данных)
+type Breaker struct {
+	cfg Config
+	now func() time.Time
+
+	mu          sync.Mutex
+	state       State
+	failures    int
+	trips       int64
+	rejected    int64
+	lastError   string
+	openedAt    time.Time
+	openTimeout time.Duration
+	nextProbe   time.Time
+	probing     bool
+}
+
+func New(cfg Config) *Breaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = time.Second
+	}
+	if cfg.MaxOpenTimeout < cfg.OpenTimeout {
+		cfg.MaxOpenTimeout = cfg.OpenTimeout
+	}
+	return &Breaker{cfg: cfg, now: time.Now, state: StateClosed, openTimeout: cfg.OpenTimeout}
+}
+
+// Do выполняет fn, если предохранитель замкнут или пришло время пробы
+func (b *Breaker) Do(fn func() error) error {
+	if !b.allow() {
+		return ErrOpen
+	}
+	err := fn()
+	b.record(err)
+	return err
+}
+
+// Ready сообщает, пропустит ли предохранитель вызов прямо сейчас
+func (b *Breaker) Ready() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case StateOpen:
+		return !b.now().Before(b.nextProbe)
+	case StateHalfOpen:
+		return !b.probing
+	default:
+		return true
+	}
+}
+
+// Failure сообщает, говорит ли ошибка о сбое зависимости, а не о проблеме конкретного вызова
+func (b *Breaker) Failure(err error) bool {
+	if err == nil {
+		return false
+	}
+	return errors.Is(err, ErrOpen) || b.cfg.IsFailure == nil || b.cfg.IsFailure(err)
+}
+
+func (b *Breaker) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	stats := Stats{
+		Name:                b.cfg.Name,
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		Trips:               b.trips,
+		Rejected:            b.rejected,
+		LastError:           b.lastError,
+	}
+	if b.state != StateClosed {
+		stats.OpenedAt = b.openedAt
+		stats.NextProbe = b.nextProbe
+	}
+	return stats
+}
+
+func (b *Breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case StateOpen:
+		if b.now().Before(b.nextProbe) {
+			b.rejected++
+			return false
+		}
+		b.state = StateHalfOpen
+		b.probing = true
+		log.Printf("Circuit breaker %s is half-open, probing", b.cfg.Name)
+		return true
+	case StateHalfOpen:
+		if b.probing {
+			b.rejected++
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *Breaker) record(err error) {
+	failed := b.Failure(err)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	probe := b.state == StateHalfOpen
+	b.probing = false
+
+	if !failed {
+		if b.state != StateClosed {
+			log.Printf("Circuit breaker %s is closed after %s", b.cfg.Name, b.now().Sub(b.openedAt).Round(time.Millisecond))
+		}
+		b.state = StateClosed
+		b.failures = 0
+		b.openTimeout = b.cfg.OpenTimeout
+		return
+	}
+
+	b.failures++
+	b.lastError = err.Error()
+	switch {
+	case probe:
+		// Проба не удалась — ждём дольше
+		b.openTimeout = min(2*b.openTimeout, b.cfg.MaxOpenTimeout)
+		b.open()
+	case b.state == StateClosed && b.failures >= b.cfg.FailureThreshold:
+		b.trips++
+		b.openedAt = b.now()
+		b.open()
+	}
+}
+
+// open размыкает предохранитель до следующей пробы; вызывается под b.mu
+func (b *Breaker) open() {
+	b.state = StateOpen
+	b.nextProbe = b.now().Add(b.openTimeout)
+	log.Printf("Circuit breaker %s is open after %d failures, next probe in %s: %s",
+		b.cfg.Name, b.failures, b.openTimeout, b.lastError)
+}
@@ -0,0 +1,91 @@
+package breaker
+
+import (
+    "errors"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+)
+
+var errDown = errors.New("connection refused")
+
+// newTestBreaker возвращает предохранитель с управляемыми часами
+func newTestBreaker(cfg Config) (*Breaker, *time.Time) {
+    now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+    b := New(cfg)
+    b.now = func() time.Time { return now }
+    return b, &now
+}
+
+func fail() error { return errDown }
+func ok() error   { return nil }
+
+func TestBreaker(t *testing.T) {
+    t.Run("Opens after threshold", func(t *testing.T) {
+        b, _ := newTestBreaker(Config{Name: "db", FailureThreshold: 2, OpenTimeout: time.Second})
+
+        assert.ErrorIs(t, b.Do(fail), errDown)
+        assert.Equal(t, StateClosed, b.Stats().State)
+        assert.ErrorIs(t, b.Do(fail), errDown)
+        assert.Equal(t, StateOpen, b.Stats().State)
+        assert.False(t, b.Ready())
+
+        called := false
+        err := b.Do(func() error { called = true; return nil })
+        assert.ErrorIs(t, err, ErrOpen)
+        assert.False(t, called)
+
+        stats := b.Stats()
+        assert.Equal(t, int64(1), stats.Trips)
+        assert.Equal(t, int64(1), stats.Rejected)
+        assert.Equal(t, errDown.Error(), stats.LastError)
+    })
+
+    t.Run("Success resets failures", func(t *testing.T) {
+        b, _ := newTestBreaker(Config{FailureThreshold: 2})
+
+        assert.Error(t, b.Do(fail))
+        assert.NoError(t, b.Do(ok))
+        assert.Error(t, b.Do(fail))
+        assert.Equal(t, StateClosed, b.Stats().State)
+    })
+
+    t.Run("Probe closes on success", func(t *testing.T) {
+        b, now := newTestBreaker(Config{FailureThreshold: 1, OpenTimeout: time.Second})
+
+        assert.Error(t, b.Do(fail))
+        *now = now.Add(time.Second)
+        assert.True(t, b.Ready())
+        assert.NoError(t, b.Do(ok))
+        assert.Equal(t, StateClosed, b.Stats().State)
+    })
+
+    t.Run("Failed probe doubles timeout", func(t *testing.T) {
+        b, now := newTestBreaker(Config{FailureThreshold: 1, OpenTimeout: time.Second, MaxOpenTimeout: 3 * time.Second})
+
+        assert.Error(t, b.Do(fail))
+        *now = now.Add(time.Second)
+        assert.ErrorIs(t, b.Do(fail), errDown)
+        assert.Equal(t, now.Add(2*time.Second), b.Stats().NextProbe)
+
+        *now = now.Add(2 * time.Second)
+        assert.Error(t, b.Do(fail))
+        assert.Equal(t, now.Add(3*time.Second), b.Stats().NextProbe) // Не больше MaxOpenTimeout
+        assert.Equal(t, int64(1), b.Stats().Trips)
+    })
+
+    t.Run("Ignored errors do not count", func(t *testing.T) {
+        errBad := errors.New("bad order")
+        b, _ := newTestBreaker(Config{
+            FailureThreshold: 1,
+            IsFailure:        func(err error) bool { return !errors.Is(err, errBad) },
+        })
+
+        assert.ErrorIs(t, b.Do(func() error { return errBad }), errBad)
+        assert.Equal(t, StateClosed, b.Stats().State)
+        assert.False(t, b.Failure(errBad))
+        assert.True(t, b.Failure(ErrOpen))
+        assert.False(t, b.Failure(nil))
+    })
+}
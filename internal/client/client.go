@@ -0,0 +1,230 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"order/internal/breaker"
+	"order/internal/controller/kafka"
+	"order/internal/entity"
+	"order/internal/export"
+	"order/internal/report"
+	"order/internal/storage"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StatusError — ответ API с кодом ошибки. 404 сопоставляется со storage.ErrNotFound,
+// 503 — с breaker.ErrOpen, чтобы вызывающий код обрабатывал их так же, как ошибки хранилища
+type StatusError struct {
+	Code    int
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.Code, http.StatusText(e.Code), e.Message)
+}
+
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case storage.ErrNotFound:
+		return e.Code == http.StatusNotFound
+	case breaker.ErrOpen:
+		return e.Code == http.StatusServiceUnavailable
+	}
+	return false
+}
+
+// CacheResponse — ответ эндпоинтов /admin/cache/*
+type CacheResponse struct {
+	Evicted int `json:"evicted,omitempty"`
+	Size    int `json:"size"`
+}
+
+// Client — клиент HTTP API сервиса заказов. Служебные методы (/admin) требуют token
+type Client struct {
+	base  string
+	token string
+	http  *http.Client
+}
+
+func New(baseURL, token string) *Client {
+	return &Client{base: strings.TrimSuffix(baseURL, "/"), token: token, http: http.DefaultClient}
+}
+
+func (c *Client) GetOrder(ctx context.Context, orderUID string) (entity.Order, error) {
+	var order entity.Order
+	err := c.getJSON(ctx, "/order/"+url.PathEscape(orderUID), nil, &order)
+	return order, err
+}
+
+func (c *Client) FindOrders(ctx context.Context, lookup storage.Lookup) ([]entity.Order, error) {
+	query := url.Values{string(lookup.Field): {lookup.Value}}
+	if lookup.Limit > 0 {
+		query.Set("limit", strconv.Itoa(lookup.Limit))
+	}
+	var orders []entity.Order
+	err := c.getJSON(ctx, "/orders", query, &orders)
+	return orders, err
+}
+
+func (c *Client) SearchOrders(ctx context.Context, search storage.Search) (storage.SearchPage, error) {
+	query := url.Values{"q": {search.Text}}
+	if search.Limit > 0 {
+		query.Set("limit", strconv.Itoa(search.Limit))
+	}
+	if search.Offset > 0 {
+		query.Set("offset", strconv.Itoa(search.Offset))
+	}
+	var page storage.SearchPage
+	err := c.getJSON(ctx, "/orders/search", query, &page)
+	return page, err
+}
+
+// Revenue запрашивает отчёт о выручке /reports/revenue; нулевые From и To сервер
+// заменяет последними 30 днями
+func (c *Client) Revenue(ctx context.Context, q report.Query) (report.Revenue, error) {
+	query := url.Values{"currency": {q.Currency}}
+	if !q.From.IsZero() {
+		query.Set("from", entity.Day(q.From).Format(time.DateOnly))
+	}
+	if !q.To.IsZero() {
+		query.Set("to", entity.Day(q.To).Format(time.DateOnly))
+	}
+	if q.Group != "" {
+		query.Set("group", string(q.Group))
+	}
+	var revenue report.Revenue
+	err := c.getJSON(ctx, "/reports/revenue", query, &revenue)
+	return revenue, err
+}
+
+// Export пишет в w выгрузку /orders/export как есть: формат и колонки выбирает сервер
+func (c *Client) Export(ctx context.Context, w io.Writer, filter storage.Filter, opts export.Options) error {
+	body, err := c.do(ctx, http.MethodGet, "/orders/export", exportQuery(filter, opts), nil)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	_, err = io.Copy(w, body)
+	return err
+}
+
+// Orders обходит заказы через выгрузку в NDJSON, не загружая её целиком
+func (c *Client) Orders(ctx context.Context, filter storage.Filter) iter.Seq2[entity.Order, error] {
+	return func(yield func(entity.Order, error) bool) {
+		body, err := c.do(ctx, http.MethodGet, "/orders/export", exportQuery(filter, export.Options{Format: export.FormatNDJSON}), nil)
+		if err != nil {
+			yield(entity.Order{}, err)
+			return
+		}
+		defer body.Close()
+		for record, err := range export.Read(body, export.FormatNDJSON) {
+			if err == nil {
+				err = record.Err
+			}
+			if err != nil {
+				yield(entity.Order{}, err)
+				return
+			}
+			if !yield(record.Order, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Replay запускает replay диапазона топика; запрос синхронный, как и сам эндпоинт
+func (c *Client) Replay(ctx context.Context, req kafka.ReplayRequest) (kafka.ReplayReport, error) {
+	var report kafka.ReplayReport
+	err := c.postJSON(ctx, "/admin/replay", req, &report)
+	return report, err
+}
+
+func (c *Client) FlushCache(ctx context.Context) (CacheResponse, error) {
+	var resp CacheResponse
+	err := c.postJSON(ctx, "/admin/cache/flush", nil, &resp)
+	return resp, err
+}
+
+func exportQuery(filter storage.Filter, opts export.Options) url.Values {
+	query := url.Values{"format": {string(opts.Format)}}
+	if opts.Rows != "" {
+		query.Set("rows", string(opts.Rows))
+	}
+	if len(opts.Columns) > 0 {
+		query.Set("columns", strings.Join(opts.Columns, ","))
+	}
+	if filter.Field != "" {
+		query.Set(string(filter.Field), filter.Value)
+	}
+	return query
+}
+
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, out any) error {
+	body, err := c.do(ctx, http.MethodGet, path, query, nil)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return decode(body, out)
+}
+
+func (c *Client) postJSON(ctx context.Context, path string, in, out any) error {
+	var payload io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		payload = bytes.NewReader(data)
+	}
+	body, err := c.do(ctx, http.MethodPost, path, nil, payload)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return decode(body, out)
+}
+
+func decode(body io.Reader, out any) error {
+	if err := json.NewDecoder(body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// do выполняет запрос и возвращает тело успешного ответа; ответ с кодом ошибки — *StatusError
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, payload io.Reader) (io.ReadCloser, error) {
+	target := c.base + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, payload)
+	if err != nil {
+		return nil, err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" && strings.HasPrefix(path, "/admin/") {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return nil, &StatusError{Code: resp.StatusCode, Message: strings.TrimSpace(string(message))}
+	}
+	return resp.Body, nil
+}
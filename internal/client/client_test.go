@@ -0,0 +1,114 @@
+package client
+
+import (
+    "bytes"
+    "context"
+    "net/http/httptest"
+    "order/config"
+    "order/internal/entity"
+    "order/internal/export"
+    "order/internal/report"
+    "order/internal/service"
+    "order/internal/storage"
+    "order/internal/storage/memory"
+    "testing"
+    "time"
+    v1 "order/internal/controller/http/v1"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+var testOrders = []entity.Order{
+    {OrderUID: "uid-1", CustomerID: "test", DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC), Items: []entity.Item{{ChrtID: 1, Name: "Mascaras"}}},
+    {OrderUID: "uid-2", CustomerID: "test", DateCreated: time.Date(2021, 11, 27, 6, 22, 19, 0, time.UTC)},
+    {OrderUID: "uid-3", CustomerID: "other", DateCreated: time.Date(2021, 11, 28, 6, 22, 19, 0, time.UTC)},
+}
+
+// setupAPI поднимает настоящий роутер v1 над хранилищем в памяти
+func setupAPI(t *testing.T) (*httptest.Server, service.Service) {
+    store := memory.NewStore()
+    require.NoError(t, store.SaveOrders(context.Background(), testOrders))
+    svc := service.NewService(store)
+    cfg := &config.Config{App: config.App{AdminToken: "secret"}}
+    router := v1.NewRouter(v1.NewHandler(svc), nil, v1.NewConsumerAdminHandler(nil, svc), nil, cfg)
+    server := httptest.NewServer(router)
+    t.Cleanup(server.Close)
+    return server, svc
+}
+
+func TestClient_Orders(t *testing.T) {
+    ctx := context.Background()
+    server, _ := setupAPI(t)
+    c := New(server.URL+"/", "")
+
+    t.Run("Get order", func(t *testing.T) {
+        order, err := c.GetOrder(ctx, "uid-1")
+        require.NoError(t, err)
+        assert.Equal(t, testOrders[0], order)
+
+        _, err = c.GetOrder(ctx, "missing")
+        assert.ErrorIs(t, err, storage.ErrNotFound)
+        var statusErr *StatusError
+        assert.ErrorAs(t, err, &statusErr)
+        assert.Equal(t, 404, statusErr.Code)
+    })
+
+    t.Run("Find orders", func(t *testing.T) {
+        orders, err := c.FindOrders(ctx, storage.Lookup{Field: storage.ByCustomer, Value: "test", Limit: 1})
+        require.NoError(t, err)
+        require.Len(t, orders, 1)
+        assert.Equal(t, "uid-2", orders[0].OrderUID)
+
+        _, err = c.FindOrders(ctx, storage.Lookup{Field: storage.ByNmID, Value: "abc"})
+        assert.ErrorContains(t, err, "400 Bad Request")
+    })
+
+    t.Run("Search orders", func(t *testing.T) {
+        page, err := c.SearchOrders(ctx, storage.Search{Text: "mascaras"})
+        require.NoError(t, err)
+        require.Len(t, page.Hits, 1)
+        assert.Equal(t, "uid-1", page.Hits[0].Order.OrderUID)
+    })
+
+    t.Run("Stream orders", func(t *testing.T) {
+        orders, err := storage.Collect(c.Orders(ctx, storage.Filter{Field: storage.ByCustomer, Value: "test"}))
+        require.NoError(t, err)
+        assert.Equal(t, testOrders[:2], orders)
+    })
+
+    t.Run("Export", func(t *testing.T) {
+        var out bytes.Buffer
+        err := c.Export(ctx, &out, storage.Filter{}, export.Options{Format: export.FormatCSV, Columns: []string{"order_uid"}})
+        require.NoError(t, err)
+        assert.Equal(t, "order_uid\nuid-1\nuid-2\nuid-3\n", out.String())
+    })
+
+    t.Run("Revenue report", func(t *testing.T) {
+        q := report.Query{Currency: "USD", From: testOrders[0].DateCreated, To: testOrders[2].DateCreated, Group: report.GroupTotal}
+        revenue, err := c.Revenue(ctx, q)
+        require.NoError(t, err)
+        assert.Equal(t, "2021-11-26", revenue.From)
+        assert.Equal(t, "2021-11-28", revenue.To)
+        // У тестовых заказов нет валюты оплаты: пересчитать их нечем
+        assert.Empty(t, revenue.Periods)
+        assert.Len(t, revenue.Unconverted, 3)
+
+        _, err = c.Revenue(ctx, report.Query{Currency: "XYZ"})
+        assert.ErrorContains(t, err, "400 Bad Request")
+    })
+}
+
+func TestClient_Admin(t *testing.T) {
+    ctx := context.Background()
+    server, svc := setupAPI(t)
+    _, err := svc.GetOrder(ctx, "uid-1")
+    require.NoError(t, err)
+
+    _, err = New(server.URL, "wrong").FlushCache(ctx)
+    assert.ErrorContains(t, err, "401 Unauthorized")
+
+    resp, err := New(server.URL, "secret").FlushCache(ctx)
+    require.NoError(t, err)
+    assert.Equal(t, CacheResponse{Evicted: 1, Size: 0}, resp)
+}
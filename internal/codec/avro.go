@@ -0,0 +1,115 @@
+package codec
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"order/internal/entity"
+	"sync"
+
+	"github.com/hamba/avro/v2"
+)
+
+// parseAvro разбирает схему в собственном кэше: разные версии схемы
+// с одинаковыми именами записей не должны конфликтовать в глобальном кэше hamba/avro
+func parseAvro(text string) (avro.Schema, error) {
+	return avro.ParseWithCache(text, "", &avro.SchemaCache{})
+}
+
+type avroEncoder struct {
+	registry Registry
+	subject  string
+	schema   avro.Schema
+}
+
+func newAvroEncoder(registry Registry, subject string) (*avroEncoder, error) {
+	schema, err := parseAvro(orderAvroSchema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid order.avsc: %w", err)
+	}
+	return &avroEncoder{registry: registry, subject: subject, schema: schema}, nil
+}
+
+func (e *avroEncoder) Encode(ctx context.Context, v any) ([]byte, error) {
+	id, err := e.registry.Register(ctx, e.subject, Schema{Type: SchemaAvro, Text: orderAvroSchema})
+	if err != nil {
+		return nil, err
+	}
+	doc, err := toDocument(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := mapMoney(doc, moneyToRat); err != nil {
+		return nil, err
+	}
+	payload, err := avro.Marshal(e.schema, doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode avro: %w", err)
+	}
+	return frame(id, payload), nil
+}
+
+func (e *avroEncoder) Headers() map[string]string {
+	return encoderHeaders(contentTypeAvro)
+}
+
+// avroSchemas кэширует разобранные схемы писателя по ID
+type avroSchemas struct {
+	mu      sync.RWMutex
+	schemas map[int]avro.Schema
+}
+
+func (c *avroSchemas) get(id int, text string) (avro.Schema, error) {
+	c.mu.RLock()
+	schema, ok := c.schemas[id]
+	c.mu.RUnlock()
+	if ok {
+		return schema, nil
+	}
+
+	schema, err := parseAvro(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid avro schema %d: %w", id, err)
+	}
+	c.mu.Lock()
+	c.schemas[id] = schema
+	c.mu.Unlock()
+	return schema, nil
+}
+
+func (c *avroSchemas) decode(id int, text string, payload []byte) (map[string]any, error) {
+	schema, err := c.get(id, text)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	if err := avro.Unmarshal(schema, payload, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode avro: %w", err)
+	}
+	if err := mapMoney(doc, moneyFromRat); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// Суммы в order.avsc — bytes с логическим типом decimal(19,4); hamba/avro
+// кодирует и декодирует его как *big.Rat. Целые long из схем версии 1 не трогаются
+
+func moneyToRat(v any) (any, error) {
+	s, ok := v.(string)
+	if !ok {
+		return v, nil
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %q", s)
+	}
+	return r, nil
+}
+
+func moneyFromRat(v any) (any, error) {
+	if r, ok := v.(*big.Rat); ok {
+		return r.FloatString(entity.MoneyScale), nil
+	}
+	return v, nil
+}
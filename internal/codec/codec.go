@@ -0,0 +1,177 @@
+package codec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"order/internal/entity"
+	"strconv"
+	"strings"
+
+	"github.com/hamba/avro/v2"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// HeaderContentType — заголовок сообщения, по которому в режиме auto выбирается формат
+const HeaderContentType = "content-type"
+
+const (
+	contentTypeJSON     = "application/json"
+	contentTypeAvro     = "application/avro"
+	contentTypeProtobuf = "application/x-protobuf"
+)
+
+type decoder struct {
+	format   Format
+	registry Registry
+	versions *Versions
+	avro     *avroSchemas
+	proto    *protoSchemas
+}
+
+// NewDecoder создаёт декодер для заданного формата. Для avro и protobuf нужен реестр схем;
+// в режиме auto он нужен только для сообщений в кадре Schema Registry.
+// Документы старых версий приводятся к текущей через versions (nil — DefaultVersions)
+func NewDecoder(format Format, registry Registry, versions *Versions) (Decoder, error) {
+	switch format {
+	case "":
+		format = FormatJSON
+	case FormatJSON, FormatAuto:
+	case FormatAvro, FormatProtobuf:
+		if registry == nil {
+			return nil, fmt.Errorf("schema registry is required for format %s", format)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported message format: %s", format)
+	}
+	if versions == nil {
+		versions = DefaultVersions
+	}
+
+	return &decoder{
+		format:   format,
+		registry: registry,
+		versions: versions,
+		avro:     &avroSchemas{schemas: make(map[int]avro.Schema)},
+		proto:    &protoSchemas{schemas: make(map[int]protoreflect.FileDescriptor)},
+	}, nil
+}
+
+func (d *decoder) Decode(ctx context.Context, value []byte, headers map[string]string) (entity.Order, error) {
+	format := d.format
+	if format == FormatAuto {
+		format = detectFormat(value, headers)
+	}
+
+	var doc map[string]any
+	var err error
+	switch format {
+	case FormatJSON:
+		doc, err = parseDocument(value)
+	default:
+		doc, err = d.decodeFramed(ctx, format, value)
+	}
+	if err != nil {
+		return entity.Order{}, err
+	}
+
+	version, err := versionOf(doc, headers)
+	if err != nil {
+		return entity.Order{}, err
+	}
+	if doc, err = d.versions.Upcast(version, doc); err != nil {
+		return entity.Order{}, err
+	}
+	return orderFromDocument(doc)
+}
+
+// detectFormat выбирает формат по content-type, а без него — по magic byte.
+// Для сообщений в кадре возвращается FormatAuto: тип определит схема из реестра
+func detectFormat(value []byte, headers map[string]string) Format {
+	contentType := strings.ToLower(headers[HeaderContentType])
+	switch {
+	case strings.Contains(contentType, "avro"):
+		return FormatAvro
+	case strings.Contains(contentType, "protobuf"):
+		return FormatProtobuf
+	case strings.Contains(contentType, "json"), !isFramed(value):
+		return FormatJSON
+	default:
+		return FormatAuto
+	}
+}
+
+func (d *decoder) decodeFramed(ctx context.Context, format Format, value []byte) (map[string]any, error) {
+	if d.registry == nil {
+		return nil, fmt.Errorf("schema registry is not configured")
+	}
+	id, payload, err := unframe(value)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := d.registry.Schema(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if want := schemaTypeOf(format); want != "" && want != schema.Type {
+		return nil, fmt.Errorf("schema %d has type %s, expected %s", id, schema.Type, want)
+	}
+
+	switch schema.Type {
+	case SchemaAvro:
+		return d.avro.decode(id, schema.Text, payload)
+	case SchemaProtobuf:
+		return d.proto.decode(id, schema.Text, payload)
+	case SchemaJSON:
+		return parseDocument(payload)
+	default:
+		return nil, fmt.Errorf("unsupported schema type %s of schema %d", schema.Type, id)
+	}
+}
+
+func schemaTypeOf(format Format) SchemaType {
+	switch format {
+	case FormatAvro:
+		return SchemaAvro
+	case FormatProtobuf:
+		return SchemaProtobuf
+	default:
+		return ""
+	}
+}
+
+type jsonEncoder struct{}
+
+func (jsonEncoder) Encode(_ context.Context, v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonEncoder) Headers() map[string]string {
+	return encoderHeaders(contentTypeJSON)
+}
+
+func encoderHeaders(contentType string) map[string]string {
+	return map[string]string{
+		HeaderContentType:   contentType,
+		HeaderSchemaVersion: strconv.Itoa(CurrentVersion),
+	}
+}
+
+// NewEncoder создаёт энкодер. Avro и Protobuf регистрируют схему заказа
+// в subject (обычно "<topic>-value") и пишут сообщения в кадре Schema Registry
+func NewEncoder(format Format, registry Registry, subject string) (Encoder, error) {
+	switch format {
+	case FormatJSON, "":
+		return jsonEncoder{}, nil
+	case FormatAvro, FormatProtobuf:
+		if registry == nil {
+			return nil, fmt.Errorf("schema registry is required for format %s", format)
+		}
+		if format == FormatAvro {
+			return newAvroEncoder(registry, subject)
+		}
+		return &protobufEncoder{registry: registry, subject: subject}, nil
+	default:
+		return nil, fmt.Errorf("unsupported message format: %s", format)
+	}
+}
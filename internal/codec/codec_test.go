@@ -0,0 +1,357 @@
+package codec
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "order/internal/entity"
+    "strconv"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/hamba/avro/v2"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "google.golang.org/protobuf/encoding/protojson"
+    "google.golang.org/protobuf/proto"
+    "google.golang.org/protobuf/types/dynamicpb"
+)
+
+// fakeRegistry — минимальная замена Schema Registry поверх httptest
+type fakeRegistry struct {
+    mu      sync.Mutex
+    schemas []registrySchema
+    lookups int
+}
+
+func (f *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+
+    switch {
+    case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/subjects/"):
+        var req registrySchema
+        if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+            http.Error(w, err.Error(), http.StatusBadRequest)
+            return
+        }
+        f.schemas = append(f.schemas, req)
+        json.NewEncoder(w).Encode(map[string]int{"id": len(f.schemas)})
+    case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/schemas/ids/"):
+        f.lookups++
+        id, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/schemas/ids/"))
+        if id < 1 || id > len(f.schemas) {
+            http.Error(w, `{"error_code":40403,"message":"Schema not found"}`, http.StatusNotFound)
+            return
+        }
+        json.NewEncoder(w).Encode(f.schemas[id-1])
+    default:
+        http.NotFound(w, r)
+    }
+}
+
+func newFakeRegistry(t *testing.T) (*fakeRegistry, Registry) {
+    fake := &fakeRegistry{}
+    server := httptest.NewServer(fake)
+    t.Cleanup(server.Close)
+    return fake, NewRegistryClient(server.URL)
+}
+
+func testOrder() entity.Order {
+    return entity.Order{
+        OrderUID:    "test-uid",
+        TrackNumber: "WBILMTESTTRACK",
+        Delivery:    entity.Delivery{Name: "John", Phone: "1234567890"},
+        Payment:     entity.Payment{Transaction: "test-uid", Currency: "USD", Amount: entity.NewMoney(1817), PaymentDt: time.Unix(1637907727, 0).UTC()},
+        Items:       []entity.Item{{ChrtID: 9934930, Price: entity.NewMoney(453), Name: "Mascaras", NmID: 2389212}},
+        SmID:        99,
+        DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+    }
+}
+
+func TestCodec_RoundTrip(t *testing.T) {
+    ctx := context.Background()
+
+    for _, format := range []Format{FormatJSON, FormatAvro, FormatProtobuf} {
+        t.Run(string(format), func(t *testing.T) {
+            _, registry := newFakeRegistry(t)
+
+            encoder, err := NewEncoder(format, registry, "order-value")
+            require.NoError(t, err)
+            value, err := encoder.Encode(ctx, testOrder())
+            require.NoError(t, err)
+
+            // Явно заданный формат
+            decoder, err := NewDecoder(format, registry, nil)
+            require.NoError(t, err)
+            order, err := decoder.Decode(ctx, value, nil)
+            require.NoError(t, err)
+            assert.Equal(t, testOrder(), order)
+
+            // Автоопределение по заголовку и по magic byte
+            auto, err := NewDecoder(FormatAuto, registry, nil)
+            require.NoError(t, err)
+            order, err = auto.Decode(ctx, value, encoder.Headers())
+            require.NoError(t, err)
+            assert.Equal(t, testOrder(), order)
+            order, err = auto.Decode(ctx, value, nil)
+            require.NoError(t, err)
+            assert.Equal(t, testOrder(), order)
+        })
+    }
+}
+
+func TestCodec_FractionalMoney(t *testing.T) {
+    ctx := context.Background()
+
+    want := testOrder()
+    want.Payment.Amount = mustMoney(t, "18.17")
+    want.Payment.CustomFee = mustMoney(t, "0.0001")
+    want.Items[0].Price = mustMoney(t, "4.53")
+    want.Items[0].TotalPrice = mustMoney(t, "922337203685477.5807")
+
+    for _, format := range []Format{FormatJSON, FormatAvro, FormatProtobuf} {
+        t.Run(string(format), func(t *testing.T) {
+            _, registry := newFakeRegistry(t)
+
+            encoder, err := NewEncoder(format, registry, "order-value")
+            require.NoError(t, err)
+            value, err := encoder.Encode(ctx, want)
+            require.NoError(t, err)
+
+            decoder, err := NewDecoder(format, registry, nil)
+            require.NoError(t, err)
+            order, err := decoder.Decode(ctx, value, encoder.Headers())
+            require.NoError(t, err)
+            assert.Equal(t, want, order)
+        })
+    }
+}
+
+func TestCodec_IntegerMoneyV1(t *testing.T) {
+    ctx := context.Background()
+    _, registry := newFakeRegistry(t)
+
+    // Документ версии 1: суммы — целые основные единицы
+    doc, err := toDocument(testOrder())
+    require.NoError(t, err)
+    payment := doc["payment"].(map[string]any)
+    payment["amount"], payment["delivery_cost"], payment["goods_total"], payment["custom_fee"] = int64(1817), int64(0), int64(0), int64(0)
+    item := doc["items"].([]any)[0].(map[string]any)
+    item["price"], item["total_price"] = int64(453), int64(0)
+
+    decoder, err := NewDecoder(FormatAuto, registry, nil)
+    require.NoError(t, err)
+
+    t.Run("Avro long", func(t *testing.T) {
+        writer := strings.ReplaceAll(orderAvroSchema, `{"type": "bytes", "logicalType": "decimal", "precision": 19, "scale": 4}`, `"long"`)
+        require.NotEqual(t, orderAvroSchema, writer)
+        id, err := registry.Register(ctx, "order-value", Schema{Type: SchemaAvro, Text: writer})
+        require.NoError(t, err)
+        schema, err := parseAvro(writer)
+        require.NoError(t, err)
+        payload, err := avro.Marshal(schema, doc)
+        require.NoError(t, err)
+
+        order, err := decoder.Decode(ctx, frame(id, payload), map[string]string{HeaderSchemaVersion: "1"})
+        require.NoError(t, err)
+        assert.Equal(t, testOrder(), order)
+    })
+
+    t.Run("Protobuf int64", func(t *testing.T) {
+        writer := `syntax = "proto3";
+package order;
+message Order {
+  string order_uid = 1;
+  Payment payment = 5;
+  repeated Item items = 6;
+}
+message Payment { int64 amount = 5; }
+message Item { int64 price = 3; }`
+        id, err := registry.Register(ctx, "order-value", Schema{Type: SchemaProtobuf, Text: writer})
+        require.NoError(t, err)
+        file, err := compileProto(writer)
+        require.NoError(t, err)
+        msg := dynamicpb.NewMessage(file.Messages().Get(0))
+        require.NoError(t, protojson.Unmarshal([]byte(`{"order_uid":"uid","payment":{"amount":"1817"},"items":[{"price":"453"}]}`), msg))
+        payload, err := proto.Marshal(msg)
+        require.NoError(t, err)
+
+        // Без заголовка версии сообщение считается версией 1
+        order, err := decoder.Decode(ctx, append(appendMessageIndexes(frame(id, nil), 0), payload...), nil)
+        require.NoError(t, err)
+        assert.Equal(t, entity.Order{
+            OrderUID: "uid",
+            Payment:  entity.Payment{Amount: entity.NewMoney(1817)},
+            Items:    []entity.Item{{Price: entity.NewMoney(453)}},
+        }, order)
+    })
+}
+
+func mustMoney(t *testing.T, s string) entity.Money {
+    m, err := entity.ParseMoney(s)
+    require.NoError(t, err)
+    return m
+}
+
+func TestCodec_SchemaCache(t *testing.T) {
+    ctx := context.Background()
+    fake, registry := newFakeRegistry(t)
+
+    encoder, err := NewEncoder(FormatAvro, registry, "order-value")
+    require.NoError(t, err)
+    value, err := encoder.Encode(ctx, testOrder())
+    require.NoError(t, err)
+
+    // Отдельный клиент, чтобы схема не попала в кэш при регистрации
+    server := httptest.NewServer(fake)
+    defer server.Close()
+    decoder, err := NewDecoder(FormatAvro, NewRegistryClient(server.URL), nil)
+    require.NoError(t, err)
+
+    for i := 0; i < 3; i++ {
+        _, err := decoder.Decode(ctx, value, nil)
+        require.NoError(t, err)
+    }
+    assert.Equal(t, 1, fake.lookups)
+}
+
+func TestCodec_ProtobufWriterSchema(t *testing.T) {
+    ctx := context.Background()
+    _, registry := newFakeRegistry(t)
+
+    // Схема писателя отличается от встроенной: Order идёт вторым, а у track_number другой номер
+    writer := `syntax = "proto3";
+package order;
+message Meta { string source = 1; }
+message Order {
+  string order_uid = 1;
+  string track_number = 20; // номер 2 во встроенной схеме
+  int64 sm_id = 12;
+}`
+    id, err := registry.Register(ctx, "order-value", Schema{Type: SchemaProtobuf, Text: writer})
+    require.NoError(t, err)
+
+    file, err := compileProto(writer)
+    require.NoError(t, err)
+    msg := dynamicpb.NewMessage(file.Messages().Get(1))
+    require.NoError(t, protojson.Unmarshal([]byte(`{"order_uid":"uid","track_number":"TN1","sm_id":"7"}`), msg))
+    payload, err := proto.Marshal(msg)
+    require.NoError(t, err)
+    value := append(appendMessageIndexes(frame(id, nil), 1), payload...)
+
+    decoder, err := NewDecoder(FormatProtobuf, registry, nil)
+    require.NoError(t, err)
+    order, err := decoder.Decode(ctx, value, nil)
+    require.NoError(t, err)
+    assert.Equal(t, entity.Order{OrderUID: "uid", TrackNumber: "TN1", SmID: 7}, order)
+
+    t.Run("Message index out of range", func(t *testing.T) {
+        value := append(appendMessageIndexes(frame(id, nil), 2), payload...)
+        _, err := decoder.Decode(ctx, value, nil)
+        assert.ErrorContains(t, err, "has no message at indexes [2]")
+    })
+
+    t.Run("Invalid schema", func(t *testing.T) {
+        id, err := registry.Register(ctx, "order-value", Schema{Type: SchemaProtobuf, Text: `message Order { strin order_uid = 1; }`})
+        require.NoError(t, err)
+        _, err = decoder.Decode(ctx, appendMessageIndexes(frame(id, nil), 0), nil)
+        assert.ErrorContains(t, err, "invalid protobuf schema")
+    })
+}
+
+func TestCodec_Errors(t *testing.T) {
+    ctx := context.Background()
+
+    t.Run("Unknown schema ID", func(t *testing.T) {
+        _, registry := newFakeRegistry(t)
+        decoder, err := NewDecoder(FormatAvro, registry, nil)
+        require.NoError(t, err)
+
+        _, err = decoder.Decode(ctx, frame(42, []byte{1, 2, 3}), nil)
+        assert.ErrorContains(t, err, "failed to fetch schema 42")
+    })
+
+    t.Run("Schema type mismatch", func(t *testing.T) {
+        _, registry := newFakeRegistry(t)
+        encoder, err := NewEncoder(FormatProtobuf, registry, "order-value")
+        require.NoError(t, err)
+        value, err := encoder.Encode(ctx, testOrder())
+        require.NoError(t, err)
+
+        decoder, err := NewDecoder(FormatAvro, registry, nil)
+        require.NoError(t, err)
+        _, err = decoder.Decode(ctx, value, nil)
+        assert.ErrorContains(t, err, "expected AVRO")
+    })
+
+    t.Run("Registry required", func(t *testing.T) {
+        _, err := NewDecoder(FormatProtobuf, nil, nil)
+        assert.Error(t, err)
+        _, err = NewEncoder(FormatAvro, nil, "order-value")
+        assert.Error(t, err)
+    })
+
+    t.Run("Unsupported format", func(t *testing.T) {
+        _, err := NewDecoder("xml", nil, nil)
+        assert.ErrorContains(t, err, "unsupported message format")
+    })
+}
+
+func TestCodec_Upcasting(t *testing.T) {
+    ctx := context.Background()
+
+    // v1: трек-номер в поле "track"; v2: customer — объект; v3 — текущая форма
+    versions := NewVersions(3).
+        Register(1, func(doc map[string]any) (map[string]any, error) {
+            doc["track_number"] = doc["track"]
+            delete(doc, "track")
+            doc["customer"] = map[string]any{"id": doc["customer_id"]}
+            delete(doc, "customer_id")
+            return doc, nil
+        }).
+        Register(2, func(doc map[string]any) (map[string]any, error) {
+            customer, _ := doc["customer"].(map[string]any)
+            doc["customer_id"] = customer["id"]
+            delete(doc, "customer")
+            return doc, nil
+        })
+
+    decoder, err := NewDecoder(FormatJSON, nil, versions)
+    require.NoError(t, err)
+
+    t.Run("Version from field", func(t *testing.T) {
+        order, err := decoder.Decode(ctx, []byte(`{"version":1,"order_uid":"uid","track":"TN1","customer_id":"c1"}`), nil)
+        require.NoError(t, err)
+        assert.Equal(t, entity.Order{OrderUID: "uid", TrackNumber: "TN1", CustomerID: "c1"}, order)
+    })
+
+    t.Run("Header overrides field", func(t *testing.T) {
+        value := []byte(`{"version":1,"order_uid":"uid","track_number":"TN1","customer":{"id":"c1"}}`)
+        order, err := decoder.Decode(ctx, value, map[string]string{HeaderSchemaVersion: "2"})
+        require.NoError(t, err)
+        assert.Equal(t, entity.Order{OrderUID: "uid", TrackNumber: "TN1", CustomerID: "c1"}, order)
+    })
+
+    t.Run("Current version", func(t *testing.T) {
+        order, err := decoder.Decode(ctx, []byte(`{"version":"3","order_uid":"uid","customer_id":"c1"}`), nil)
+        require.NoError(t, err)
+        assert.Equal(t, entity.Order{OrderUID: "uid", CustomerID: "c1"}, order)
+    })
+
+    t.Run("Unknown future version", func(t *testing.T) {
+        _, err := decoder.Decode(ctx, []byte(`{"order_uid":"uid"}`), map[string]string{HeaderSchemaVersion: "4"})
+        assert.ErrorIs(t, err, ErrUnknownVersion)
+    })
+
+    t.Run("Missing upcaster", func(t *testing.T) {
+        decoder, err := NewDecoder(FormatJSON, nil, NewVersions(2))
+        require.NoError(t, err)
+        _, err = decoder.Decode(ctx, []byte(`{"order_uid":"uid"}`), nil)
+        assert.ErrorContains(t, err, "no upcaster from schema version 1")
+        assert.NotErrorIs(t, err, ErrUnknownVersion)
+    })
+}
@@ -0,0 +1,133 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"order/internal/entity"
+)
+
+// Avro и Protobuf работают с заказом как с обобщённым документом
+// (map[string]any с int64/float64 вместо json.Number), который получается
+// из JSON-представления. Так кодеки не зависят от Go-типов entity.
+// Суммы (entity.Money) в документе — десятичные строки: float64 терял бы точность
+
+// Денежные поля оплаты и каждого товара
+var (
+	paymentMoneyFields = []string{"amount", "delivery_cost", "goods_total", "custom_fee"}
+	itemMoneyFields    = []string{"price", "total_price"}
+)
+
+// toDocument переводит значение в документ для кодирования. Время в схемах Avro
+// и Protobuf прежнее: date_created — строка, payment_dt — Unix-время в секундах
+func toDocument(v any) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := parseDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	if doc["date_created"] == nil {
+		doc["date_created"] = ""
+	}
+	if payment, ok := doc["payment"].(map[string]any); ok {
+		switch dt := payment["payment_dt"].(type) {
+		case nil:
+			payment["payment_dt"] = int64(0)
+		case string:
+			t, err := entity.ParseTime(dt)
+			if err != nil {
+				return nil, err
+			}
+			payment["payment_dt"] = t.Unix()
+		}
+	}
+	return doc, nil
+}
+
+func parseDocument(data []byte) (map[string]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	err := mapMoney(doc, func(v any) (any, error) {
+		if n, ok := v.(json.Number); ok {
+			return n.String(), nil
+		}
+		return v, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	normalizeNumbers(doc)
+	return doc, nil
+}
+
+// mapMoney заменяет значения денежных полей документа результатом fn
+func mapMoney(doc map[string]any, fn func(v any) (any, error)) error {
+	if payment, ok := doc["payment"].(map[string]any); ok {
+		if err := mapFields(payment, paymentMoneyFields, fn); err != nil {
+			return fmt.Errorf("payment: %w", err)
+		}
+	}
+	items, _ := doc["items"].([]any)
+	for i, item := range items {
+		if item, ok := item.(map[string]any); ok {
+			if err := mapFields(item, itemMoneyFields, fn); err != nil {
+				return fmt.Errorf("items[%d]: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+func mapFields(obj map[string]any, fields []string, fn func(v any) (any, error)) error {
+	for _, field := range fields {
+		value, ok := obj[field]
+		if !ok {
+			continue
+		}
+		value, err := fn(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", field, err)
+		}
+		obj[field] = value
+	}
+	return nil
+}
+
+func normalizeNumbers(v any) any {
+	switch x := v.(type) {
+	case map[string]any:
+		for k, val := range x {
+			x[k] = normalizeNumbers(val)
+		}
+	case []any:
+		for i, val := range x {
+			x[i] = normalizeNumbers(val)
+		}
+	case json.Number:
+		if i, err := x.Int64(); err == nil {
+			return i
+		}
+		f, _ := x.Float64()
+		return f
+	}
+	return v
+}
+
+func orderFromDocument(doc map[string]any) (entity.Order, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return entity.Order{}, err
+	}
+	var order entity.Order
+	if err := json.Unmarshal(data, &order); err != nil {
+		return entity.Order{}, err
+	}
+	return order, nil
+}
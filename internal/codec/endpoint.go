@@ -0,0 +1,49 @@
+package codec
+
+import (
+	"context"
+	"order/internal/entity"
+)
+
+// Format — формат значения сообщения с заказом
+type Format string
+
+const (
+	FormatJSON     Format = "json"
+	FormatAvro     Format = "avro"
+	FormatProtobuf Format = "protobuf"
+	// FormatAuto определяет формат по заголовку content-type или magic byte
+	FormatAuto Format = "auto"
+)
+
+// SchemaType — тип схемы в терминах Schema Registry
+type SchemaType string
+
+const (
+	SchemaAvro     SchemaType = "AVRO"
+	SchemaProtobuf SchemaType = "PROTOBUF"
+	SchemaJSON     SchemaType = "JSON"
+)
+
+type Schema struct {
+	Type SchemaType
+	Text string
+}
+
+// Decoder превращает значение сообщения в заказ
+type Decoder interface {
+	Decode(ctx context.Context, value []byte, headers map[string]string) (entity.Order, error)
+}
+
+// Encoder сериализует заказ (любое значение с JSON-представлением заказа)
+type Encoder interface {
+	Encode(ctx context.Context, v any) ([]byte, error)
+	// Headers — заголовки для закодированных сообщений (content-type и версия схемы)
+	Headers() map[string]string
+}
+
+// Registry — клиент Schema Registry
+type Registry interface {
+	Schema(ctx context.Context, id int) (Schema, error)
+	Register(ctx context.Context, subject string, schema Schema) (int, error)
+}
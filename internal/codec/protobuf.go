@@ -0,0 +1,98 @@
+package codec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+type protobufEncoder struct {
+	registry Registry
+	subject  string
+}
+
+func (e *protobufEncoder) Encode(ctx context.Context, v any) ([]byte, error) {
+	id, err := e.registry.Register(ctx, e.subject, Schema{Type: SchemaProtobuf, Text: orderProtoSchema})
+	if err != nil {
+		return nil, err
+	}
+	doc, err := toDocument(v)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := dynamicpb.NewMessage(orderDescriptor)
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, msg); err != nil {
+		return nil, fmt.Errorf("failed to convert order to protobuf: %w", err)
+	}
+	payload, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode protobuf: %w", err)
+	}
+
+	out := frame(id, nil)
+	out = appendMessageIndexes(out, 0)
+	return append(out, payload...), nil
+}
+
+func (e *protobufEncoder) Headers() map[string]string {
+	return encoderHeaders(contentTypeProtobuf)
+}
+
+// decode разбирает сообщение по схеме писателя из реестра: имена полей документа
+// берутся из неё, а не из встроенного order.proto
+func (c *protoSchemas) decode(id int, text string, payload []byte) (map[string]any, error) {
+	indexes, payload, err := consumeMessageIndexes(payload)
+	if err != nil {
+		return nil, err
+	}
+	descriptor, err := c.message(id, text, indexes)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := dynamicpb.NewMessage(descriptor)
+	if err := proto.Unmarshal(payload, msg); err != nil {
+		return nil, fmt.Errorf("failed to decode protobuf: %w", err)
+	}
+	return messageToDocument(msg), nil
+}
+
+// messageToDocument переводит сообщение в документ с именами полей из .proto.
+// protojson здесь не подходит: int64 он выводит строками
+func messageToDocument(msg protoreflect.Message) map[string]any {
+	doc := make(map[string]any)
+	fields := msg.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		value := msg.Get(fd)
+		switch {
+		case fd.IsList():
+			list := value.List()
+			items := make([]any, list.Len())
+			for j := range items {
+				items[j] = fieldValue(fd, list.Get(j))
+			}
+			doc[string(fd.Name())] = items
+		default:
+			doc[string(fd.Name())] = fieldValue(fd, value)
+		}
+	}
+	return doc
+}
+
+func fieldValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	if fd.Kind() == protoreflect.MessageKind {
+		return messageToDocument(v.Message())
+	}
+	return v.Interface()
+}
@@ -0,0 +1,123 @@
+package codec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const registryContentType = "application/vnd.schemaregistry.v1+json"
+
+type registryClient struct {
+	url    string
+	client *http.Client
+
+	mu       sync.RWMutex
+	schemas  map[int]Schema
+	subjects map[string]int // subject + текст схемы -> ID
+}
+
+// NewRegistryClient создаёт клиента Schema Registry с кэшем схем по ID.
+// Схема с заданным ID в реестре неизменна, поэтому кэш не инвалидируется
+func NewRegistryClient(url string) Registry {
+	return &registryClient{
+		url:      strings.TrimRight(url, "/"),
+		client:   &http.Client{Timeout: 10 * time.Second},
+		schemas:  make(map[int]Schema),
+		subjects: make(map[string]int),
+	}
+}
+
+type registrySchema struct {
+	Schema     string     `json:"schema"`
+	SchemaType SchemaType `json:"schemaType,omitempty"`
+}
+
+func (c *registryClient) Schema(ctx context.Context, id int) (Schema, error) {
+	c.mu.RLock()
+	schema, ok := c.schemas[id]
+	c.mu.RUnlock()
+	if ok {
+		return schema, nil
+	}
+
+	var resp registrySchema
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/schemas/ids/%d", id), nil, &resp); err != nil {
+		return Schema{}, fmt.Errorf("failed to fetch schema %d: %w", id, err)
+	}
+	schema = Schema{Type: resp.SchemaType, Text: resp.Schema}
+	if schema.Type == "" {
+		// Реестр не возвращает schemaType для Avro
+		schema.Type = SchemaAvro
+	}
+
+	c.mu.Lock()
+	c.schemas[id] = schema
+	c.mu.Unlock()
+	return schema, nil
+}
+
+func (c *registryClient) Register(ctx context.Context, subject string, schema Schema) (int, error) {
+	key := subject + "\x00" + schema.Text
+	c.mu.RLock()
+	id, ok := c.subjects[key]
+	c.mu.RUnlock()
+	if ok {
+		return id, nil
+	}
+
+	req := registrySchema{Schema: schema.Text}
+	if schema.Type != SchemaAvro {
+		req.SchemaType = schema.Type
+	}
+	var resp struct {
+		ID int `json:"id"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/subjects/"+subject+"/versions", req, &resp); err != nil {
+		return 0, fmt.Errorf("failed to register schema for subject %s: %w", subject, err)
+	}
+
+	c.mu.Lock()
+	c.subjects[key] = resp.ID
+	c.schemas[resp.ID] = schema
+	c.mu.Unlock()
+	return resp.ID, nil
+}
+
+func (c *registryClient) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.url+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", registryContentType)
+	if in != nil {
+		req.Header.Set("Content-Type", registryContentType)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("registry responded %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,89 @@
+package codec
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"sync"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+//go:embed schema/order.avsc
+var orderAvroSchema string
+
+//go:embed schema/order.proto
+var orderProtoSchema string
+
+// orderDescriptor — дескриптор сообщения Order из встроенного order.proto, по нему кодируются
+// исходящие сообщения. Входящие декодируются по схеме писателя из реестра (protoSchemas)
+var orderDescriptor = mustOrderDescriptor()
+
+func mustOrderDescriptor() protoreflect.MessageDescriptor {
+	file, err := compileProto(orderProtoSchema)
+	if err != nil {
+		panic(fmt.Sprintf("invalid order.proto: %v", err))
+	}
+	return file.Messages().Get(0)
+}
+
+// compileProto разбирает и линкует текст .proto-файла. Импорты разрешаются
+// только из стандартных файлов (google/protobuf/*.proto)
+func compileProto(text string) (protoreflect.FileDescriptor, error) {
+	const name = "schema.proto"
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			Accessor: protocompile.SourceAccessorFromMap(map[string]string{name: text}),
+		}),
+	}
+	files, err := compiler.Compile(context.Background(), name)
+	if err != nil {
+		return nil, err
+	}
+	return files[0], nil
+}
+
+// protoSchemas кэширует скомпилированные схемы писателя по ID
+type protoSchemas struct {
+	mu      sync.RWMutex
+	schemas map[int]protoreflect.FileDescriptor
+}
+
+func (c *protoSchemas) get(id int, text string) (protoreflect.FileDescriptor, error) {
+	c.mu.RLock()
+	file, ok := c.schemas[id]
+	c.mu.RUnlock()
+	if ok {
+		return file, nil
+	}
+
+	file, err := compileProto(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid protobuf schema %d: %w", id, err)
+	}
+	c.mu.Lock()
+	c.schemas[id] = file
+	c.mu.Unlock()
+	return file, nil
+}
+
+// message находит сообщение по индексам из кадра: первый — среди сообщений файла,
+// следующие — среди вложенных
+func (c *protoSchemas) message(id int, text string, indexes []int) (protoreflect.MessageDescriptor, error) {
+	file, err := c.get(id, text)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := file.Messages()
+	var msg protoreflect.MessageDescriptor
+	for _, i := range indexes {
+		if i < 0 || i >= messages.Len() {
+			return nil, fmt.Errorf("protobuf schema %d has no message at indexes %v", id, indexes)
+		}
+		msg = messages.Get(i)
+		messages = msg.Messages()
+	}
+	return msg, nil
+}
@@ -0,0 +1,111 @@
+package codec
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// CurrentVersion — версия формы заказа, которой соответствует entity.Order.
+// При изменении формы версия увеличивается, а для предыдущей регистрируется апкастер
+const CurrentVersion = 2
+
+// HeaderSchemaVersion — заголовок с версией формы заказа.
+// Без заголовка версия берётся из поля VersionField документа, а без него считается равной 1
+const (
+	HeaderSchemaVersion = "schema-version"
+	VersionField        = "version"
+)
+
+// ErrUnknownVersion — сообщение записано более новой версией, чем известна сервису.
+// Такие сообщения не отбрасываются, а отправляются в карантин
+var ErrUnknownVersion = errors.New("unknown schema version")
+
+// Upcaster переводит документ заказа из версии N в версию N+1
+type Upcaster func(doc map[string]any) (map[string]any, error)
+
+// Versions — реестр апкастеров, приводящих старые документы к текущей версии
+type Versions struct {
+	current   int
+	upcasters map[int]Upcaster
+}
+
+func NewVersions(current int) *Versions {
+	return &Versions{current: current, upcasters: make(map[int]Upcaster)}
+}
+
+// DefaultVersions используется декодерами по умолчанию; апкастеры
+// для прошлых версий формы заказа регистрируются здесь
+var DefaultVersions = NewVersions(CurrentVersion).
+	Register(1, upcastIntegerMoney)
+
+// upcastIntegerMoney переводит документ из версии 1 в 2. В версии 1 схемы Avro и Protobuf
+// передавали суммы целыми (long/int64) в основных единицах, в версии 2 — десятичными
+func upcastIntegerMoney(doc map[string]any) (map[string]any, error) {
+	err := mapMoney(doc, func(v any) (any, error) {
+		if units, ok := v.(int64); ok {
+			return strconv.FormatInt(units, 10), nil
+		}
+		return v, nil
+	})
+	return doc, err
+}
+
+// Register регистрирует апкастер из версии from в from+1
+func (v *Versions) Register(from int, up Upcaster) *Versions {
+	v.upcasters[from] = up
+	return v
+}
+
+func (v *Versions) Current() int {
+	return v.current
+}
+
+// Upcast последовательно применяет апкастеры от version до текущей версии
+func (v *Versions) Upcast(version int, doc map[string]any) (map[string]any, error) {
+	if version > v.current {
+		return nil, fmt.Errorf("%w %d (current is %d)", ErrUnknownVersion, version, v.current)
+	}
+	if version < 1 {
+		return nil, fmt.Errorf("invalid schema version %d", version)
+	}
+
+	for ; version < v.current; version++ {
+		up, ok := v.upcasters[version]
+		if !ok {
+			return nil, fmt.Errorf("no upcaster from schema version %d", version)
+		}
+		var err error
+		if doc, err = up(doc); err != nil {
+			return nil, fmt.Errorf("failed to upcast from schema version %d: %w", version, err)
+		}
+	}
+	return doc, nil
+}
+
+// versionOf определяет версию документа и убирает служебное поле версии
+func versionOf(doc map[string]any, headers map[string]string) (int, error) {
+	field, hasField := doc[VersionField]
+	delete(doc, VersionField)
+
+	if h, ok := headers[HeaderSchemaVersion]; ok {
+		version, err := strconv.Atoi(h)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s header %q", HeaderSchemaVersion, h)
+		}
+		return version, nil
+	}
+	if !hasField {
+		return 1, nil
+	}
+
+	switch x := field.(type) {
+	case int64:
+		return int(x), nil
+	case string:
+		if version, err := strconv.Atoi(x); err == nil {
+			return version, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid %s field %v", VersionField, field)
+}
@@ -0,0 +1,74 @@
+package codec
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Формат кадра Schema Registry: magic byte 0x0, затем ID схемы (4 байта, big endian)
+const (
+	magicByte  = 0x0
+	headerSize = 5
+)
+
+var errNotFramed = errors.New("message is not framed with a schema ID")
+
+func isFramed(value []byte) bool {
+	return len(value) >= headerSize && value[0] == magicByte
+}
+
+func frame(id int, payload []byte) []byte {
+	out := make([]byte, headerSize, headerSize+len(payload))
+	out[0] = magicByte
+	binary.BigEndian.PutUint32(out[1:], uint32(id))
+	return append(out, payload...)
+}
+
+func unframe(value []byte) (int, []byte, error) {
+	if !isFramed(value) {
+		return 0, nil, errNotFramed
+	}
+	return int(binary.BigEndian.Uint32(value[1:headerSize])), value[headerSize:], nil
+}
+
+// Protobuf-сообщения дополнительно содержат индексы сообщения в .proto-файле:
+// количество и сами индексы в zigzag varint, а путь [0] кодируется одним нулевым байтом
+func appendMessageIndexes(b []byte, indexes ...int) []byte {
+	if len(indexes) == 1 && indexes[0] == 0 {
+		return append(b, 0)
+	}
+	b = protowire.AppendVarint(b, protowire.EncodeZigZag(int64(len(indexes))))
+	for _, i := range indexes {
+		b = protowire.AppendVarint(b, protowire.EncodeZigZag(int64(i)))
+	}
+	return b
+}
+
+func consumeMessageIndexes(b []byte) ([]int, []byte, error) {
+	v, n := protowire.ConsumeVarint(b)
+	if n < 0 {
+		return nil, nil, fmt.Errorf("invalid message indexes: %w", protowire.ParseError(n))
+	}
+	b = b[n:]
+	count := protowire.DecodeZigZag(v)
+	if count == 0 {
+		return []int{0}, b, nil
+	}
+	if count < 0 || count > int64(len(b)) {
+		return nil, nil, fmt.Errorf("invalid message indexes count %d", count)
+	}
+
+	indexes := make([]int, count)
+	for i := range indexes {
+		v, n := protowire.ConsumeVarint(b)
+		if n < 0 {
+			return nil, nil, fmt.Errorf("invalid message index: %w", protowire.ParseError(n))
+		}
+		indexes[i] = int(protowire.DecodeZigZag(v))
+		b = b[n:]
+	}
+	return indexes, b, nil
+}
@@ -0,0 +1,44 @@
+package v1
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"order/internal/controller/kafka"
+)
+
+// AdminHandler обслуживает служебные эндпоинты для операторов
+type AdminHandler struct {
+	replayer kafka.Replayer
+}
+
+func NewAdminHandler(replayer kafka.Replayer) *AdminHandler {
+	return &AdminHandler{replayer: replayer}
+}
+
+// Replay перечитывает заданный диапазон топика и возвращает отчёт.
+// Запрос синхронный: ответ приходит после обработки всего диапазона
+func (h *AdminHandler) Replay(w http.ResponseWriter, r *http.Request) {
+	var req kafka.ReplayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid replay request: %v", err)
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Topic == "" {
+		http.Error(w, "topic is required", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.replayer.Replay(r.Context(), req)
+	if err != nil {
+		log.Printf("Replay of topic %s failed: %v", req.Topic, err)
+		http.Error(w, "Replay failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		log.Printf("Failed to encode replay report: %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package v1
+
+import (
+    "encoding/json"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "order/internal/controller/kafka"
+    "order/internal/controller/kafka/mock"
+    "strings"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "go.uber.org/mock/gomock"
+)
+
+func TestAdminHandler_Replay(t *testing.T) {
+    t.Run("Successful replay", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        mockReplayer := mock.NewMockReplayer(ctrl)
+        handler := NewAdminHandler(mockReplayer)
+
+        offset := int64(10)
+        expected := kafka.ReplayRequest{
+            Topic:  "order",
+            Ranges: []kafka.PartitionRange{{Partition: 0, From: kafka.Bound{Offset: &offset}}},
+        }
+        report := kafka.ReplayReport{Topic: "order", New: 2, Updated: 1, Rejected: 1}
+
+        body := `{"topic":"order","ranges":[{"partition":0,"from":{"offset":10}}]}`
+        req := httptest.NewRequest(http.MethodPost, "/admin/replay", strings.NewReader(body))
+        mockReplayer.EXPECT().Replay(req.Context(), expected).Return(report, nil)
+
+        w := httptest.NewRecorder()
+        handler.Replay(w, req)
+
+        assert.Equal(t, http.StatusOK, w.Code)
+        var result kafka.ReplayReport
+        assert.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+        assert.Equal(t, report, result)
+    })
+
+    t.Run("Invalid body", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        handler := NewAdminHandler(mock.NewMockReplayer(ctrl))
+
+        req := httptest.NewRequest(http.MethodPost, "/admin/replay", strings.NewReader("{"))
+        w := httptest.NewRecorder()
+        handler.Replay(w, req)
+
+        assert.Equal(t, http.StatusBadRequest, w.Code)
+    })
+
+    t.Run("Missing topic", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        handler := NewAdminHandler(mock.NewMockReplayer(ctrl))
+
+        req := httptest.NewRequest(http.MethodPost, "/admin/replay", strings.NewReader(`{}`))
+        w := httptest.NewRecorder()
+        handler.Replay(w, req)
+
+        assert.Equal(t, http.StatusBadRequest, w.Code)
+        assert.Contains(t, w.Body.String(), "topic is required")
+    })
+
+    t.Run("Replay error", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        mockReplayer := mock.NewMockReplayer(ctrl)
+        handler := NewAdminHandler(mockReplayer)
+
+        req := httptest.NewRequest(http.MethodPost, "/admin/replay", strings.NewReader(`{"topic":"order"}`))
+        mockReplayer.EXPECT().Replay(req.Context(), kafka.ReplayRequest{Topic: "order"}).
+            Return(kafka.ReplayReport{}, errors.New("broker down"))
+
+        w := httptest.NewRecorder()
+        handler.Replay(w, req)
+
+        assert.Equal(t, http.StatusInternalServerError, w.Code)
+        assert.Contains(t, w.Body.String(), "broker down")
+    })
+}
+
+func TestAdminAuth(t *testing.T) {
+    ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+
+    tests := []struct {
+        name   string
+        token  string
+        header string
+        want   int
+    }{
+        {"Valid token", "secret", "Bearer secret", http.StatusNoContent},
+        {"Wrong token", "secret", "Bearer other", http.StatusUnauthorized},
+        {"Missing header", "secret", "", http.StatusUnauthorized},
+        {"Basic scheme", "secret", "Basic secret", http.StatusUnauthorized},
+        {"Admin disabled", "", "Bearer ", http.StatusForbidden},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            req := httptest.NewRequest(http.MethodPost, "/admin/replay", nil)
+            if tt.header != "" {
+                req.Header.Set("Authorization", tt.header)
+            }
+            w := httptest.NewRecorder()
+            AdminAuth(tt.token)(ok).ServeHTTP(w, req)
+            assert.Equal(t, tt.want, w.Code)
+        })
+    }
+}
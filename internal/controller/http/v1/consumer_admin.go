@@ -0,0 +1,157 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"order/internal/controller/kafka"
+	"order/internal/service"
+	"order/internal/source"
+	"order/internal/storage"
+	"strconv"
+)
+
+// defaultErrorsLimit — сколько ошибок отдаётся, если limit не указан
+const defaultErrorsLimit = 20
+
+// ConsumerAdminHandler управляет приёмом заказов и кэшем без перезапуска процесса
+type ConsumerAdminHandler struct {
+	consumer kafka.KafkaController // nil, если Kafka не используется
+	service  service.Service
+}
+
+func NewConsumerAdminHandler(consumer kafka.KafkaController, service service.Service) *ConsumerAdminHandler {
+	return &ConsumerAdminHandler{consumer: consumer, service: service}
+}
+
+// PartitionsRequest — тело запросов pause/resume; пустой список — все партиции
+type PartitionsRequest struct {
+	Partitions []source.Partition `json:"partitions"`
+}
+
+// PartitionsResponse — ответ со списком назначенных партиций
+type PartitionsResponse struct {
+	// StoragePaused — чтение приостановлено из-за недоступности БД
+	StoragePaused bool                    `json:"storage_paused"`
+	Partitions    []kafka.PartitionStatus `json:"partitions"`
+}
+
+// CacheResponse — результат сброса или прогрева кэша
+type CacheResponse struct {
+	Evicted int `json:"evicted,omitempty"`
+	Size    int `json:"size"`
+}
+
+func (h *ConsumerAdminHandler) Pause(w http.ResponseWriter, r *http.Request) {
+	h.setPaused(w, r, true)
+}
+
+func (h *ConsumerAdminHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	h.setPaused(w, r, false)
+}
+
+func (h *ConsumerAdminHandler) setPaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	if !h.requireConsumer(w) {
+		return
+	}
+	var req PartitionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		log.Printf("Invalid pause request: %v", err)
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var err error
+	if paused {
+		err = h.consumer.Pause(req.Partitions)
+	} else {
+		err = h.consumer.Resume(req.Partitions)
+	}
+	if errors.Is(err, kafka.ErrNotAssigned) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to change pause of partitions %v: %v", req.Partitions, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	h.Partitions(w, r)
+}
+
+// Partitions возвращает назначенные партиции, закоммиченные смещения и отставание
+func (h *ConsumerAdminHandler) Partitions(w http.ResponseWriter, r *http.Request) {
+	if !h.requireConsumer(w) {
+		return
+	}
+	partitions, err := h.consumer.Partitions(r.Context())
+	if err != nil {
+		log.Printf("Failed to get partition offsets: %v", err)
+		http.Error(w, "Failed to get partition offsets", http.StatusBadGateway)
+		return
+	}
+	writeJSON(w, PartitionsResponse{StoragePaused: h.consumer.Paused(), Partitions: partitions})
+}
+
+// Errors возвращает последние ошибки обработки; ?limit=N, по умолчанию 20
+func (h *ConsumerAdminHandler) Errors(w http.ResponseWriter, r *http.Request) {
+	if !h.requireConsumer(w) {
+		return
+	}
+	limit := defaultErrorsLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	writeJSON(w, h.consumer.Errors(limit))
+}
+
+func (h *ConsumerAdminHandler) FlushCache(w http.ResponseWriter, r *http.Request) {
+	evicted := h.service.FlushCache()
+	writeJSON(w, CacheResponse{Evicted: evicted, Size: h.service.CacheSize()})
+}
+
+// RewarmCache догружает кэш из БД (в режиме partitioned — только заказы своих партиций)
+func (h *ConsumerAdminHandler) RewarmCache(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.LoadCacheFromDB(r.Context()); err != nil {
+		log.Printf("Failed to rewarm cache: %v", err)
+		http.Error(w, "Failed to load orders from database", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, CacheResponse{Size: h.service.CacheSize()})
+}
+
+// IncompleteOrders перечисляет заказы без доставки или оплаты; исправить их можно через replay
+func (h *ConsumerAdminHandler) IncompleteOrders(w http.ResponseWriter, r *http.Request) {
+	orders, err := h.service.IncompleteOrders(r.Context())
+	if err != nil {
+		log.Printf("Failed to list incomplete orders: %v", err)
+		http.Error(w, "Failed to query database", http.StatusServiceUnavailable)
+		return
+	}
+	if orders == nil {
+		orders = []storage.IncompleteOrder{}
+	}
+	writeJSON(w, orders)
+}
+
+func (h *ConsumerAdminHandler) requireConsumer(w http.ResponseWriter) bool {
+	if h.consumer == nil {
+		http.Error(w, "Kafka consumer is not running", http.StatusNotFound)
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed to encode response: %v", err)
+	}
+}
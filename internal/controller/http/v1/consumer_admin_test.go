@@ -0,0 +1,188 @@
+package v1
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "order/internal/controller/kafka"
+    "order/internal/controller/kafka/mock"
+    servicemock "order/internal/service/mock"
+    "order/internal/source"
+    "order/internal/storage"
+    "strings"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "go.uber.org/mock/gomock"
+)
+
+func TestConsumerAdminHandler(t *testing.T) {
+    p0 := source.Partition{Topic: "orders", Partition: 0}
+
+    t.Run("Pause partition", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        consumer := mock.NewMockKafkaController(ctrl)
+        handler := NewConsumerAdminHandler(consumer, servicemock.NewMockService(ctrl))
+
+        statuses := []kafka.PartitionStatus{{Offsets: source.Offsets{Partition: p0, Committed: 5, High: 8}, Lag: 3, Held: true, Paused: true}}
+        req := httptest.NewRequest(http.MethodPost, "/admin/consumer/pause", strings.NewReader(`{"partitions":[{"topic":"orders","partition":0}]}`))
+        consumer.EXPECT().Pause([]source.Partition{p0}).Return(nil)
+        consumer.EXPECT().Partitions(req.Context()).Return(statuses, nil)
+        consumer.EXPECT().Paused().Return(false)
+
+        w := httptest.NewRecorder()
+        handler.Pause(w, req)
+
+        assert.Equal(t, http.StatusOK, w.Code)
+        var result PartitionsResponse
+        require.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+        assert.Equal(t, PartitionsResponse{Partitions: statuses}, result)
+    })
+
+    t.Run("Resume all with empty body", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        consumer := mock.NewMockKafkaController(ctrl)
+        handler := NewConsumerAdminHandler(consumer, servicemock.NewMockService(ctrl))
+
+        req := httptest.NewRequest(http.MethodPost, "/admin/consumer/resume", nil)
+        consumer.EXPECT().Resume(nil).Return(nil)
+        consumer.EXPECT().Partitions(req.Context()).Return(nil, nil)
+        consumer.EXPECT().Paused().Return(true)
+
+        w := httptest.NewRecorder()
+        handler.Resume(w, req)
+
+        assert.Equal(t, http.StatusOK, w.Code)
+        assert.Contains(t, w.Body.String(), `"storage_paused":true`)
+    })
+
+    t.Run("Partition not assigned", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        consumer := mock.NewMockKafkaController(ctrl)
+        handler := NewConsumerAdminHandler(consumer, servicemock.NewMockService(ctrl))
+
+        consumer.EXPECT().Pause(gomock.Any()).Return(fmt.Errorf("%w: orders[7]", kafka.ErrNotAssigned))
+
+        req := httptest.NewRequest(http.MethodPost, "/admin/consumer/pause", strings.NewReader(`{"partitions":[{"topic":"orders","partition":7}]}`))
+        w := httptest.NewRecorder()
+        handler.Pause(w, req)
+
+        assert.Equal(t, http.StatusConflict, w.Code)
+    })
+
+    t.Run("Errors with limit", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        consumer := mock.NewMockKafkaController(ctrl)
+        handler := NewConsumerAdminHandler(consumer, servicemock.NewMockService(ctrl))
+
+        errs := []kafka.ProcessingError{{Partition: p0, Offset: 3, Stage: kafka.StageDecode, Error: "invalid character"}}
+        consumer.EXPECT().Errors(5).Return(errs)
+
+        w := httptest.NewRecorder()
+        handler.Errors(w, httptest.NewRequest(http.MethodGet, "/admin/consumer/errors?limit=5", nil))
+
+        assert.Equal(t, http.StatusOK, w.Code)
+        var result []kafka.ProcessingError
+        require.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+        assert.Equal(t, errs, result)
+    })
+
+    t.Run("Invalid limit", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        handler := NewConsumerAdminHandler(mock.NewMockKafkaController(ctrl), servicemock.NewMockService(ctrl))
+
+        w := httptest.NewRecorder()
+        handler.Errors(w, httptest.NewRequest(http.MethodGet, "/admin/consumer/errors?limit=-1", nil))
+
+        assert.Equal(t, http.StatusBadRequest, w.Code)
+    })
+
+    t.Run("Consumer is not running", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        handler := NewConsumerAdminHandler(nil, servicemock.NewMockService(ctrl))
+
+        w := httptest.NewRecorder()
+        handler.Partitions(w, httptest.NewRequest(http.MethodGet, "/admin/consumer/partitions", nil))
+
+        assert.Equal(t, http.StatusNotFound, w.Code)
+    })
+
+    t.Run("Flush cache", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        svc := servicemock.NewMockService(ctrl)
+        handler := NewConsumerAdminHandler(nil, svc)
+        svc.EXPECT().FlushCache().Return(42)
+        svc.EXPECT().CacheSize().Return(0)
+
+        w := httptest.NewRecorder()
+        handler.FlushCache(w, httptest.NewRequest(http.MethodPost, "/admin/cache/flush", nil))
+
+        assert.Equal(t, http.StatusOK, w.Code)
+        var result CacheResponse
+        require.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+        assert.Equal(t, CacheResponse{Evicted: 42}, result)
+    })
+
+    t.Run("Rewarm cache", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        svc := servicemock.NewMockService(ctrl)
+        handler := NewConsumerAdminHandler(nil, svc)
+        req := httptest.NewRequest(http.MethodPost, "/admin/cache/rewarm", nil)
+        svc.EXPECT().LoadCacheFromDB(req.Context()).Return(nil)
+        svc.EXPECT().CacheSize().Return(10)
+
+        w := httptest.NewRecorder()
+        handler.RewarmCache(w, req)
+
+        assert.Equal(t, http.StatusOK, w.Code)
+        assert.JSONEq(t, `{"size":10}`, w.Body.String())
+    })
+
+    t.Run("Rewarm cache with database down", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        svc := servicemock.NewMockService(ctrl)
+        handler := NewConsumerAdminHandler(nil, svc)
+        svc.EXPECT().LoadCacheFromDB(gomock.Any()).Return(errors.New("connection refused"))
+
+        w := httptest.NewRecorder()
+        handler.RewarmCache(w, httptest.NewRequest(http.MethodPost, "/admin/cache/rewarm", nil))
+
+        assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+    })
+
+    t.Run("Incomplete orders", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        svc := servicemock.NewMockService(ctrl)
+        handler := NewConsumerAdminHandler(nil, svc)
+        svc.EXPECT().IncompleteOrders(gomock.Any()).Return([]storage.IncompleteOrder{{OrderUID: "uid-1", Missing: []string{storage.PartPayment}}}, nil)
+
+        w := httptest.NewRecorder()
+        handler.IncompleteOrders(w, httptest.NewRequest(http.MethodGet, "/admin/orders/incomplete", nil))
+
+        assert.Equal(t, http.StatusOK, w.Code)
+        assert.JSONEq(t, `[{"order_uid":"uid-1","missing":["payment"]}]`, w.Body.String())
+    })
+}
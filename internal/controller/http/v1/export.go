@@ -0,0 +1,81 @@
+package v1
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"order/internal/breaker"
+	"order/internal/export"
+	"strings"
+	"time"
+)
+
+// ExportOrders выгружает заказы потоком:
+// GET /orders/export?format=csv|ndjson|parquet[&rows=item|order][&columns=a,b,...][&<ключ поиска>=<значение>]
+func (h *Handler) ExportOrders(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	opts := export.Options{Format: export.Format(query.Get("format")), Rows: export.Rows(query.Get("rows"))}
+	if opts.Format == "" {
+		opts.Format = export.FormatCSV
+	}
+	if columns := query.Get("columns"); columns != "" {
+		opts.Columns = strings.Split(columns, ",")
+	}
+	filter, ok := lookupParam(query)
+	if !ok {
+		http.Error(w, "at most one lookup parameter is allowed", http.StatusBadRequest)
+		return
+	}
+	// Ошибки параметров проверяются до начала ответа: потом статус уже не изменить
+	if err := errors.Join(opts.Validate(), filter.Validate()); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Ответ буферизуется: если БД недоступна с самого начала, клиент получит статус ошибки,
+	// а не пустой файл с кодом 200
+	sent := &countingWriter{w: w}
+	buf := bufio.NewWriterSize(sent, exportBufferSize)
+	filename := fmt.Sprintf("orders-%s.%s", time.Now().UTC().Format("20060102-150405"), opts.Format)
+	w.Header().Set("Content-Type", opts.Format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	summary, err := export.Export(buf, h.service.Orders(r.Context(), filter), opts)
+	if err == nil {
+		err = buf.Flush()
+	}
+	if err != nil {
+		log.Printf("Export interrupted after %d orders: %v", summary.Orders, err)
+		if sent.n == 0 {
+			w.Header().Del("Content-Disposition")
+			if errors.Is(err, breaker.ErrOpen) {
+				http.Error(w, "Storage is temporarily unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		// Статус 200 уже отправлен. Обычный return завершил бы ответ как полный, и клиент
+		// получил бы усечённый файл без признаков ошибки; ErrAbortHandler обрывает соединение
+		// без завершающего чанка, и клиент получает ошибку чтения
+		panic(http.ErrAbortHandler)
+	}
+	log.Printf("Exported %d orders (%d rows) as %s", summary.Orders, summary.Rows, opts.Format)
+}
+
+const exportBufferSize = 64 << 10
+
+// countingWriter считает байты, ушедшие клиенту
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,107 @@
+package v1
+
+import (
+    "errors"
+    "fmt"
+    "io"
+    "iter"
+    "net/http"
+    "net/http/httptest"
+    "order/internal/breaker"
+    "order/internal/entity"
+    "order/internal/service/mock"
+    "order/internal/storage"
+    "strings"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "go.uber.org/mock/gomock"
+)
+
+func ordersSeq(orders []entity.Order, err error) iter.Seq2[entity.Order, error] {
+    return func(yield func(entity.Order, error) bool) {
+        for _, order := range orders {
+            if !yield(order, nil) {
+                return
+            }
+        }
+        if err != nil {
+            yield(entity.Order{}, err)
+        }
+    }
+}
+
+func TestHandler_ExportOrders(t *testing.T) {
+    serve := func(handler *Handler, target string) *httptest.ResponseRecorder {
+        w := httptest.NewRecorder()
+        handler.ExportOrders(w, httptest.NewRequest(http.MethodGet, target, nil))
+        return w
+    }
+
+    t.Run("CSV export", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        mockService := mock.NewMockService(ctrl)
+        orders := []entity.Order{{OrderUID: "uid-1", Items: []entity.Item{{Name: "Mascaras"}}}, {OrderUID: "uid-2"}}
+        mockService.EXPECT().Orders(gomock.Any(), storage.Filter{Field: storage.ByCustomer, Value: "test"}).Return(ordersSeq(orders, nil))
+
+        w := serve(NewHandler(mockService), "/orders/export?columns=order_uid,item_name&customer_id=test")
+
+        assert.Equal(t, http.StatusOK, w.Code)
+        assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+        assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+        assert.Equal(t, "order_uid,item_name\nuid-1,Mascaras\nuid-2,\n", w.Body.String())
+    })
+
+    t.Run("Bad requests", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        handler := NewHandler(mock.NewMockService(ctrl))
+
+        assert.Equal(t, http.StatusBadRequest, serve(handler, "/orders/export?format=xml").Code)
+        assert.Equal(t, http.StatusBadRequest, serve(handler, "/orders/export?columns=secret").Code)
+        assert.Equal(t, http.StatusBadRequest, serve(handler, "/orders/export?rows=order&columns=item_name").Code)
+        assert.Equal(t, http.StatusBadRequest, serve(handler, "/orders/export?rid=a&phone=b").Code)
+        assert.Equal(t, http.StatusBadRequest, serve(handler, "/orders/export?nm_id=abc").Code)
+    })
+
+    t.Run("Storage unavailable", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        mockService := mock.NewMockService(ctrl)
+        mockService.EXPECT().Orders(gomock.Any(), storage.Filter{}).Return(ordersSeq(nil, breaker.ErrOpen))
+
+        w := serve(NewHandler(mockService), "/orders/export?format=ndjson")
+        assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+        assert.Empty(t, w.Header().Get("Content-Disposition"))
+    })
+
+    t.Run("Error mid-stream aborts the response", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        // Больше буфера ответа: первая часть уходит клиенту до ошибки
+        orders := make([]entity.Order, 2000)
+        for i := range orders {
+            orders[i] = entity.Order{OrderUID: fmt.Sprintf("uid-%d", i), TrackNumber: strings.Repeat("T", 64)}
+        }
+        mockService := mock.NewMockService(ctrl)
+        mockService.EXPECT().Orders(gomock.Any(), storage.Filter{}).Return(ordersSeq(orders, errors.New("connection reset")))
+
+        server := httptest.NewServer(http.HandlerFunc(NewHandler(mockService).ExportOrders))
+        defer server.Close()
+
+        resp, err := http.Get(server.URL + "/orders/export?format=ndjson")
+        require.NoError(t, err)
+        defer resp.Body.Close()
+        assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+        body, err := io.ReadAll(resp.Body)
+        assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+        assert.NotEmpty(t, body)
+    })
+}
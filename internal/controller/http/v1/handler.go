@@ -2,9 +2,15 @@ package v1
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"net/url"
+	"order/internal/breaker"
+	"order/internal/entity"
 	"order/internal/service"
+	"order/internal/storage"
+	"strconv"
 
 	"github.com/gorilla/mux"
 )
@@ -29,6 +35,16 @@ func (h *Handler) GetOrder(w http.ResponseWriter, r *http.Request) {
 	order, err := h.service.GetOrder(r.Context(), orderUID)
 	if err != nil {
 		log.Printf("Failed to get order %s: %v", orderUID, err)
+		if errors.Is(err, breaker.ErrOpen) {
+			// Заказа нет в кэше, а база недоступна — это не «не найден»
+			http.Error(w, "Storage is temporarily unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if errors.Is(err, storage.ErrIncomplete) {
+			// Заказ есть, но без доставки или оплаты — отдавать его частично нельзя
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
 		http.Error(w, "Order not found", http.StatusNotFound)
 		return
 	}
@@ -42,3 +58,107 @@ func (h *Handler) GetOrder(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("Successfully served order %s", orderUID)
 }
+
+// FindOrders ищет заказы по одному вторичному ключу:
+// GET /orders?track_number=|customer_id=|rid=|chrt_id=|nm_id=|phone=|email=[&limit=]
+func (h *Handler) FindOrders(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter, ok := lookupParam(query)
+	if !ok || filter.Field == "" {
+		http.Error(w, "exactly one lookup parameter is required", http.StatusBadRequest)
+		return
+	}
+	lookup := storage.Lookup{Field: filter.Field, Value: filter.Value}
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		lookup.Limit = limit
+	}
+
+	orders, err := h.service.FindOrders(r.Context(), lookup)
+	if err != nil {
+		log.Printf("Failed to find orders by %s: %v", lookup.Field, err)
+		switch {
+		case errors.Is(err, storage.ErrInvalidLookup):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, breaker.ErrOpen):
+			http.Error(w, "Storage is temporarily unavailable", http.StatusServiceUnavailable)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+	if orders == nil {
+		orders = []entity.Order{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(orders); err != nil {
+		log.Printf("Failed to encode response for lookup by %s: %v", lookup.Field, err)
+		return
+	}
+	log.Printf("Served %d orders by %s", len(orders), lookup.Field)
+}
+
+// lookupParam читает ключ поиска из параметров запроса; false — указано несколько ключей
+func lookupParam(query url.Values) (storage.Filter, bool) {
+	var filter storage.Filter
+	for _, field := range storage.LookupFields {
+		if !query.Has(string(field)) {
+			continue
+		}
+		if filter.Field != "" {
+			return storage.Filter{}, false
+		}
+		filter = storage.Filter{Field: field, Value: query.Get(string(field))}
+	}
+	return filter, true
+}
+
+// SearchOrders — полнотекстовый поиск: GET /orders/search?q=<запрос>[&limit=][&offset=]
+func (h *Handler) SearchOrders(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	search := storage.Search{Text: query.Get("q")}
+	for name, value := range map[string]*int{"limit": &search.Limit, "offset": &search.Offset} {
+		raw := query.Get(name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			http.Error(w, name+" must be an integer", http.StatusBadRequest)
+			return
+		}
+		*value = n
+	}
+	if query.Has("limit") && search.Limit <= 0 {
+		http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.service.SearchOrders(r.Context(), search)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrInvalidSearch):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, breaker.ErrOpen):
+			http.Error(w, "Storage is temporarily unavailable", http.StatusServiceUnavailable)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+	if page.Hits == nil {
+		page.Hits = []storage.SearchHit{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		log.Printf("Failed to encode search response: %v", err)
+		return
+	}
+	log.Printf("Served %d of %d orders found by search", len(page.Hits), page.Total)
+}
@@ -3,11 +3,15 @@ package v1
 import (
     "encoding/json"
     "errors"
+    "fmt"
     "net/http"
     "net/http/httptest"
+    "order/internal/breaker"
     "order/internal/entity"
     "order/internal/service/mock"
+    "order/internal/storage"
     "testing"
+    "time"
 
     "github.com/gorilla/mux"
     "github.com/stretchr/testify/assert"
@@ -26,9 +30,9 @@ func TestHandler_GetOrder(t *testing.T) {
         order := entity.Order{
             OrderUID:    orderUID,
             Delivery:    entity.Delivery{Name: "John", Phone: "1234567890"},
-            Payment:     entity.Payment{Amount: 1000},
-            Items:       []entity.Item{{ChrtID: 1, Price: 500}},
-            DateCreated: "2025-08-09T10:30:00Z",
+            Payment:     entity.Payment{Amount: entity.NewMoney(1000)},
+            Items:       []entity.Item{{ChrtID: 1, Price: entity.NewMoney(500)}},
+            DateCreated: time.Date(2025, 8, 9, 10, 30, 0, 0, time.UTC),
         }
         req := httptest.NewRequest(http.MethodGet, "/order/"+orderUID, nil)
         req = mux.SetURLVars(req, map[string]string{"order_uid": orderUID})
@@ -82,6 +86,45 @@ func TestHandler_GetOrder(t *testing.T) {
         assert.Contains(t, w.Body.String(), "Order not found")
     })
 
+    t.Run("Storage unavailable", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        mockService := mock.NewMockService(ctrl)
+        handler := NewHandler(mockService)
+
+        orderUID := "test-uid"
+        req := httptest.NewRequest(http.MethodGet, "/order/"+orderUID, nil)
+        req = mux.SetURLVars(req, map[string]string{"order_uid": orderUID})
+        ctx := req.Context()
+        mockService.EXPECT().GetOrder(ctx, orderUID).Return(entity.Order{}, fmt.Errorf("get order: %w", breaker.ErrOpen))
+
+        w := httptest.NewRecorder()
+        handler.GetOrder(w, req)
+
+        assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+    })
+
+    t.Run("Incomplete order", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        mockService := mock.NewMockService(ctrl)
+        handler := NewHandler(mockService)
+
+        orderUID := "test-uid"
+        req := httptest.NewRequest(http.MethodGet, "/order/"+orderUID, nil)
+        req = mux.SetURLVars(req, map[string]string{"order_uid": orderUID})
+        ctx := req.Context()
+        mockService.EXPECT().GetOrder(ctx, orderUID).Return(entity.Order{}, storage.IncompleteOrder{OrderUID: orderUID, Missing: []string{storage.PartDelivery}})
+
+        w := httptest.NewRecorder()
+        handler.GetOrder(w, req)
+
+        assert.Equal(t, http.StatusInternalServerError, w.Code)
+        assert.Contains(t, w.Body.String(), "missing delivery")
+    })
+
     t.Run("JSON encode error", func(t *testing.T) {
         ctrl := gomock.NewController(t)
         defer ctrl.Finish()
@@ -93,9 +136,9 @@ func TestHandler_GetOrder(t *testing.T) {
         order := entity.Order{
             OrderUID:    orderUID,
             Delivery:    entity.Delivery{Name: "John", Phone: "1234567890"},
-            Payment:     entity.Payment{Amount: 1000},
-            Items:       []entity.Item{{ChrtID: 1, Price: 500}},
-            DateCreated: "2025-08-09T10:30:00Z",
+            Payment:     entity.Payment{Amount: entity.NewMoney(1000)},
+            Items:       []entity.Item{{ChrtID: 1, Price: entity.NewMoney(500)}},
+            DateCreated: time.Date(2025, 8, 9, 10, 30, 0, 0, time.UTC),
         }
         req := httptest.NewRequest(http.MethodGet, "/order/"+orderUID, nil)
         req = mux.SetURLVars(req, map[string]string{"order_uid": orderUID})
@@ -134,3 +177,118 @@ func (w *errorResponseWriter) Write(b []byte) (int, error) {
 func (w *errorResponseWriter) WriteHeader(statusCode int) {
     w.Recorder.WriteHeader(statusCode)
 }
+
+func TestHandler_FindOrders(t *testing.T) {
+    serve := func(handler *Handler, target string) *httptest.ResponseRecorder {
+        w := httptest.NewRecorder()
+        handler.FindOrders(w, httptest.NewRequest(http.MethodGet, target, nil))
+        return w
+    }
+
+    t.Run("Found orders", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        mockService := mock.NewMockService(ctrl)
+        orders := []entity.Order{{OrderUID: "uid-2"}, {OrderUID: "uid-1"}}
+        mockService.EXPECT().FindOrders(gomock.Any(), storage.Lookup{Field: storage.ByEmail, Value: "Test@Gmail.com", Limit: 5}).Return(orders, nil)
+
+        w := serve(NewHandler(mockService), "/orders?email=Test%40Gmail.com&limit=5")
+
+        assert.Equal(t, http.StatusOK, w.Code)
+        var result []entity.Order
+        assert.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+        assert.Equal(t, orders, result)
+    })
+
+    t.Run("Nothing found is an empty list", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        mockService := mock.NewMockService(ctrl)
+        mockService.EXPECT().FindOrders(gomock.Any(), storage.Lookup{Field: storage.ByTrackNumber, Value: "TRACK"}).Return(nil, nil)
+
+        w := serve(NewHandler(mockService), "/orders?track_number=TRACK")
+
+        assert.Equal(t, http.StatusOK, w.Code)
+        assert.JSONEq(t, "[]", w.Body.String())
+    })
+
+    t.Run("Bad requests", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        mockService := mock.NewMockService(ctrl)
+        mockService.EXPECT().FindOrders(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("%w: nm_id must be an integer", storage.ErrInvalidLookup))
+        handler := NewHandler(mockService)
+
+        assert.Equal(t, http.StatusBadRequest, serve(handler, "/orders").Code)
+        assert.Equal(t, http.StatusBadRequest, serve(handler, "/orders?rid=a&phone=b").Code)
+        assert.Equal(t, http.StatusBadRequest, serve(handler, "/orders?rid=a&limit=0").Code)
+        assert.Equal(t, http.StatusBadRequest, serve(handler, "/orders?nm_id=abc").Code)
+    })
+
+    t.Run("Storage unavailable", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        mockService := mock.NewMockService(ctrl)
+        mockService.EXPECT().FindOrders(gomock.Any(), gomock.Any()).Return(nil, breaker.ErrOpen)
+
+        w := serve(NewHandler(mockService), "/orders?customer_id=test")
+        assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+    })
+}
+
+func TestHandler_SearchOrders(t *testing.T) {
+    serve := func(handler *Handler, target string) *httptest.ResponseRecorder {
+        w := httptest.NewRecorder()
+        handler.SearchOrders(w, httptest.NewRequest(http.MethodGet, target, nil))
+        return w
+    }
+
+    t.Run("Search page", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        mockService := mock.NewMockService(ctrl)
+        page := storage.SearchPage{
+            Hits:  []storage.SearchHit{{Order: entity.Order{OrderUID: "uid-1"}, Rank: 0.5, Highlights: []string{"<mark>Moscow</mark>"}}},
+            Total: 3, Limit: 1, Offset: 2,
+        }
+        mockService.EXPECT().SearchOrders(gomock.Any(), storage.Search{Text: "moscow", Limit: 1, Offset: 2}).Return(page, nil)
+
+        w := serve(NewHandler(mockService), "/orders/search?q=moscow&limit=1&offset=2")
+
+        assert.Equal(t, http.StatusOK, w.Code)
+        var result storage.SearchPage
+        assert.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+        assert.Equal(t, page, result)
+    })
+
+    t.Run("Nothing found", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        mockService := mock.NewMockService(ctrl)
+        mockService.EXPECT().SearchOrders(gomock.Any(), storage.Search{Text: "nothing"}).Return(storage.SearchPage{Limit: 20}, nil)
+
+        w := serve(NewHandler(mockService), "/orders/search?q=nothing")
+
+        assert.Equal(t, http.StatusOK, w.Code)
+        assert.JSONEq(t, `{"hits":[],"total":0,"limit":20,"offset":0}`, w.Body.String())
+    })
+
+    t.Run("Bad requests", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        mockService := mock.NewMockService(ctrl)
+        mockService.EXPECT().SearchOrders(gomock.Any(), storage.Search{}).Return(storage.SearchPage{}, fmt.Errorf("%w: empty query", storage.ErrInvalidSearch))
+        handler := NewHandler(mockService)
+
+        assert.Equal(t, http.StatusBadRequest, serve(handler, "/orders/search").Code)
+        assert.Equal(t, http.StatusBadRequest, serve(handler, "/orders/search?q=a&limit=x").Code)
+        assert.Equal(t, http.StatusBadRequest, serve(handler, "/orders/search?q=a&limit=0").Code)
+    })
+}
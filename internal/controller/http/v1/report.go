@@ -0,0 +1,66 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"order/internal/breaker"
+	"order/internal/entity"
+	"order/internal/report"
+	"strings"
+	"time"
+)
+
+// defaultReportDays — период отчёта, если from не задан: последние 30 дней по to включительно
+const defaultReportDays = 30
+
+// Revenue отдаёт выручку в валюте currency за дни с from по to включительно:
+// GET /reports/revenue?currency=USD&from=2024-03-01&to=2024-03-31&group=day|month|total
+func (h *Handler) Revenue(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := report.Query{
+		Currency: strings.ToUpper(query.Get("currency")),
+		Group:    report.Group(query.Get("group")),
+		To:       entity.Day(time.Now()),
+	}
+	if q.Currency == "" {
+		http.Error(w, "currency is required", http.StatusBadRequest)
+		return
+	}
+	for name, value := range map[string]*time.Time{"from": &q.From, "to": &q.To} {
+		raw := query.Get(name)
+		if raw == "" {
+			continue
+		}
+		t, err := entity.ParseTime(raw)
+		if err != nil {
+			http.Error(w, name+" must be a date (YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		*value = t
+	}
+	if q.From.IsZero() {
+		q.From = entity.Day(q.To).AddDate(0, 0, 1-defaultReportDays)
+	}
+
+	revenue, err := h.service.Revenue(r.Context(), q)
+	if err != nil {
+		switch {
+		case errors.Is(err, report.ErrInvalidQuery):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, breaker.ErrOpen):
+			http.Error(w, "Storage is temporarily unavailable", http.StatusServiceUnavailable)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(revenue); err != nil {
+		log.Printf("Failed to encode revenue report: %v", err)
+		return
+	}
+	log.Printf("Served revenue report in %s from %s to %s", revenue.Currency, revenue.From, revenue.To)
+}
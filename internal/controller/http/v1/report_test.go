@@ -0,0 +1,81 @@
+package v1
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "order/internal/breaker"
+    "order/internal/entity"
+    "order/internal/report"
+    "order/internal/service/mock"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "go.uber.org/mock/gomock"
+)
+
+func TestHandler_Revenue(t *testing.T) {
+    serve := func(handler *Handler, target string) *httptest.ResponseRecorder {
+        w := httptest.NewRecorder()
+        handler.Revenue(w, httptest.NewRequest(http.MethodGet, target, nil))
+        return w
+    }
+
+    t.Run("Report", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        mockService := mock.NewMockService(ctrl)
+        q := report.Query{
+            Currency: "USD",
+            From:     time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+            To:       time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC),
+            Group:    report.GroupMonth,
+        }
+        revenue := report.Revenue{
+            Currency: "USD", From: "2024-03-01", To: "2024-03-31", Group: report.GroupMonth,
+            Orders: 2, Total: entity.NewMoney(154),
+            Periods: []report.Period{{Start: "2024-03-01", Orders: 2, Total: entity.NewMoney(154), Currencies: []report.CurrencyTotal{
+                {Currency: "EUR", Orders: 1, Amount: entity.NewMoney(50), Converted: entity.NewMoney(54)},
+                {Currency: "USD", Orders: 1, Amount: entity.NewMoney(100), Converted: entity.NewMoney(100)},
+            }}},
+        }
+        mockService.EXPECT().Revenue(gomock.Any(), q).Return(revenue, nil)
+
+        w := serve(NewHandler(mockService), "/reports/revenue?currency=usd&from=2024-03-01&to=2024-03-31&group=month")
+
+        assert.Equal(t, http.StatusOK, w.Code)
+        var result report.Revenue
+        assert.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+        assert.Equal(t, revenue, result)
+    })
+
+    t.Run("Last 30 days by default", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        mockService := mock.NewMockService(ctrl)
+        today := entity.Day(time.Now())
+        mockService.EXPECT().Revenue(gomock.Any(), report.Query{Currency: "EUR", From: today.AddDate(0, 0, -29), To: today}).
+            Return(report.Revenue{}, nil)
+
+        assert.Equal(t, http.StatusOK, serve(NewHandler(mockService), "/reports/revenue?currency=EUR").Code)
+    })
+
+    t.Run("Errors", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        mockService := mock.NewMockService(ctrl)
+        mockService.EXPECT().Revenue(gomock.Any(), gomock.Any()).Return(report.Revenue{}, fmt.Errorf("%w: unknown group", report.ErrInvalidQuery))
+        mockService.EXPECT().Revenue(gomock.Any(), gomock.Any()).Return(report.Revenue{}, breaker.ErrOpen)
+        handler := NewHandler(mockService)
+
+        assert.Equal(t, http.StatusBadRequest, serve(handler, "/reports/revenue").Code)
+        assert.Equal(t, http.StatusBadRequest, serve(handler, "/reports/revenue?currency=USD&from=March").Code)
+        assert.Equal(t, http.StatusBadRequest, serve(handler, "/reports/revenue?currency=USD&group=week").Code)
+        assert.Equal(t, http.StatusServiceUnavailable, serve(handler, "/reports/revenue?currency=USD").Code)
+    })
+}
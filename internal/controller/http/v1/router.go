@@ -1,15 +1,40 @@
 package v1
 
 import (
+	"expvar"
 	"net/http"
 	"order/config"
 
 	"github.com/gorilla/mux"
 )
 
-func NewRouter(handler *Handler, cfg *config.Config) *mux.Router {
+func NewRouter(handler *Handler, admin *AdminHandler, consumer *ConsumerAdminHandler, status *StatusHandler, cfg *config.Config) *mux.Router {
 	r := mux.NewRouter()
-	r.HandleFunc("/order/{order_uid}", handler.GetOrder).Methods("GET")
+	// handler равен nil у процесса без REST API (роль ingest), admin — без Kafka
+	if handler != nil {
+		r.HandleFunc("/order/{order_uid}", handler.GetOrder).Methods("GET")
+		r.HandleFunc("/orders", handler.FindOrders).Methods("GET")
+		r.HandleFunc("/orders/search", handler.SearchOrders).Methods("GET")
+		r.HandleFunc("/orders/export", handler.ExportOrders).Methods("GET")
+		r.HandleFunc("/reports/revenue", handler.Revenue).Methods("GET")
+	}
+
+	// Служебные эндпоинты доступны только с токеном ADMIN_TOKEN
+	a := r.PathPrefix("/admin").Subrouter()
+	a.Use(AdminAuth(cfg.App.AdminToken))
+	if admin != nil {
+		a.HandleFunc("/replay", admin.Replay).Methods("POST")
+	}
+	a.HandleFunc("/consumer/pause", consumer.Pause).Methods("POST")
+	a.HandleFunc("/consumer/resume", consumer.Resume).Methods("POST")
+	a.HandleFunc("/consumer/partitions", consumer.Partitions).Methods("GET")
+	a.HandleFunc("/consumer/errors", consumer.Errors).Methods("GET")
+	a.HandleFunc("/cache/flush", consumer.FlushCache).Methods("POST")
+	a.HandleFunc("/cache/rewarm", consumer.RewarmCache).Methods("POST")
+	a.HandleFunc("/orders/incomplete", consumer.IncompleteOrders).Methods("GET")
+
+	r.HandleFunc("/status", status.Status).Methods("GET")
+	r.Handle("/debug/vars", expvar.Handler()).Methods("GET")
 	return r
 }
 
@@ -0,0 +1,50 @@
+package v1
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "order/config"
+    "order/internal/breaker"
+    "order/internal/service/mock"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "go.uber.org/mock/gomock"
+)
+
+func TestNewRouter(t *testing.T) {
+    cfg := &config.Config{App: config.App{AdminToken: "secret"}}
+    status := NewStatusHandler(breaker.New(breaker.Config{Name: "database"}), nil)
+
+    serve := func(router http.Handler, method, path string) int {
+        req := httptest.NewRequest(method, path, nil)
+        req.Header.Set("Authorization", "Bearer secret")
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        return w.Code
+    }
+
+    t.Run("Ingest role has no order API", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        router := NewRouter(nil, nil, NewConsumerAdminHandler(nil, mock.NewMockService(ctrl)), status, cfg)
+
+        assert.Equal(t, http.StatusNotFound, serve(router, http.MethodGet, "/order/uid-1"))
+        assert.Equal(t, http.StatusNotFound, serve(router, http.MethodPost, "/admin/replay"))
+        assert.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/status"))
+    })
+
+    t.Run("API role without Kafka", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        svc := mock.NewMockService(ctrl)
+        router := NewRouter(NewHandler(svc), nil, NewConsumerAdminHandler(nil, svc), status, cfg)
+
+        svc.EXPECT().FlushCache().Return(0)
+        svc.EXPECT().CacheSize().Return(0)
+        assert.Equal(t, http.StatusOK, serve(router, http.MethodPost, "/admin/cache/flush"))
+        assert.Equal(t, http.StatusNotFound, serve(router, http.MethodGet, "/admin/consumer/partitions"))
+    })
+}
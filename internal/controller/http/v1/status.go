@@ -0,0 +1,61 @@
+package v1
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"order/internal/breaker"
+	"order/internal/controller/kafka"
+	"order/internal/source"
+)
+
+const (
+	StatusOK       = "ok"
+	StatusDegraded = "degraded" // база недоступна, приём заказов приостановлен
+)
+
+// Status — ответ /status
+type Status struct {
+	Status    string          `json:"status"`
+	Database  breaker.Stats   `json:"database"`
+	Ingestion *IngestionState `json:"ingestion,omitempty"`
+}
+
+type IngestionState struct {
+	Paused     bool               `json:"paused"`
+	Assignment []source.Partition `json:"assignment"`
+}
+
+// StatusHandler показывает состояние предохранителя базы и чтения из Kafka
+type StatusHandler struct {
+	breaker  *breaker.Breaker
+	consumer kafka.KafkaController // nil, если Kafka не используется
+}
+
+func NewStatusHandler(breaker *breaker.Breaker, consumer kafka.KafkaController) *StatusHandler {
+	return &StatusHandler{breaker: breaker, consumer: consumer}
+}
+
+// Snapshot собирает текущее состояние; используется и в /status, и в expvar
+func (h *StatusHandler) Snapshot() Status {
+	status := Status{Status: StatusOK, Database: h.breaker.Stats()}
+	if status.Database.State != breaker.StateClosed {
+		status.Status = StatusDegraded
+	}
+	if h.consumer != nil {
+		status.Ingestion = &IngestionState{
+			Paused:     h.consumer.Paused(),
+			Assignment: h.consumer.Assignment(),
+		}
+	}
+	return status
+}
+
+// Status всегда отвечает 200: при недоступной базе заказы из кэша продолжают отдаваться,
+// поэтому экземпляр не должен выводиться из балансировки
+func (h *StatusHandler) Status(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.Snapshot()); err != nil {
+		log.Printf("Failed to encode status: %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package v1
+
+import (
+    "encoding/json"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "order/internal/breaker"
+    "order/internal/controller/kafka/mock"
+    "order/internal/source"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "go.uber.org/mock/gomock"
+)
+
+func TestStatusHandler_Status(t *testing.T) {
+    t.Run("Healthy", func(t *testing.T) {
+        ctrl := gomock.NewController(t)
+        defer ctrl.Finish()
+
+        consumer := mock.NewMockKafkaController(ctrl)
+        partitions := []source.Partition{{Topic: "orders", Partition: 0}}
+        consumer.EXPECT().Paused().Return(false)
+        consumer.EXPECT().Assignment().Return(partitions)
+        handler := NewStatusHandler(breaker.New(breaker.Config{Name: "database"}), consumer)
+
+        w := httptest.NewRecorder()
+        handler.Status(w, httptest.NewRequest(http.MethodGet, "/status", nil))
+
+        assert.Equal(t, http.StatusOK, w.Code)
+        var status Status
+        require.NoError(t, json.NewDecoder(w.Body).Decode(&status))
+        assert.Equal(t, StatusOK, status.Status)
+        assert.Equal(t, breaker.StateClosed, status.Database.State)
+        assert.Equal(t, &IngestionState{Paused: false, Assignment: partitions}, status.Ingestion)
+    })
+
+    t.Run("Database unavailable", func(t *testing.T) {
+        b := breaker.New(breaker.Config{Name: "database", FailureThreshold: 1, OpenTimeout: time.Minute})
+        b.Do(func() error { return errors.New("connection refused") })
+        handler := NewStatusHandler(b, nil)
+
+        w := httptest.NewRecorder()
+        handler.Status(w, httptest.NewRequest(http.MethodGet, "/status", nil))
+
+        assert.Equal(t, http.StatusOK, w.Code)
+        var status Status
+        require.NoError(t, json.NewDecoder(w.Body).Decode(&status))
+        assert.Equal(t, StatusDegraded, status.Status)
+        assert.Equal(t, breaker.StateOpen, status.Database.State)
+        assert.Equal(t, "connection refused", status.Database.LastError)
+        assert.Nil(t, status.Ingestion)
+    })
+}
@@ -0,0 +1,159 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"order/internal/source"
+)
+
+// ErrNotAssigned возвращается при попытке управлять партицией, не назначенной консюмеру
+var ErrNotAssigned = errors.New("partition is not assigned")
+
+// PartitionStatus — назначенная партиция со смещениями группы и признаком паузы
+type PartitionStatus struct {
+	source.Offsets
+	Lag int64 `json:"lag"`
+	// Held — партиция приостановлена оператором; Paused — чтение фактически остановлено
+	// (оператором или из-за недоступности хранилища)
+	Held   bool `json:"held"`
+	Paused bool `json:"paused"`
+}
+
+// Pause приостанавливает чтение партиций по запросу оператора; пустой список — все назначенные,
+// включая те, что будут назначены позже. Пауза сохраняется до Resume
+func (c *kafkaController) Pause(partitions []source.Partition) error {
+	c.holdMu.Lock()
+	defer c.holdMu.Unlock()
+
+	if len(partitions) == 0 {
+		c.holdAll = true
+		partitions = c.tracker.Partitions()
+	} else {
+		if err := c.checkAssigned(partitions); err != nil {
+			return err
+		}
+		for _, p := range partitions {
+			c.held[p] = true
+		}
+	}
+	log.Printf("Ingestion paused by operator: %v", partitions)
+
+	if c.paused.Load() {
+		return nil // Уже на паузе из-за хранилища; Resume после восстановления учтёт паузу оператора
+	}
+	return c.source.Pause(partitions)
+}
+
+// Resume снимает паузу оператора; пустой список — со всех партиций
+func (c *kafkaController) Resume(partitions []source.Partition) error {
+	c.holdMu.Lock()
+	defer c.holdMu.Unlock()
+
+	assigned := c.tracker.Partitions()
+	if len(partitions) == 0 {
+		c.holdAll = false
+		clear(c.held)
+		partitions = assigned
+	} else {
+		if err := c.checkAssigned(partitions); err != nil {
+			return err
+		}
+		if c.holdAll {
+			// Глобальная пауза превращается в паузу остальных партиций
+			c.holdAll = false
+			for _, p := range assigned {
+				c.held[p] = true
+			}
+		}
+		for _, p := range partitions {
+			delete(c.held, p)
+		}
+	}
+	log.Printf("Ingestion resumed by operator: %v", partitions)
+
+	if c.paused.Load() {
+		return nil // Чтение возобновится после восстановления хранилища
+	}
+	return c.source.Resume(partitions)
+}
+
+// Partitions возвращает назначенные партиции с закоммиченными смещениями и отставанием
+func (c *kafkaController) Partitions(ctx context.Context) ([]PartitionStatus, error) {
+	offsets, err := c.source.Offsets(ctx, c.tracker.Partitions())
+	if err != nil {
+		return nil, err
+	}
+
+	c.holdMu.Lock()
+	defer c.holdMu.Unlock()
+	storageDown := c.paused.Load()
+	statuses := make([]PartitionStatus, 0, len(offsets))
+	for _, o := range offsets {
+		held := c.isHeld(o.Partition)
+		statuses = append(statuses, PartitionStatus{
+			Offsets: o,
+			Lag:     o.Lag(),
+			Held:    held,
+			Paused:  held || storageDown,
+		})
+	}
+	return statuses, nil
+}
+
+func (c *kafkaController) Errors(n int) []ProcessingError {
+	return c.errors.last(n)
+}
+
+// applyPauses после ребалансировки ставит на паузу новые партиции, если чтение
+// остановлено из-за хранилища или оператором
+func (c *kafkaController) applyPauses(partitions []source.Partition) {
+	c.holdMu.Lock()
+	defer c.holdMu.Unlock()
+
+	stopped := partitions
+	if !c.paused.Load() {
+		stopped = nil
+		for _, p := range partitions {
+			if c.isHeld(p) {
+				stopped = append(stopped, p)
+			}
+		}
+	}
+	if len(stopped) == 0 {
+		return
+	}
+	if err := c.source.Pause(stopped); err != nil {
+		log.Printf("Failed to pause partitions %v: %v", stopped, err)
+	}
+}
+
+// running возвращает партиции без паузы оператора; вызывается под holdMu
+func (c *kafkaController) running(partitions []source.Partition) []source.Partition {
+	var result []source.Partition
+	for _, p := range partitions {
+		if !c.isHeld(p) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// isHeld вызывается под holdMu
+func (c *kafkaController) isHeld(p source.Partition) bool {
+	return c.holdAll || c.held[p]
+}
+
+func (c *kafkaController) checkAssigned(partitions []source.Partition) error {
+	assigned := make(map[source.Partition]bool)
+	for _, p := range c.tracker.Partitions() {
+		assigned[p] = true
+	}
+	for _, p := range partitions {
+		if !assigned[p] {
+			return fmt.Errorf("%w: %s", ErrNotAssigned, p)
+		}
+	}
+	return nil
+}
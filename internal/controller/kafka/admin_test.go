@@ -0,0 +1,140 @@
+package kafka
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "order/internal/codec"
+    "order/internal/entity"
+    "order/internal/service"
+    "order/internal/source"
+    "order/internal/storage/memory"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+// produceTo записывает заказ с ключом, который попадает в партицию partition
+func produceTo(t *testing.T, broker *source.MemoryBroker, partition int32, n int) *source.Message {
+    for i := 0; ; i++ {
+        uid := fmt.Sprintf("uid-%d-%d", n, i)
+        if source.PartitionFor([]byte(uid), 2) != partition {
+            continue
+        }
+        value, err := json.Marshal(entity.Order{
+            OrderUID: uid,
+            Delivery: entity.Delivery{Name: "John", Phone: "1234567890"},
+            Payment:  entity.Payment{Amount: entity.NewMoney(1000)},
+            Items:    []entity.Item{{ChrtID: 1, Price: entity.NewMoney(500)}},
+        })
+        require.NoError(t, err)
+        msg, err := broker.Produce("orders", []byte(uid), value, nil)
+        require.NoError(t, err)
+        return msg
+    }
+}
+
+func TestKafkaController_OperatorPause(t *testing.T) {
+    broker := source.NewMemoryBroker()
+    broker.CreateTopic("orders", 2)
+    p0 := source.Partition{Topic: "orders", Partition: 0}
+    p1 := source.Partition{Topic: "orders", Partition: 1}
+
+    decoder, err := codec.NewDecoder(codec.FormatJSON, nil, nil)
+    require.NoError(t, err)
+    controller, err := NewController(broker.NewSource("order-group"), []string{"orders"}, decoder, nil, service.NewService(memory.NewStore()), false, nil)
+    require.NoError(t, err)
+
+    // Пауза до запуска: ни одно сообщение не обрабатывается
+    require.NoError(t, controller.Pause(nil))
+    produceTo(t, broker, 0, 1)
+    produceTo(t, broker, 1, 2)
+
+    ctx, cancel := context.WithCancel(context.Background())
+    done := make(chan error, 1)
+    go func() { done <- controller.Consume(ctx) }()
+    defer func() {
+        cancel()
+        require.NoError(t, <-done)
+    }()
+
+    time.Sleep(100 * time.Millisecond)
+    statuses, err := controller.Partitions(context.Background())
+    require.NoError(t, err)
+    require.Len(t, statuses, 2)
+    for _, status := range statuses {
+        assert.Equal(t, int64(-1), status.Committed)
+        assert.Equal(t, int64(1), status.Lag)
+        assert.True(t, status.Held)
+        assert.True(t, status.Paused)
+    }
+
+    // Снятие паузы с одной партиции: вторая остаётся на паузе
+    require.NoError(t, controller.Resume([]source.Partition{p0}))
+    require.Eventually(t, func() bool {
+        return broker.Committed("order-group", p0) == 1
+    }, 5*time.Second, 10*time.Millisecond)
+    time.Sleep(100 * time.Millisecond)
+    assert.Equal(t, int64(0), broker.Committed("order-group", p1))
+
+    statuses, err = controller.Partitions(context.Background())
+    require.NoError(t, err)
+    assert.Equal(t, PartitionStatus{Offsets: source.Offsets{Partition: p0, Committed: 1, High: 1}}, statuses[0])
+    assert.True(t, statuses[1].Held)
+    assert.Equal(t, int64(1), statuses[1].Lag)
+
+    require.NoError(t, controller.Resume(nil))
+    require.Eventually(t, func() bool {
+        return broker.Committed("order-group", p1) == 1
+    }, 5*time.Second, 10*time.Millisecond)
+
+    err = controller.Pause([]source.Partition{{Topic: "orders", Partition: 7}})
+    assert.ErrorIs(t, err, ErrNotAssigned)
+}
+
+func TestKafkaController_Errors(t *testing.T) {
+    broker := source.NewMemoryBroker()
+    broker.CreateTopic("orders", 1)
+    _, err := broker.Produce("orders", nil, []byte("not json"), nil)
+    require.NoError(t, err)
+    _, err = broker.Produce("orders", nil, []byte("{"), nil)
+    require.NoError(t, err)
+    last := produceTo(t, broker, 0, 1)
+
+    decoder, err := codec.NewDecoder(codec.FormatJSON, nil, nil)
+    require.NoError(t, err)
+    c, err := NewController(broker.NewSource("order-group"), []string{"orders"}, decoder, nil, service.NewService(memory.NewStore()), false, nil)
+    require.NoError(t, err)
+
+    ctx, cancel := context.WithCancel(context.Background())
+    done := make(chan error, 1)
+    go func() { done <- c.Consume(ctx) }()
+    require.Eventually(t, func() bool {
+        return broker.Committed("order-group", last.TopicPartition()) == last.Offset+1
+    }, 5*time.Second, 10*time.Millisecond)
+    cancel()
+    require.NoError(t, <-done)
+
+    errs := c.Errors(10)
+    require.Len(t, errs, 2)
+    assert.Equal(t, int64(1), errs[0].Offset) // Новые первыми
+    assert.Equal(t, int64(0), errs[1].Offset)
+    assert.Equal(t, StageDecode, errs[0].Stage)
+    assert.Len(t, c.Errors(1), 1)
+}
+
+func TestErrorLog(t *testing.T) {
+    log := newErrorLog(3)
+    for i := range 5 {
+        log.add(&source.Message{Topic: "orders", Offset: int64(i)}, "", StageProcess, errors.New("failed"))
+    }
+
+    var offsets []int64
+    for _, entry := range log.last(10) {
+        offsets = append(offsets, entry.Offset)
+    }
+    assert.Equal(t, []int64{4, 3, 2}, offsets)
+}
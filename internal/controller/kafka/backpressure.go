@@ -0,0 +1,83 @@
+package kafka
+
+import (
+	"context"
+	"log"
+	"order/internal/source"
+	"time"
+)
+
+// probeInterval — как часто при недоступном хранилище проверяется, не пора ли повторить
+const probeInterval = 200 * time.Millisecond
+
+// hold откладывает сообщение до восстановления хранилища и приостанавливает
+// чтение всех назначенных партиций, чтобы не перебирать сообщения впустую
+func (c *kafkaController) hold(msg *source.Message) {
+	c.pending = append(c.pending, msg)
+	if c.paused.Load() {
+		return
+	}
+	c.holdMu.Lock()
+	partitions := c.tracker.Partitions()
+	if err := c.source.Pause(partitions); err != nil {
+		log.Printf("Failed to pause partitions %v: %v", partitions, err)
+	}
+	c.paused.Store(true)
+	c.holdMu.Unlock()
+	log.Printf("Storage is unavailable, ingestion paused at %s", msg)
+}
+
+// retryPending повторяет отложенные сообщения, когда предохранитель пропускает пробу.
+// До этого консюмер продолжает опрос (партиции на паузе), иначе группа исключит его
+// по max.poll.interval.ms
+func (c *kafkaController) retryPending(fetch, work context.Context) {
+	if !c.health.Ready() {
+		msg, err := c.source.Fetch(fetch, probeInterval)
+		if err == nil && msg != nil {
+			// Сообщение было прочитано до паузы — оно тоже ждёт своей очереди
+			c.pending = append(c.pending, msg)
+		}
+		return
+	}
+
+	for len(c.pending) > 0 {
+		if !c.handle(work, c.pending[0]) {
+			return
+		}
+		c.pending = c.pending[1:]
+	}
+
+	// Партиции, приостановленные оператором, остаются на паузе
+	c.holdMu.Lock()
+	if partitions := c.running(c.tracker.Partitions()); len(partitions) > 0 {
+		if err := c.source.Resume(partitions); err != nil {
+			log.Printf("Failed to resume partitions %v: %v", partitions, err)
+		}
+	}
+	c.paused.Store(false)
+	c.holdMu.Unlock()
+	log.Printf("Storage recovered, ingestion resumed")
+}
+
+// reconcilePending после ребалансировки отбрасывает отложенные сообщения отозванных
+// партиций: их дочитает новый владелец
+func (c *kafkaController) reconcilePending(partitions []source.Partition) {
+	if len(c.pending) == 0 {
+		return
+	}
+	assigned := make(map[source.Partition]bool, len(partitions))
+	for _, p := range partitions {
+		assigned[p] = true
+	}
+	kept := c.pending[:0]
+	for _, msg := range c.pending {
+		if assigned[msg.TopicPartition()] {
+			kept = append(kept, msg)
+		}
+	}
+	c.pending = kept
+}
+
+func (c *kafkaController) Paused() bool {
+	return c.paused.Load()
+}
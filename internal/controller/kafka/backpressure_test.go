@@ -0,0 +1,92 @@
+package kafka
+
+import (
+    "context"
+    "errors"
+    "order/internal/breaker"
+    "order/internal/codec"
+    "order/internal/entity"
+    "order/internal/service"
+    "order/internal/source"
+    "order/internal/storage"
+    "order/internal/storage/memory"
+    "sync/atomic"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+// outageStore имитирует недоступную базу, пока установлен down
+type outageStore struct {
+    storage.Store
+    down  atomic.Bool
+    calls atomic.Int64
+}
+
+func (s *outageStore) SaveOrder(ctx context.Context, order entity.Order) error {
+    s.calls.Add(1)
+    if s.down.Load() {
+        return errors.New("dial tcp: connection refused")
+    }
+    return s.Store.SaveOrder(ctx, order)
+}
+
+func TestKafkaController_PausesWhileStorageIsDown(t *testing.T) {
+    broker := source.NewMemoryBroker()
+    broker.CreateTopic("orders", 2)
+    produceOrder(t, broker, "uid-1", nil)
+    produceOrder(t, broker, "uid-2", nil)
+    last, err := broker.Produce("orders", []byte("key"), []byte(`{"order_uid":"uid-3"}`), nil)
+    require.NoError(t, err)
+
+    store := &outageStore{Store: memory.NewStore()}
+    store.down.Store(true)
+    dbBreaker := breaker.New(breaker.Config{
+        Name:             "database",
+        FailureThreshold: 2,
+        OpenTimeout:      20 * time.Millisecond,
+        MaxOpenTimeout:   50 * time.Millisecond,
+        IsFailure:        storage.IsUnavailable,
+    })
+    svc := service.NewService(storage.NewBreakerStore(store, dbBreaker))
+
+    decoder, err := codec.NewDecoder(codec.FormatJSON, nil, nil)
+    require.NoError(t, err)
+    controller, err := NewController(broker.NewSource("order-group"), []string{"orders"}, decoder, nil, svc, false, dbBreaker)
+    require.NoError(t, err)
+
+    ctx, cancel := context.WithCancel(context.Background())
+    done := make(chan error, 1)
+    go func() { done <- controller.Consume(ctx) }()
+
+    // База недоступна: чтение приостановлено, смещение не сдвигается,
+    // а к базе обращаются только пробы предохранителя
+    require.Eventually(t, func() bool {
+        return controller.Paused() && dbBreaker.Stats().State != breaker.StateClosed
+    }, 5*time.Second, 10*time.Millisecond)
+    time.Sleep(200 * time.Millisecond)
+    assert.Equal(t, int64(0), broker.Committed("order-group", last.TopicPartition()))
+    assert.Less(t, store.calls.Load(), int64(20))
+
+    // База вернулась — первое сообщение не потеряно, чтение возобновлено
+    store.down.Store(false)
+    require.Eventually(t, func() bool {
+        return broker.Committed("order-group", last.TopicPartition()) == last.Offset+1
+    }, 5*time.Second, 10*time.Millisecond)
+    assert.False(t, controller.Paused())
+    assert.Equal(t, breaker.StateClosed, dbBreaker.Stats().State)
+
+    cancel()
+    require.NoError(t, <-done)
+    require.NoError(t, controller.Close())
+
+    orders, err := storage.Collect(store.Orders(context.Background(), storage.Filter{}))
+    require.NoError(t, err)
+    var uids []string
+    for _, order := range orders {
+        uids = append(uids, order.OrderUID)
+    }
+    assert.Equal(t, []string{"uid-1", "uid-2", "uid-3"}, uids)
+}
@@ -0,0 +1,95 @@
+package kafka
+
+import (
+	"fmt"
+	"order/config"
+	"strings"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+var (
+	securityProtocols = map[string]bool{"PLAINTEXT": true, "SSL": true, "SASL_PLAINTEXT": true, "SASL_SSL": true}
+	saslMechanisms    = map[string]bool{"PLAIN": true, "SCRAM-SHA-256": true, "SCRAM-SHA-512": true, "OAUTHBEARER": true}
+)
+
+// NewConfigMap собирает общие для консюмеров и продюсеров настройки librdkafka:
+// брокеры, TLS, SASL и произвольные свойства из KAFKA_PROPERTIES
+func NewConfigMap(cfg config.Kafka, brokers string) (kafka.ConfigMap, error) {
+	cm := kafka.ConfigMap{"bootstrap.servers": brokers}
+	sec := cfg.Security
+
+	protocol := strings.ToUpper(sec.Protocol)
+	if protocol == "" {
+		protocol = "PLAINTEXT"
+	}
+	if !securityProtocols[protocol] {
+		return nil, fmt.Errorf("unsupported kafka security protocol: %s", sec.Protocol)
+	}
+	cm["security.protocol"] = protocol
+
+	if protocol == "SSL" || protocol == "SASL_SSL" {
+		setIfNotEmpty(cm, "ssl.ca.location", sec.CALocation)
+		setIfNotEmpty(cm, "ssl.certificate.location", sec.CertLocation)
+		setIfNotEmpty(cm, "ssl.key.location", sec.KeyLocation)
+		setIfNotEmpty(cm, "ssl.key.password", sec.KeyPassword)
+		if (sec.CertLocation == "") != (sec.KeyLocation == "") {
+			return nil, fmt.Errorf("client certificate and key must be set together")
+		}
+	}
+
+	if strings.HasPrefix(protocol, "SASL_") {
+		mechanism := strings.ToUpper(sec.SASLMechanism)
+		if !saslMechanisms[mechanism] {
+			return nil, fmt.Errorf("unsupported kafka SASL mechanism: %q", sec.SASLMechanism)
+		}
+		cm["sasl.mechanism"] = mechanism
+
+		switch mechanism {
+		case "OAUTHBEARER":
+			// Без token endpoint librdkafka использует небезопасные JWT — годится только для разработки
+			if sec.OAuthTokenURL != "" {
+				if sec.OAuthClientID == "" || sec.OAuthClientSecret == "" {
+					return nil, fmt.Errorf("OAUTHBEARER requires client id and secret")
+				}
+				cm["sasl.oauthbearer.method"] = "oidc"
+				cm["sasl.oauthbearer.token.endpoint.url"] = sec.OAuthTokenURL
+				cm["sasl.oauthbearer.client.id"] = sec.OAuthClientID
+				cm["sasl.oauthbearer.client.secret"] = sec.OAuthClientSecret
+				setIfNotEmpty(cm, "sasl.oauthbearer.scope", sec.OAuthScope)
+			}
+		default:
+			if sec.SASLUsername == "" || sec.SASLPassword == "" {
+				return nil, fmt.Errorf("SASL mechanism %s requires username and password", mechanism)
+			}
+			cm["sasl.username"] = sec.SASLUsername
+			cm["sasl.password"] = sec.SASLPassword
+		}
+	} else if sec.SASLMechanism != "" {
+		return nil, fmt.Errorf("SASL mechanism %s requires SASL_PLAINTEXT or SASL_SSL protocol", sec.SASLMechanism)
+	}
+
+	for key, value := range cfg.Properties {
+		cm[key] = value
+	}
+	return cm, nil
+}
+
+// withProperties дополняет копию базовых настроек свойствами конкретного клиента.
+// Базовые настройки применяются последними, чтобы KAFKA_PROPERTIES могли переопределить и их
+func withProperties(base kafka.ConfigMap, props kafka.ConfigMap) *kafka.ConfigMap {
+	cm := make(kafka.ConfigMap, len(base)+len(props))
+	for key, value := range props {
+		cm[key] = value
+	}
+	for key, value := range base {
+		cm[key] = value
+	}
+	return &cm
+}
+
+func setIfNotEmpty(cm kafka.ConfigMap, key, value string) {
+	if value != "" {
+		cm[key] = value
+	}
+}
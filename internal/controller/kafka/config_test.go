@@ -0,0 +1,83 @@
+package kafka
+
+import (
+    "order/config"
+    "testing"
+
+    "github.com/confluentinc/confluent-kafka-go/v2/kafka"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func TestNewConfigMap(t *testing.T) {
+    t.Run("Plaintext by default", func(t *testing.T) {
+        cm, err := NewConfigMap(config.Kafka{}, "localhost:9092")
+        require.NoError(t, err)
+        assert.Equal(t, kafka.ConfigMap{
+            "bootstrap.servers": "localhost:9092",
+            "security.protocol": "PLAINTEXT",
+        }, cm)
+    })
+
+    t.Run("SASL_SSL with SCRAM and client certificate", func(t *testing.T) {
+        cfg := config.Kafka{Security: config.KafkaSecurity{
+            Protocol:      "sasl_ssl",
+            SASLMechanism: "SCRAM-SHA-512",
+            SASLUsername:  "svc",
+            SASLPassword:  "secret",
+            CALocation:    "/etc/kafka/ca.pem",
+            CertLocation:  "/etc/kafka/client.pem",
+            KeyLocation:   "/etc/kafka/client.key",
+        }}
+        cm, err := NewConfigMap(cfg, "b1:9093")
+        require.NoError(t, err)
+        assert.Equal(t, "SASL_SSL", cm["security.protocol"])
+        assert.Equal(t, "SCRAM-SHA-512", cm["sasl.mechanism"])
+        assert.Equal(t, "svc", cm["sasl.username"])
+        assert.Equal(t, "/etc/kafka/ca.pem", cm["ssl.ca.location"])
+        assert.Equal(t, "/etc/kafka/client.key", cm["ssl.key.location"])
+    })
+
+    t.Run("OAUTHBEARER via OIDC", func(t *testing.T) {
+        cfg := config.Kafka{Security: config.KafkaSecurity{
+            Protocol:          "SASL_SSL",
+            SASLMechanism:     "OAUTHBEARER",
+            OAuthTokenURL:     "https://idp/token",
+            OAuthClientID:     "order-service",
+            OAuthClientSecret: "secret",
+        }}
+        cm, err := NewConfigMap(cfg, "b1:9093")
+        require.NoError(t, err)
+        assert.Equal(t, "oidc", cm["sasl.oauthbearer.method"])
+        assert.Equal(t, "https://idp/token", cm["sasl.oauthbearer.token.endpoint.url"])
+        assert.NotContains(t, cm, "sasl.username")
+    })
+
+    t.Run("Properties override everything", func(t *testing.T) {
+        cfg := config.Kafka{Properties: map[string]string{
+            "security.protocol": "SSL",
+            "auto.offset.reset": "latest",
+        }}
+        cm, err := NewConfigMap(cfg, "b1:9093")
+        require.NoError(t, err)
+
+        consumer := withProperties(cm, kafka.ConfigMap{"auto.offset.reset": "earliest", "group.id": "g"})
+        assert.Equal(t, "SSL", (*consumer)["security.protocol"])
+        assert.Equal(t, "latest", (*consumer)["auto.offset.reset"])
+        assert.Equal(t, "g", (*consumer)["group.id"])
+    })
+
+    t.Run("Invalid combinations", func(t *testing.T) {
+        for name, sec := range map[string]config.KafkaSecurity{
+            "unknown protocol":       {Protocol: "TLS"},
+            "mechanism without sasl": {Protocol: "SSL", SASLMechanism: "PLAIN"},
+            "scram without password": {Protocol: "SASL_SSL", SASLMechanism: "SCRAM-SHA-256", SASLUsername: "svc"},
+            "unknown mechanism":      {Protocol: "SASL_PLAINTEXT", SASLMechanism: "KERBEROS"},
+            "cert without key":       {Protocol: "SSL", CertLocation: "/etc/kafka/client.pem"},
+            "oidc without client":    {Protocol: "SASL_SSL", SASLMechanism: "OAUTHBEARER", OAuthTokenURL: "https://idp/token"},
+        } {
+            _, err := NewConfigMap(config.Kafka{Security: sec}, "b1:9093")
+            assert.Error(t, err, name)
+        }
+    })
+}
@@ -2,87 +2,233 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"log"
-	"order/internal/entity"
+	"order/internal/codec"
+	"order/internal/lifecycle"
 	"order/internal/service"
+	"order/internal/source"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
 )
 
 type kafkaController struct {
-	consumer *kafka.Consumer
-	service  service.Service
+	source     source.MessageSource
+	decoder    codec.Decoder
+	quarantine Quarantine
+	service    service.Service
+
+	drainer *lifecycle.Drainer
+	tracker *partitionTracker
+	// partitionedCache — кэш держит только заказы назначенных партиций
+	partitionedCache bool
+	counts           map[string]int            // число партиций по топикам
+	applied          map[source.Partition]bool // партиции, под которые перестроен кэш
+
+	// health — состояние хранилища (предохранитель); nil — ошибки сохранения не повторяются
+	health  Health
+	pending []*source.Message // сообщения, ждущие восстановления хранилища
+	paused  atomic.Bool       // чтение приостановлено из-за хранилища; меняется под holdMu
+
+	// Пауза, заданная оператором через админку
+	holdMu  sync.Mutex
+	holdAll bool
+	held    map[source.Partition]bool
+
+	errors *errorLog
 }
 
-func NewKafkaController(brokers, groupID, topic string, service service.Service) (KafkaController, error) {
-	consumer, err := kafka.NewConsumer(&kafka.ConfigMap{
-		"bootstrap.servers":  brokers,
-		"group.id":           groupID,
-		"auto.offset.reset":  "earliest", // Начать с самого начала топика
-		"enable.auto.commit": false,      // Ручное подтверждение смещений
-	})
+// NewKafkaController создаёт консюмер группы поверх общих настроек base (см. NewConfigMap).
+// Топики, начинающиеся с "^", librdkafka трактует как регулярные выражения.
+// С partitionedCache кэш сервиса держит только заказы назначенных партиций.
+// С health при сбое хранилища чтение партиций приостанавливается до его восстановления
+func NewKafkaController(base kafka.ConfigMap, groupID string, topics []string, decoder codec.Decoder, quarantine Quarantine, service service.Service, partitionedCache bool, health Health) (KafkaController, error) {
+	src, err := NewSource(base, groupID)
 	if err != nil {
 		return nil, err
 	}
+	return NewController(src, topics, decoder, quarantine, service, partitionedCache, health)
+}
 
-	// Подписка на топик
-	err = consumer.SubscribeTopics([]string{topic}, nil)
-	if err != nil {
-		consumer.Close()
+// NewController создаёт контроллер поверх произвольного источника сообщений
+// и подписывает источник на топики
+func NewController(src source.MessageSource, topics []string, decoder codec.Decoder, quarantine Quarantine, service service.Service, partitionedCache bool, health Health) (KafkaController, error) {
+	c := &kafkaController{
+		source:           src,
+		decoder:          decoder,
+		quarantine:       quarantine,
+		service:          service,
+		drainer:          lifecycle.NewDrainer(),
+		tracker:          newPartitionTracker(),
+		partitionedCache: partitionedCache,
+		counts:           make(map[string]int),
+		applied:          make(map[source.Partition]bool),
+		health:           health,
+		held:             make(map[source.Partition]bool),
+		errors:           newErrorLog(errorLogSize),
+	}
+	if err := src.Subscribe(topics, c.tracker); err != nil {
+		src.Close()
 		return nil, err
 	}
-
-	return &kafkaController{
-		consumer: consumer,
-		service:  service,
-	}, nil
+	return c, nil
 }
 
 func (c *kafkaController) Consume(ctx context.Context) error {
-	for {
-		select {
-		case <-ctx.Done():
-			return nil // Грациозное завершение
-		default:
-			// Чтение сообщения с таймаутом 1 секунда
-			msg, err := c.consumer.ReadMessage(1 * time.Second)
-			if err != nil {
-				var kafkaErr kafka.Error
-				if errors.As(err, &kafkaErr) && kafkaErr.Code() == kafka.ErrTimedOut {
-					continue // Таймаут, продолжаем цикл
-				}
-				log.Printf("Failed to read message: %v", err)
-				continue
-			}
+	// fetch отменяется при Shutdown, work — только по ctx или по истечении срока остановки
+	fetch, work, end := c.drainer.Begin(ctx)
+	defer end()
 
-			// Десериализация сообщения
-			var order entity.Order
-			if err := json.Unmarshal(msg.Value, &order); err != nil {
-				log.Printf("Failed to unmarshal message: %v", err)
-				continue
-			}
+	for fetch.Err() == nil {
+		c.rebalance(work)
+		if len(c.pending) > 0 {
+			c.retryPending(fetch, work)
+			continue
+		}
 
-			// Обработка заказа через сервис
-			if err := c.service.ProcessOrder(ctx, order); err != nil {
-				log.Printf("Failed to process order %s: %v", order.OrderUID, err)
-				continue
+		// Чтение сообщения с таймаутом 1 секунда
+		msg, err := c.source.Fetch(fetch, 1*time.Second)
+		if err != nil {
+			if fetch.Err() != nil {
+				break
 			}
-
-			// Ручное подтверждение смещения
-			_, err = c.consumer.CommitMessage(msg)
-			if err != nil {
-				log.Printf("Failed to commit message: %v", err)
-				continue
+			if errors.Is(err, source.ErrClosed) {
+				return err
 			}
+			log.Printf("Failed to read message: %v", err)
+			continue
+		}
+		if msg == nil {
+			continue // Таймаут, продолжаем цикл
+		}
+		if fetch.Err() != nil {
+			// Остановка началась во время чтения: смещение не коммитится, сообщение прочитают снова
+			break
+		}
 
-			log.Printf("Successfully processed order %s", order.OrderUID)
+		if !c.handle(work, msg) {
+			c.hold(msg)
 		}
 	}
+	return nil // Грациозное завершение
+}
+
+// handle обрабатывает одно сообщение и коммитит его смещение. Возвращает false,
+// если заказ не сохранён из-за сбоя хранилища и сообщение нужно повторить
+func (c *kafkaController) handle(ctx context.Context, msg *source.Message) bool {
+	// Десериализация сообщения
+	order, err := c.decoder.Decode(ctx, msg.Value, msg.Headers)
+	if errors.Is(err, codec.ErrUnknownVersion) {
+		c.toQuarantine(ctx, msg, err)
+		return true
+	}
+	if err != nil {
+		log.Printf("Failed to decode message %s: %v", msg, err)
+		c.errors.add(msg, "", StageDecode, err)
+		return true
+	}
+
+	// Обработка заказа через сервис
+	if err := c.service.ProcessOrder(ctx, order); err != nil {
+		log.Printf("Failed to process order %s: %v", order.OrderUID, err)
+		c.errors.add(msg, order.OrderUID, StageProcess, err)
+		return c.health == nil || !c.health.Failure(err)
+	}
+
+	// Ручное подтверждение смещения
+	if err := c.source.Commit(ctx, msg); err != nil {
+		log.Printf("Failed to commit message: %v", err)
+		c.errors.add(msg, order.OrderUID, StageCommit, err)
+		return true
+	}
+
+	log.Printf("Successfully processed order %s", order.OrderUID)
+	return true
+}
+
+// toQuarantine откладывает сообщение и коммитит его смещение,
+// чтобы оно не блокировало партицию
+func (c *kafkaController) toQuarantine(ctx context.Context, msg *source.Message, reason error) {
+	if c.quarantine == nil {
+		log.Printf("Dropping message %s: %v (quarantine is not configured)", msg, reason)
+		c.errors.add(msg, "", StageDecode, reason)
+		return
+	}
+	if err := c.quarantine.Put(ctx, msg, reason); err != nil {
+		log.Printf("Failed to quarantine message %s: %v", msg, err)
+		c.errors.add(msg, "", StageQuarantine, err)
+		return
+	}
+	if err := c.source.Commit(ctx, msg); err != nil {
+		log.Printf("Failed to commit message: %v", err)
+		c.errors.add(msg, "", StageCommit, err)
+	}
+}
+
+// rebalance применяет изменения назначения партиций, накопленные колбэками
+func (c *kafkaController) rebalance(ctx context.Context) {
+	partitions, changed := c.tracker.takeChanges()
+	if !changed {
+		return
+	}
+	c.reconcilePending(partitions)
+	c.applyPauses(partitions)
+	if c.partitionedCache {
+		c.applyOwnership(ctx, partitions)
+	}
+}
+
+// applyOwnership перестраивает кэш после ребалансировки: вытесняет заказы отозванных
+// партиций и догружает из БД заказы новых. Отзыв и повторное назначение тех же партиций
+// между двумя итерациями цикла (eager-ребалансировка) кэш не трогают
+func (c *kafkaController) applyOwnership(ctx context.Context, partitions []source.Partition) {
+	assigned := make(map[source.Partition]bool, len(partitions))
+	gained := false
+	for _, p := range partitions {
+		assigned[p] = true
+		gained = gained || !c.applied[p]
+		if _, ok := c.counts[p.Topic]; ok {
+			continue
+		}
+		n, err := c.source.Partitions(p.Topic)
+		if err != nil {
+			log.Printf("Failed to get partition count of topic %s: %v", p.Topic, err)
+			c.tracker.retry()
+			return
+		}
+		c.counts[p.Topic] = n
+	}
+	if !gained && len(assigned) == len(c.applied) {
+		return
+	}
+
+	counts := make(map[string]int, len(c.counts))
+	for topic, n := range c.counts {
+		counts[topic] = n
+	}
+	owned := ownership{counts: counts, assigned: assigned}
+	if err := c.service.SetOwnership(ctx, owned.owns, gained); err != nil {
+		log.Printf("Failed to warm cache for partitions %v: %v", partitions, err)
+		c.tracker.retry()
+		return
+	}
+	c.applied = assigned
+	log.Printf("Cache now holds orders of partitions %v", partitions)
+}
+
+// Shutdown прекращает чтение и ждёт, пока Consume дообработает текущее сообщение
+// и закоммитит его смещение; по истечении ctx обработка прерывается
+func (c *kafkaController) Shutdown(ctx context.Context) error {
+	return c.drainer.Drain(ctx)
+}
+
+func (c *kafkaController) Assignment() []source.Partition {
+	return c.tracker.Partitions()
 }
 
 func (c *kafkaController) Close() error {
-	return c.consumer.Close()
+	return c.source.Close()
 }
@@ -0,0 +1,195 @@
+package kafka
+
+import (
+    "context"
+    "encoding/json"
+    "order/internal/codec"
+    "order/internal/entity"
+    "order/internal/service"
+    "order/internal/source"
+    "order/internal/storage"
+    "order/internal/storage/memory"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+// fakeQuarantine запоминает отложенные сообщения
+// (mock из mock/ здесь недоступен из-за цикла импортов)
+type fakeQuarantine struct {
+    messages []*source.Message
+    reasons  []error
+}
+
+func (q *fakeQuarantine) Put(_ context.Context, msg *source.Message, reason error) error {
+    q.messages = append(q.messages, msg)
+    q.reasons = append(q.reasons, reason)
+    return nil
+}
+
+func (q *fakeQuarantine) Close(context.Context) error { return nil }
+
+func produceOrder(t *testing.T, broker *source.MemoryBroker, uid string, headers map[string]string) {
+    value, err := json.Marshal(entity.Order{
+        OrderUID: uid,
+        Delivery: entity.Delivery{Name: "John", Phone: "1234567890"},
+        Payment:  entity.Payment{Amount: entity.NewMoney(1000)},
+        Items:    []entity.Item{{ChrtID: 1, Price: entity.NewMoney(500)}},
+    })
+    require.NoError(t, err)
+    _, err = broker.Produce("orders", []byte("key"), value, headers)
+    require.NoError(t, err)
+}
+
+func TestKafkaController_Consume(t *testing.T) {
+    broker := source.NewMemoryBroker()
+    broker.CreateTopic("orders", 2)
+
+    // Все сообщения с одним ключом попадают в одну партицию
+    produceOrder(t, broker, "uid-1", nil)
+    _, err := broker.Produce("orders", []byte("key"), []byte("not json"), nil)
+    require.NoError(t, err)
+    produceOrder(t, broker, "uid-2", nil)
+    produceOrder(t, broker, "uid-future", map[string]string{codec.HeaderSchemaVersion: "99"})
+    last, err := broker.Produce("orders", []byte("key"), []byte(`{"order_uid":"uid-3"}`), nil)
+    require.NoError(t, err)
+
+    quarantine := &fakeQuarantine{}
+
+    store := memory.NewStore()
+    decoder, err := codec.NewDecoder(codec.FormatJSON, nil, nil)
+    require.NoError(t, err)
+    controller, err := NewController(broker.NewSource("order-group"), []string{"^ord.*"}, decoder, quarantine, service.NewService(store), false, nil)
+    require.NoError(t, err)
+
+    ctx, cancel := context.WithCancel(context.Background())
+    done := make(chan error, 1)
+    go func() { done <- controller.Consume(ctx) }()
+
+    require.Eventually(t, func() bool {
+        return broker.Committed("order-group", last.TopicPartition()) == last.Offset+1
+    }, 5*time.Second, 10*time.Millisecond)
+    cancel()
+    require.NoError(t, <-done)
+    require.NoError(t, controller.Close())
+
+    orders, err := storage.Collect(store.Orders(context.Background(), storage.Filter{}))
+    require.NoError(t, err)
+    var uids []string
+    for _, order := range orders {
+        uids = append(uids, order.OrderUID)
+    }
+    assert.Equal(t, []string{"uid-1", "uid-2", "uid-3"}, uids)
+
+    require.Len(t, quarantine.messages, 1)
+    assert.Equal(t, int64(3), quarantine.messages[0].Offset)
+    assert.ErrorIs(t, quarantine.reasons[0], codec.ErrUnknownVersion)
+}
+
+func TestKafkaController_ResumesFromCommittedOffset(t *testing.T) {
+    broker := source.NewMemoryBroker()
+    broker.CreateTopic("orders", 1)
+    produceOrder(t, broker, "uid-1", nil)
+
+    // Смещение уже закоммичено группой — сообщение не должно обрабатываться повторно
+    src := broker.NewSource("order-group")
+    require.NoError(t, src.Subscribe([]string{"orders"}, nil))
+    msg, err := src.Fetch(context.Background(), time.Second)
+    require.NoError(t, err)
+    require.NoError(t, src.Commit(context.Background(), msg))
+    require.NoError(t, src.Close())
+
+    produceOrder(t, broker, "uid-2", nil)
+
+    store := memory.NewStore()
+    decoder, err := codec.NewDecoder(codec.FormatJSON, nil, nil)
+    require.NoError(t, err)
+    controller, err := NewController(broker.NewSource("order-group"), []string{"orders"}, decoder, nil, service.NewService(store), false, nil)
+    require.NoError(t, err)
+
+    ctx, cancel := context.WithCancel(context.Background())
+    done := make(chan error, 1)
+    go func() { done <- controller.Consume(ctx) }()
+
+    require.Eventually(t, func() bool {
+        return broker.Committed("order-group", source.Partition{Topic: "orders"}) == 2
+    }, 5*time.Second, 10*time.Millisecond)
+    cancel()
+    require.NoError(t, <-done)
+
+    _, err = store.GetOrder(context.Background(), "uid-1")
+    assert.Error(t, err)
+    _, err = store.GetOrder(context.Background(), "uid-2")
+    assert.NoError(t, err)
+}
+
+// slowService задерживает обработку заказов, пока тест не отпустит release
+type slowService struct {
+    service.Service
+    started chan string
+    release chan struct{}
+}
+
+func (s *slowService) ProcessOrder(ctx context.Context, order entity.Order) error {
+    s.started <- order.OrderUID
+    select {
+    case <-s.release:
+        return s.Service.ProcessOrder(ctx, order)
+    case <-ctx.Done():
+        return ctx.Err()
+    }
+}
+
+func TestKafkaController_Shutdown(t *testing.T) {
+    newController := func(t *testing.T) (*source.MemoryBroker, *slowService, KafkaController) {
+        broker := source.NewMemoryBroker()
+        broker.CreateTopic("orders", 1)
+        produceOrder(t, broker, "uid-1", nil)
+        produceOrder(t, broker, "uid-2", nil)
+
+        svc := &slowService{Service: service.NewService(memory.NewStore()), started: make(chan string, 2), release: make(chan struct{})}
+        decoder, err := codec.NewDecoder(codec.FormatJSON, nil, nil)
+        require.NoError(t, err)
+        controller, err := NewController(broker.NewSource("order-group"), []string{"orders"}, decoder, nil, svc, false, nil)
+        require.NoError(t, err)
+        return broker, svc, controller
+    }
+    partition := source.Partition{Topic: "orders"}
+
+    t.Run("In-flight order is finished and committed", func(t *testing.T) {
+        broker, svc, controller := newController(t)
+        done := make(chan error, 1)
+        go func() { done <- controller.Consume(context.Background()) }()
+        assert.Equal(t, "uid-1", <-svc.started)
+
+        shutdown := make(chan error, 1)
+        go func() {
+            ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+            defer cancel()
+            shutdown <- controller.Shutdown(ctx)
+        }()
+        time.Sleep(20 * time.Millisecond)
+        close(svc.release)
+
+        require.NoError(t, <-shutdown)
+        require.NoError(t, <-done)
+        // Второй заказ уже не читается, его смещение не закоммичено
+        assert.Equal(t, int64(1), broker.Committed("order-group", partition))
+        assert.Empty(t, svc.started)
+    })
+
+    t.Run("Deadline aborts in-flight order", func(t *testing.T) {
+        broker, svc, controller := newController(t)
+        done := make(chan error, 1)
+        go func() { done <- controller.Consume(context.Background()) }()
+        <-svc.started
+
+        ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+        defer cancel()
+        assert.ErrorIs(t, controller.Shutdown(ctx), context.DeadlineExceeded)
+        require.NoError(t, <-done)
+        assert.Equal(t, int64(0), broker.Committed("order-group", partition))
+    })
+}
@@ -1,8 +1,46 @@
 package kafka
 
-import "context"
+import (
+	"context"
+	"order/internal/source"
+)
 
 type KafkaController interface {
 	Consume(ctx context.Context) error
+	// Shutdown прекращает чтение и ждёт завершения текущей обработки не дольше ctx
+	Shutdown(ctx context.Context) error
+	// Assignment возвращает партиции, назначенные консюмеру группой
+	Assignment() []source.Partition
+	// Paused сообщает, что чтение приостановлено до восстановления хранилища
+	Paused() bool
+	// Pause и Resume ставят на паузу и возобновляют чтение партиций по запросу оператора;
+	// пустой список — все назначенные партиции
+	Pause(partitions []source.Partition) error
+	Resume(partitions []source.Partition) error
+	// Partitions возвращает назначенные партиции со смещениями группы и отставанием
+	Partitions(ctx context.Context) ([]PartitionStatus, error)
+	// Errors возвращает до n последних ошибок обработки, новые первыми
+	Errors(n int) []ProcessingError
 	Close() error
 }
+
+// Replayer перечитывает диапазоны смещений топика и повторно обрабатывает заказы
+type Replayer interface {
+	Replay(ctx context.Context, req ReplayRequest) (ReplayReport, error)
+}
+
+// Quarantine откладывает сообщения, которые сервис пока не умеет обработать
+// (например, записанные неизвестной будущей версией схемы)
+type Quarantine interface {
+	Put(ctx context.Context, msg *source.Message, reason error) error
+	// Close дожидается доставки отложенных сообщений не дольше ctx
+	Close(ctx context.Context) error
+}
+
+// Health — состояние хранилища (см. breaker.Breaker)
+type Health interface {
+	// Ready сообщает, можно ли сейчас обращаться к хранилищу
+	Ready() bool
+	// Failure отличает сбой хранилища от ошибки конкретного заказа
+	Failure(err error) bool
+}
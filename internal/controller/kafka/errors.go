@@ -0,0 +1,71 @@
+package kafka
+
+import (
+	"order/internal/source"
+	"sync"
+	"time"
+)
+
+// errorLogSize — сколько последних ошибок обработки хранит контроллер
+const errorLogSize = 100
+
+// Этапы обработки, на которых возникла ошибка
+const (
+	StageDecode     = "decode"
+	StageProcess    = "process"
+	StageCommit     = "commit"
+	StageQuarantine = "quarantine"
+)
+
+// ProcessingError — ошибка обработки сообщения
+type ProcessingError struct {
+	Time      time.Time        `json:"time"`
+	Partition source.Partition `json:"partition"`
+	Offset    int64            `json:"offset"`
+	OrderUID  string           `json:"order_uid,omitempty"`
+	Stage     string           `json:"stage"`
+	Error     string           `json:"error"`
+}
+
+// errorLog — кольцевой буфер последних ошибок
+type errorLog struct {
+	mu      sync.Mutex
+	entries []ProcessingError
+	next    int
+}
+
+func newErrorLog(size int) *errorLog {
+	return &errorLog{entries: make([]ProcessingError, 0, size)}
+}
+
+func (l *errorLog) add(msg *source.Message, orderUID, stage string, err error) {
+	entry := ProcessingError{
+		Time:      time.Now(),
+		Partition: msg.TopicPartition(),
+		Offset:    msg.Offset,
+		OrderUID:  orderUID,
+		Stage:     stage,
+		Error:     err.Error(),
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.entries) < cap(l.entries) {
+		l.entries = append(l.entries, entry)
+	} else {
+		l.entries[l.next] = entry
+	}
+	l.next = (l.next + 1) % cap(l.entries)
+}
+
+// last возвращает до n последних ошибок, новые первыми
+func (l *errorLog) last(n int) []ProcessingError {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n = min(n, len(l.entries))
+	result := make([]ProcessingError, 0, n)
+	for i := 1; i <= n; i++ {
+		result = append(result, l.entries[(l.next-i+cap(l.entries))%cap(l.entries)])
+	}
+	return result
+}
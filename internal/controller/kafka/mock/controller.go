@@ -0,0 +1,313 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/controller/kafka/endpoint.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/controller/kafka/endpoint.go -destination=internal/controller/kafka/mock/controller.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	kafka "order/internal/controller/kafka"
+	source "order/internal/source"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockKafkaController is a mock of KafkaController interface.
+type MockKafkaController struct {
+	ctrl     *gomock.Controller
+	recorder *MockKafkaControllerMockRecorder
+	isgomock struct{}
+}
+
+// MockKafkaControllerMockRecorder is the mock recorder for MockKafkaController.
+type MockKafkaControllerMockRecorder struct {
+	mock *MockKafkaController
+}
+
+// NewMockKafkaController creates a new mock instance.
+func NewMockKafkaController(ctrl *gomock.Controller) *MockKafkaController {
+	mock := &MockKafkaController{ctrl: ctrl}
+	mock.recorder = &MockKafkaControllerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKafkaController) EXPECT() *MockKafkaControllerMockRecorder {
+	return m.recorder
+}
+
+// Assignment mocks base method.
+func (m *MockKafkaController) Assignment() []source.Partition {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Assignment")
+	ret0, _ := ret[0].([]source.Partition)
+	return ret0
+}
+
+// Assignment indicates an expected call of Assignment.
+func (mr *MockKafkaControllerMockRecorder) Assignment() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Assignment", reflect.TypeOf((*MockKafkaController)(nil).Assignment))
+}
+
+// Close mocks base method.
+func (m *MockKafkaController) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockKafkaControllerMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockKafkaController)(nil).Close))
+}
+
+// Consume mocks base method.
+func (m *MockKafkaController) Consume(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Consume", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Consume indicates an expected call of Consume.
+func (mr *MockKafkaControllerMockRecorder) Consume(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Consume", reflect.TypeOf((*MockKafkaController)(nil).Consume), ctx)
+}
+
+// Errors mocks base method.
+func (m *MockKafkaController) Errors(n int) []kafka.ProcessingError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Errors", n)
+	ret0, _ := ret[0].([]kafka.ProcessingError)
+	return ret0
+}
+
+// Errors indicates an expected call of Errors.
+func (mr *MockKafkaControllerMockRecorder) Errors(n any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Errors", reflect.TypeOf((*MockKafkaController)(nil).Errors), n)
+}
+
+// Partitions mocks base method.
+func (m *MockKafkaController) Partitions(ctx context.Context) ([]kafka.PartitionStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Partitions", ctx)
+	ret0, _ := ret[0].([]kafka.PartitionStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Partitions indicates an expected call of Partitions.
+func (mr *MockKafkaControllerMockRecorder) Partitions(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Partitions", reflect.TypeOf((*MockKafkaController)(nil).Partitions), ctx)
+}
+
+// Pause mocks base method.
+func (m *MockKafkaController) Pause(partitions []source.Partition) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pause", partitions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Pause indicates an expected call of Pause.
+func (mr *MockKafkaControllerMockRecorder) Pause(partitions any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pause", reflect.TypeOf((*MockKafkaController)(nil).Pause), partitions)
+}
+
+// Paused mocks base method.
+func (m *MockKafkaController) Paused() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Paused")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Paused indicates an expected call of Paused.
+func (mr *MockKafkaControllerMockRecorder) Paused() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Paused", reflect.TypeOf((*MockKafkaController)(nil).Paused))
+}
+
+// Resume mocks base method.
+func (m *MockKafkaController) Resume(partitions []source.Partition) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resume", partitions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Resume indicates an expected call of Resume.
+func (mr *MockKafkaControllerMockRecorder) Resume(partitions any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resume", reflect.TypeOf((*MockKafkaController)(nil).Resume), partitions)
+}
+
+// Shutdown mocks base method.
+func (m *MockKafkaController) Shutdown(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Shutdown", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Shutdown indicates an expected call of Shutdown.
+func (mr *MockKafkaControllerMockRecorder) Shutdown(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shutdown", reflect.TypeOf((*MockKafkaController)(nil).Shutdown), ctx)
+}
+
+// MockReplayer is a mock of Replayer interface.
+type MockReplayer struct {
+	ctrl     *gomock.Controller
+	recorder *MockReplayerMockRecorder
+	isgomock struct{}
+}
+
+// MockReplayerMockRecorder is the mock recorder for MockReplayer.
+type MockReplayerMockRecorder struct {
+	mock *MockReplayer
+}
+
+// NewMockReplayer creates a new mock instance.
+func NewMockReplayer(ctrl *gomock.Controller) *MockReplayer {
+	mock := &MockReplayer{ctrl: ctrl}
+	mock.recorder = &MockReplayerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReplayer) EXPECT() *MockReplayerMockRecorder {
+	return m.recorder
+}
+
+// Replay mocks base method.
+func (m *MockReplayer) Replay(ctx context.Context, req kafka.ReplayRequest) (kafka.ReplayReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Replay", ctx, req)
+	ret0, _ := ret[0].(kafka.ReplayReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Replay indicates an expected call of Replay.
+func (mr *MockReplayerMockRecorder) Replay(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Replay", reflect.TypeOf((*MockReplayer)(nil).Replay), ctx, req)
+}
+
+// MockQuarantine is a mock of Quarantine interface.
+type MockQuarantine struct {
+	ctrl     *gomock.Controller
+	recorder *MockQuarantineMockRecorder
+	isgomock struct{}
+}
+
+// MockQuarantineMockRecorder is the mock recorder for MockQuarantine.
+type MockQuarantineMockRecorder struct {
+	mock *MockQuarantine
+}
+
+// NewMockQuarantine creates a new mock instance.
+func NewMockQuarantine(ctrl *gomock.Controller) *MockQuarantine {
+	mock := &MockQuarantine{ctrl: ctrl}
+	mock.recorder = &MockQuarantineMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockQuarantine) EXPECT() *MockQuarantineMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockQuarantine) Close(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockQuarantineMockRecorder) Close(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockQuarantine)(nil).Close), ctx)
+}
+
+// Put mocks base method.
+func (m *MockQuarantine) Put(ctx context.Context, msg *source.Message, reason error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Put", ctx, msg, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Put indicates an expected call of Put.
+func (mr *MockQuarantineMockRecorder) Put(ctx, msg, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockQuarantine)(nil).Put), ctx, msg, reason)
+}
+
+// MockHealth is a mock of Health interface.
+type MockHealth struct {
+	ctrl     *gomock.Controller
+	recorder *MockHealthMockRecorder
+	isgomock struct{}
+}
+
+// MockHealthMockRecorder is the mock recorder for MockHealth.
+type MockHealthMockRecorder struct {
+	mock *MockHealth
+}
+
+// NewMockHealth creates a new mock instance.
+func NewMockHealth(ctrl *gomock.Controller) *MockHealth {
+	mock := &MockHealth{ctrl: ctrl}
+	mock.recorder = &MockHealthMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHealth) EXPECT() *MockHealthMockRecorder {
+	return m.recorder
+}
+
+// Failure mocks base method.
+func (m *MockHealth) Failure(err error) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Failure", err)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Failure indicates an expected call of Failure.
+func (mr *MockHealthMockRecorder) Failure(err any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Failure", reflect.TypeOf((*MockHealth)(nil).Failure), err)
+}
+
+// Ready mocks base method.
+func (m *MockHealth) Ready() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ready")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Ready indicates an expected call of Ready.
+func (mr *MockHealthMockRecorder) Ready() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ready", reflect.TypeOf((*MockHealth)(nil).Ready))
+}
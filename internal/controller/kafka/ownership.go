@@ -0,0 +1,93 @@
+package kafka
+
+import (
+	"log"
+	"order/internal/source"
+	"sort"
+	"sync"
+)
+
+// partitionTracker отслеживает назначенные консюмеру партиции. Колбэки ребалансировки
+// только запоминают изменения, а кэш перестраивается в цикле Consume — вне колбэка librdkafka
+type partitionTracker struct {
+	mu       sync.Mutex
+	assigned map[source.Partition]bool
+	changed  bool // назначение изменилось с последнего применения
+}
+
+func newPartitionTracker() *partitionTracker {
+	return &partitionTracker{assigned: make(map[source.Partition]bool)}
+}
+
+func (t *partitionTracker) Assigned(partitions []source.Partition) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, p := range partitions {
+		t.assigned[p] = true
+	}
+	t.changed = true
+	log.Printf("Partitions assigned: %v", partitions)
+}
+
+func (t *partitionTracker) Revoked(partitions []source.Partition) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, p := range partitions {
+		delete(t.assigned, p)
+	}
+	t.changed = true
+	log.Printf("Partitions revoked: %v", partitions)
+}
+
+// Partitions возвращает текущее назначение, отсортированное по топику и номеру
+func (t *partitionTracker) Partitions() []source.Partition {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.snapshot()
+}
+
+// takeChanges возвращает назначение и сбрасывает флаг изменений
+func (t *partitionTracker) takeChanges() (partitions []source.Partition, changed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	changed, t.changed = t.changed, false
+	return t.snapshot(), changed
+}
+
+// retry помечает назначение как неприменённое
+func (t *partitionTracker) retry() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.changed = true
+}
+
+func (t *partitionTracker) snapshot() []source.Partition {
+	partitions := make([]source.Partition, 0, len(t.assigned))
+	for p := range t.assigned {
+		partitions = append(partitions, p)
+	}
+	sort.Slice(partitions, func(i, j int) bool {
+		if partitions[i].Topic != partitions[j].Topic {
+			return partitions[i].Topic < partitions[j].Topic
+		}
+		return partitions[i].Partition < partitions[j].Partition
+	})
+	return partitions
+}
+
+// ownership решает, принадлежит ли заказ назначенным партициям: ключ сообщения — order_uid,
+// партиция вычисляется партиционером Kafka по умолчанию (murmur2)
+type ownership struct {
+	counts   map[string]int // число партиций по топикам
+	assigned map[source.Partition]bool
+}
+
+func (o ownership) owns(orderUID string) bool {
+	for topic, n := range o.counts {
+		p := source.Partition{Topic: topic, Partition: source.PartitionFor([]byte(orderUID), n)}
+		if o.assigned[p] {
+			return true
+		}
+	}
+	return false
+}
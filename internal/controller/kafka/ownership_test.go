@@ -0,0 +1,108 @@
+package kafka
+
+import (
+    "context"
+    "fmt"
+    "order/internal/codec"
+    "order/internal/service/mock"
+    "order/internal/source"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "go.uber.org/mock/gomock"
+)
+
+// setOwnershipCall — аргументы вызова SetOwnership
+type setOwnershipCall struct {
+    owns func(string) bool
+    warm bool
+}
+
+func TestKafkaController_PartitionedCache(t *testing.T) {
+    ctrl := gomock.NewController(t)
+    defer ctrl.Finish()
+
+    broker := source.NewMemoryBroker()
+    broker.CreateTopic("orders", 4)
+    p := func(n int32) source.Partition { return source.Partition{Topic: "orders", Partition: n} }
+
+    calls := make(chan setOwnershipCall, 10)
+    svc := mock.NewMockService(ctrl)
+    svc.EXPECT().SetOwnership(gomock.Any(), gomock.Any(), gomock.Any()).
+        DoAndReturn(func(_ context.Context, owns func(string) bool, warm bool) error {
+            calls <- setOwnershipCall{owns: owns, warm: warm}
+            return nil
+        }).AnyTimes()
+
+    decoder, err := codec.NewDecoder(codec.FormatJSON, nil, nil)
+    require.NoError(t, err)
+    src := broker.NewSource("order-group")
+    controller, err := NewController(src, []string{"orders"}, decoder, nil, svc, true, nil)
+    require.NoError(t, err)
+    assert.Equal(t, []source.Partition{p(0), p(1), p(2), p(3)}, controller.Assignment())
+
+    ctx, cancel := context.WithCancel(context.Background())
+    done := make(chan error, 1)
+    go func() { done <- controller.Consume(ctx) }()
+    defer func() {
+        cancel()
+        require.NoError(t, <-done)
+    }()
+
+    next := func() setOwnershipCall {
+        select {
+        case call := <-calls:
+            return call
+        case <-time.After(5 * time.Second):
+            t.Fatal("SetOwnership was not called")
+            return setOwnershipCall{}
+        }
+    }
+
+    // Первое назначение — прогрев кэша
+    call := next()
+    assert.True(t, call.warm)
+    for i := 0; i < 20; i++ {
+        assert.True(t, call.owns(fmt.Sprintf("uid-%d", i)))
+    }
+
+    // Часть партиций ушла другому экземпляру — только вытеснение
+    src.Reassign([]source.Partition{p(0), p(1)})
+    call = next()
+    assert.False(t, call.warm)
+    for i := 0; i < 20; i++ {
+        uid := fmt.Sprintf("uid-%d", i)
+        assert.Equal(t, source.PartitionFor([]byte(uid), 4) < 2, call.owns(uid), uid)
+    }
+
+    // Тот же набор после eager-ребалансировки ничего не меняет, новая партиция — догрузка
+    src.Reassign([]source.Partition{p(0), p(1)})
+    src.Reassign([]source.Partition{p(0), p(1), p(2)})
+    call = next()
+    assert.True(t, call.warm)
+    assert.Equal(t, []source.Partition{p(0), p(1), p(2)}, controller.Assignment())
+}
+
+func TestKafkaController_AllCacheIgnoresRebalance(t *testing.T) {
+    ctrl := gomock.NewController(t)
+    defer ctrl.Finish()
+
+    broker := source.NewMemoryBroker()
+    broker.CreateTopic("orders", 2)
+    // SetOwnership не должен вызываться
+    svc := mock.NewMockService(ctrl)
+
+    decoder, err := codec.NewDecoder(codec.FormatJSON, nil, nil)
+    require.NoError(t, err)
+    src := broker.NewSource("order-group")
+    controller, err := NewController(src, []string{"orders"}, decoder, nil, svc, false, nil)
+    require.NoError(t, err)
+
+    ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+    defer cancel()
+    src.Reassign([]source.Partition{{Topic: "orders", Partition: 1}})
+    require.NoError(t, controller.Consume(ctx))
+    assert.Equal(t, []source.Partition{{Topic: "orders", Partition: 1}}, controller.Assignment())
+}
@@ -0,0 +1,85 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"order/internal/source"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// Заголовки, которые добавляются к сообщению при отправке в карантин
+const (
+	HeaderQuarantineReason = "quarantine-reason"
+	HeaderOriginalTopic    = "original-topic"
+	HeaderOriginalOffset   = "original-offset"
+)
+
+type kafkaQuarantine struct {
+	producer *kafka.Producer
+	topic    string
+}
+
+// NewQuarantine создаёт карантин, который перекладывает сообщения в отдельный топик
+// без изменений (ключ, значение и заголовки сохраняются), чтобы их можно было
+// перечитать после обновления сервиса
+func NewQuarantine(base kafka.ConfigMap, topic string) (Quarantine, error) {
+	producer, err := kafka.NewProducer(withProperties(base, kafka.ConfigMap{
+		"acks": "all",
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return &kafkaQuarantine{producer: producer, topic: topic}, nil
+}
+
+func (q *kafkaQuarantine) Put(ctx context.Context, msg *source.Message, reason error) error {
+	headers := make([]kafka.Header, 0, len(msg.Headers)+3)
+	for key, value := range msg.Headers {
+		headers = append(headers, kafka.Header{Key: key, Value: []byte(value)})
+	}
+	headers = append(headers,
+		kafka.Header{Key: HeaderQuarantineReason, Value: []byte(reason.Error())},
+		kafka.Header{Key: HeaderOriginalTopic, Value: []byte(msg.Topic)},
+		kafka.Header{Key: HeaderOriginalOffset, Value: []byte(fmt.Sprintf("%d/%d", msg.Partition, msg.Offset))},
+	)
+
+	delivery := make(chan kafka.Event, 1)
+	err := q.producer.Produce(&kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &q.topic, Partition: kafka.PartitionAny},
+		Key:            msg.Key,
+		Value:          msg.Value,
+		Headers:        headers,
+	}, delivery)
+	if err != nil {
+		return err
+	}
+
+	// Ждём подтверждения, чтобы не закоммитить смещение потерянного сообщения
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case e := <-delivery:
+		if m, ok := e.(*kafka.Message); ok && m.TopicPartition.Error != nil {
+			return m.TopicPartition.Error
+		}
+	}
+	log.Printf("Message %s moved to quarantine topic %s: %v", msg, q.topic, reason)
+	return nil
+}
+
+func (q *kafkaQuarantine) Close(ctx context.Context) error {
+	defer q.producer.Close()
+	return flush(ctx, q.producer)
+}
+
+// flush ждёт доставки сообщений продюсера, пока не истечёт ctx
+func flush(ctx context.Context, producer *kafka.Producer) error {
+	for remaining := producer.Flush(100); remaining > 0; remaining = producer.Flush(100) {
+		if ctx.Err() != nil {
+			return fmt.Errorf("%d messages not delivered: %w", remaining, ctx.Err())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"order/internal/codec"
+	"order/internal/service"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+const (
+	replayMetadataTimeout = 10 * time.Second
+	// Если за это время не пришло ни одного сообщения, replay завершается
+	// (например, последние смещения заняты маркерами транзакций)
+	replayIdleTimeout = 15 * time.Second
+)
+
+// Bound задаёт границу диапазона replay: смещение или момент времени.
+// Если не задано ни то ни другое, берётся начало (для From) или конец (для To) партиции
+type Bound struct {
+	Offset *int64     `json:"offset,omitempty"`
+	Time   *time.Time `json:"time,omitempty"`
+}
+
+// PartitionRange — диапазон одной партиции, To не включается
+type PartitionRange struct {
+	Partition int32 `json:"partition"`
+	From      Bound `json:"from"`
+	To        Bound `json:"to"`
+}
+
+// ReplayRequest описывает, что нужно перечитать.
+// Если Ranges пуст, From/To применяются ко всем партициям топика
+type ReplayRequest struct {
+	Topic  string           `json:"topic"`
+	Ranges []PartitionRange `json:"ranges,omitempty"`
+	From   Bound            `json:"from"`
+	To     Bound            `json:"to"`
+}
+
+type PartitionReport struct {
+	Partition  int32 `json:"partition"`
+	FromOffset int64 `json:"from_offset"`
+	ToOffset   int64 `json:"to_offset"`
+	Processed  int   `json:"processed"`
+	Complete   bool  `json:"complete"`
+}
+
+// ReplayReport — итог replay по исходам обработки заказов
+type ReplayReport struct {
+	Topic      string            `json:"topic"`
+	New        int               `json:"new"`
+	Updated    int               `json:"updated"`
+	Unchanged  int               `json:"unchanged"`
+	Rejected   int               `json:"rejected"`
+	Failed     int               `json:"failed"`
+	Partitions []PartitionReport `json:"partitions"`
+}
+
+func (r *ReplayReport) count(outcome service.Outcome) {
+	switch outcome {
+	case service.OutcomeNew:
+		r.New++
+	case service.OutcomeUpdated:
+		r.Updated++
+	case service.OutcomeUnchanged:
+		r.Unchanged++
+	case service.OutcomeRejected:
+		r.Rejected++
+	}
+}
+
+type replayer struct {
+	base    kafka.ConfigMap
+	groupID string
+	decoder codec.Decoder
+	service service.Service
+}
+
+// NewReplayer создаёт replayer, который на каждый запрос поднимает отдельного
+// консюмера с ручным назначением партиций и не коммитит смещения группы
+func NewReplayer(base kafka.ConfigMap, groupID string, decoder codec.Decoder, service service.Service) Replayer {
+	return &replayer{
+		base:    base,
+		groupID: groupID + "-replay",
+		decoder: decoder,
+		service: service,
+	}
+}
+
+func (r *replayer) Replay(ctx context.Context, req ReplayRequest) (ReplayReport, error) {
+	if req.Topic == "" {
+		return ReplayReport{}, errors.New("topic is required")
+	}
+
+	consumer, err := kafka.NewConsumer(withProperties(r.base, kafka.ConfigMap{
+		"group.id":           r.groupID,
+		"enable.auto.commit": false,
+	}))
+	if err != nil {
+		return ReplayReport{}, err
+	}
+	defer consumer.Close()
+
+	ranges := req.Ranges
+	if len(ranges) == 0 {
+		ranges, err = allPartitions(consumer, req)
+		if err != nil {
+			return ReplayReport{}, err
+		}
+	}
+
+	report := ReplayReport{Topic: req.Topic}
+	index := make(map[int32]int, len(ranges))
+	var assignment []kafka.TopicPartition
+	for _, pr := range ranges {
+		from, to, err := resolveRange(consumer, req.Topic, pr)
+		if err != nil {
+			return ReplayReport{}, err
+		}
+		index[pr.Partition] = len(report.Partitions)
+		report.Partitions = append(report.Partitions, PartitionReport{
+			Partition:  pr.Partition,
+			FromOffset: from,
+			ToOffset:   to,
+			Complete:   from >= to,
+		})
+		if from < to {
+			assignment = append(assignment, kafka.TopicPartition{
+				Topic:     &req.Topic,
+				Partition: pr.Partition,
+				Offset:    kafka.Offset(from),
+			})
+		}
+	}
+
+	if len(assignment) == 0 {
+		return report, nil
+	}
+	if err := consumer.Assign(assignment); err != nil {
+		return ReplayReport{}, err
+	}
+	log.Printf("Replay of topic %s started for %d partitions", req.Topic, len(assignment))
+
+	remaining := len(assignment)
+	lastMessage := time.Now()
+	for remaining > 0 {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		msg, err := consumer.ReadMessage(1 * time.Second)
+		if err != nil {
+			var kafkaErr kafka.Error
+			if errors.As(err, &kafkaErr) && kafkaErr.Code() == kafka.ErrTimedOut {
+				if time.Since(lastMessage) > replayIdleTimeout {
+					log.Printf("Replay of topic %s stopped: no messages for %s", req.Topic, replayIdleTimeout)
+					break
+				}
+				continue
+			}
+			log.Printf("Failed to read message: %v", err)
+			continue
+		}
+		lastMessage = time.Now()
+
+		i, ok := index[msg.TopicPartition.Partition]
+		if !ok {
+			continue
+		}
+		part := &report.Partitions[i]
+		offset := int64(msg.TopicPartition.Offset)
+		if part.Complete || offset >= part.ToOffset {
+			continue
+		}
+
+		r.process(ctx, msg, &report)
+		part.Processed++
+
+		if offset+1 >= part.ToOffset {
+			part.Complete = true
+			remaining--
+			if err := consumer.Pause([]kafka.TopicPartition{msg.TopicPartition}); err != nil {
+				log.Printf("Failed to pause partition %d: %v", part.Partition, err)
+			}
+		}
+	}
+
+	log.Printf("Replay of topic %s finished: new=%d updated=%d unchanged=%d rejected=%d failed=%d",
+		req.Topic, report.New, report.Updated, report.Unchanged, report.Rejected, report.Failed)
+	return report, nil
+}
+
+func (r *replayer) process(ctx context.Context, msg *kafka.Message, report *ReplayReport) {
+	order, err := r.decoder.Decode(ctx, msg.Value, headers(msg))
+	if err != nil {
+		log.Printf("Failed to decode message at %v: %v", msg.TopicPartition, err)
+		report.Rejected++
+		return
+	}
+
+	outcome, err := r.service.ReprocessOrder(ctx, order)
+	if err != nil {
+		log.Printf("Failed to reprocess order %s: %v", order.OrderUID, err)
+		report.Failed++
+		return
+	}
+	report.count(outcome)
+}
+
+// allPartitions разворачивает общие границы запроса на все партиции топика
+func allPartitions(consumer *kafka.Consumer, req ReplayRequest) ([]PartitionRange, error) {
+	metadata, err := consumer.GetMetadata(&req.Topic, false, int(replayMetadataTimeout.Milliseconds()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metadata for topic %s: %w", req.Topic, err)
+	}
+	topic, ok := metadata.Topics[req.Topic]
+	if !ok || topic.Error.Code() != kafka.ErrNoError {
+		return nil, fmt.Errorf("topic %s not found", req.Topic)
+	}
+
+	ranges := make([]PartitionRange, 0, len(topic.Partitions))
+	for _, p := range topic.Partitions {
+		ranges = append(ranges, PartitionRange{Partition: p.ID, From: req.From, To: req.To})
+	}
+	return ranges, nil
+}
+
+// resolveRange переводит границы диапазона в абсолютные смещения
+func resolveRange(consumer *kafka.Consumer, topic string, pr PartitionRange) (int64, int64, error) {
+	timeout := int(replayMetadataTimeout.Milliseconds())
+	low, high, err := consumer.QueryWatermarkOffsets(topic, pr.Partition, timeout)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to query watermarks of partition %d: %w", pr.Partition, err)
+	}
+
+	resolve := func(b Bound, fallback int64) (int64, error) {
+		switch {
+		case b.Offset != nil:
+			return min(max(*b.Offset, low), high), nil
+		case b.Time != nil:
+			offsets, err := consumer.OffsetsForTimes([]kafka.TopicPartition{{
+				Topic:     &topic,
+				Partition: pr.Partition,
+				Offset:    kafka.Offset(b.Time.UnixMilli()),
+			}}, timeout)
+			if err != nil {
+				return 0, fmt.Errorf("failed to look up offset by time for partition %d: %w", pr.Partition, err)
+			}
+			if len(offsets) == 0 || offsets[0].Offset < 0 {
+				// Сообщений позже указанного времени нет
+				return high, nil
+			}
+			return int64(offsets[0].Offset), nil
+		default:
+			return fallback, nil
+		}
+	}
+
+	from, err := resolve(pr.From, low)
+	if err != nil {
+		return 0, 0, err
+	}
+	to, err := resolve(pr.To, high)
+	if err != nil {
+		return 0, 0, err
+	}
+	return from, to, nil
+}
@@ -0,0 +1,167 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"order/internal/source"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// kafkaSource — MessageSource поверх консюмера librdkafka
+type kafkaSource struct {
+	consumer *kafka.Consumer
+}
+
+// NewSource создаёт консюмер группы с ручным коммитом смещений
+func NewSource(base kafka.ConfigMap, groupID string) (source.MessageSource, error) {
+	consumer, err := kafka.NewConsumer(withProperties(base, kafka.ConfigMap{
+		"group.id":           groupID,
+		"auto.offset.reset":  "earliest", // Начать с самого начала топика
+		"enable.auto.commit": false,      // Ручное подтверждение смещений
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return &kafkaSource{consumer: consumer}, nil
+}
+
+func (s *kafkaSource) Subscribe(topics []string, handler source.RebalanceHandler) error {
+	var cb kafka.RebalanceCb
+	if handler != nil {
+		// Назначение партиций после колбэка librdkafka выполняет сама
+		cb = func(_ *kafka.Consumer, e kafka.Event) error {
+			switch ev := e.(type) {
+			case kafka.AssignedPartitions:
+				handler.Assigned(toPartitions(ev.Partitions))
+			case kafka.RevokedPartitions:
+				handler.Revoked(toPartitions(ev.Partitions))
+			}
+			return nil
+		}
+	}
+	return s.consumer.SubscribeTopics(topics, cb)
+}
+
+func (s *kafkaSource) Fetch(_ context.Context, timeout time.Duration) (*source.Message, error) {
+	msg, err := s.consumer.ReadMessage(timeout)
+	if err != nil {
+		var kafkaErr kafka.Error
+		if errors.As(err, &kafkaErr) && kafkaErr.Code() == kafka.ErrTimedOut {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &source.Message{
+		Topic:     *msg.TopicPartition.Topic,
+		Partition: msg.TopicPartition.Partition,
+		Offset:    int64(msg.TopicPartition.Offset),
+		Key:       msg.Key,
+		Value:     msg.Value,
+		Headers:   headers(msg),
+		Timestamp: msg.Timestamp,
+	}, nil
+}
+
+func (s *kafkaSource) Commit(_ context.Context, msg *source.Message) error {
+	_, err := s.consumer.CommitOffsets([]kafka.TopicPartition{{
+		Topic:     &msg.Topic,
+		Partition: msg.Partition,
+		Offset:    kafka.Offset(msg.Offset + 1),
+	}})
+	return err
+}
+
+func (s *kafkaSource) Pause(partitions []source.Partition) error {
+	return s.consumer.Pause(fromPartitions(partitions))
+}
+
+func (s *kafkaSource) Resume(partitions []source.Partition) error {
+	return s.consumer.Resume(fromPartitions(partitions))
+}
+
+func (s *kafkaSource) Assignment() ([]source.Partition, error) {
+	partitions, err := s.consumer.Assignment()
+	if err != nil {
+		return nil, err
+	}
+	return toPartitions(partitions), nil
+}
+
+func (s *kafkaSource) Partitions(topic string) (int, error) {
+	md, err := s.consumer.GetMetadata(&topic, false, 10000)
+	if err != nil {
+		return 0, err
+	}
+	info, ok := md.Topics[topic]
+	if !ok || info.Error.Code() != kafka.ErrNoError {
+		return 0, fmt.Errorf("failed to get metadata of topic %s: %v", topic, info.Error)
+	}
+	return len(info.Partitions), nil
+}
+
+// Offsets запрашивает закоммиченные смещения группы и водяные знаки партиций у брокера
+func (s *kafkaSource) Offsets(ctx context.Context, partitions []source.Partition) ([]source.Offsets, error) {
+	timeout := 10 * time.Second
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	committed, err := s.consumer.Committed(fromPartitions(partitions), int(timeout.Milliseconds()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get committed offsets: %w", err)
+	}
+
+	offsets := make([]source.Offsets, 0, len(committed))
+	for _, tp := range committed {
+		low, high, err := s.consumer.QueryWatermarkOffsets(*tp.Topic, tp.Partition, int(timeout.Milliseconds()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get watermarks of %s[%d]: %w", *tp.Topic, tp.Partition, err)
+		}
+		o := source.Offsets{
+			Partition: source.Partition{Topic: *tp.Topic, Partition: tp.Partition},
+			Committed: int64(tp.Offset),
+			Low:       low,
+			High:      high,
+		}
+		if tp.Offset < 0 { // kafka.OffsetInvalid: группа ещё не коммитила
+			o.Committed = -1
+		}
+		offsets = append(offsets, o)
+	}
+	return offsets, nil
+}
+
+func (s *kafkaSource) Close() error {
+	return s.consumer.Close()
+}
+
+func toPartitions(tps []kafka.TopicPartition) []source.Partition {
+	partitions := make([]source.Partition, 0, len(tps))
+	for _, tp := range tps {
+		partitions = append(partitions, source.Partition{Topic: *tp.Topic, Partition: tp.Partition})
+	}
+	return partitions
+}
+
+func fromPartitions(partitions []source.Partition) []kafka.TopicPartition {
+	tps := make([]kafka.TopicPartition, 0, len(partitions))
+	for _, p := range partitions {
+		topic := p.Topic
+		tps = append(tps, kafka.TopicPartition{Topic: &topic, Partition: p.Partition})
+	}
+	return tps
+}
+
+// headers собирает заголовки сообщения в map (при повторах побеждает последний)
+func headers(msg *kafka.Message) map[string]string {
+	if len(msg.Headers) == 0 {
+		return nil
+	}
+	h := make(map[string]string, len(msg.Headers))
+	for _, header := range msg.Headers {
+		h[header.Key] = string(header.Value)
+	}
+	return h
+}
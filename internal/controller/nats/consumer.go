@@ -0,0 +1,240 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"order/config"
+	"order/internal/codec"
+	"order/internal/lifecycle"
+	"order/internal/service"
+	"order/internal/source"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// probeInterval — как часто при недоступном хранилище проверяется, не пора ли повторить
+const probeInterval = 200 * time.Millisecond
+
+type natsController struct {
+	conn       *nats.Conn
+	messages   jetstream.MessagesContext
+	decoder    codec.Decoder
+	quarantine Quarantine
+	service    service.Service
+	cfg        config.Nats
+	drainer    *lifecycle.Drainer
+	// health — состояние хранилища; nil — при ошибке сохранения сообщение сразу отдаётся на повтор
+	health Health
+}
+
+// NewNatsController подключается к NATS и создаёт (или обновляет) durable pull-консюмер
+// на существующем стриме. Семантика та же, что у Kafka-пути: подтверждение только после
+// сохранения заказа, повторная доставка при ошибке обработки. С health при сбое хранилища
+// сообщение удерживается до его восстановления, не расходуя попытки доставки.
+// Сообщения неизвестной версии схемы публикуются в NATS_QUARANTINE_SUBJECT; если его
+// не захватывает ни один стрим, контроллер не создаётся
+func NewNatsController(cfg config.Nats, decoder codec.Decoder, service service.Service, health Health) (NatsController, error) {
+	conn, err := nats.Connect(cfg.URL, nats.Name("order-service"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	c, err := connect(conn, cfg, decoder, service, health)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func connect(conn *nats.Conn, cfg config.Nats, decoder codec.Decoder, service service.Service, health Health) (*natsController, error) {
+	js, err := jetstream.New(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+	quarantine, err := NewQuarantine(context.Background(), js, cfg.QuarantineSubject)
+	if err != nil {
+		return nil, err
+	}
+	return newController(conn, cfg, decoder, quarantine, service, health)
+}
+
+func newController(conn *nats.Conn, cfg config.Nats, decoder codec.Decoder, quarantine Quarantine, service service.Service, health Health) (*natsController, error) {
+	if quarantine == nil {
+		return nil, errors.New("quarantine is required for NATS ingestion")
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	consumer, err := js.CreateOrUpdateConsumer(context.Background(), cfg.Stream, jetstream.ConsumerConfig{
+		Durable:        cfg.Durable,
+		FilterSubjects: cfg.Subjects,
+		DeliverPolicy:  jetstream.DeliverAllPolicy,
+		AckPolicy:      jetstream.AckExplicitPolicy,
+		AckWait:        cfg.AckWait,
+		MaxDeliver:     cfg.MaxDeliver,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consumer %s on stream %s: %w", cfg.Durable, cfg.Stream, err)
+	}
+
+	messages, err := consumer.Messages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe consumer %s: %w", cfg.Durable, err)
+	}
+
+	return &natsController{
+		conn:       conn,
+		messages:   messages,
+		decoder:    decoder,
+		quarantine: quarantine,
+		service:    service,
+		cfg:        cfg,
+		drainer:    lifecycle.NewDrainer(),
+		health:     health,
+	}, nil
+}
+
+func (c *natsController) Consume(ctx context.Context) error {
+	// fetch отменяется при Shutdown, work — только по ctx или по истечении срока остановки
+	fetch, work, end := c.drainer.Begin(ctx)
+	defer end()
+
+	for {
+		msg, err := c.messages.Next(jetstream.NextContext(fetch))
+		if err != nil {
+			if fetch.Err() != nil || errors.Is(err, jetstream.ErrMsgIteratorClosed) {
+				return nil // Грациозное завершение
+			}
+			log.Printf("Failed to read NATS message: %v", err)
+			continue
+		}
+		for !c.handle(work, msg) {
+			if !c.await(fetch, msg) {
+				return nil
+			}
+		}
+	}
+}
+
+// Shutdown прекращает чтение и ждёт, пока Consume дообработает и подтвердит текущее
+// сообщение; по истечении ctx обработка прерывается. Неподтверждённые сообщения из буфера
+// JetStream доставит повторно после NATS_ACK_WAIT
+func (c *natsController) Shutdown(ctx context.Context) error {
+	return c.drainer.Drain(ctx)
+}
+
+// handle обрабатывает сообщение. Возвращает false, если заказ не сохранён из-за сбоя
+// хранилища и сообщение удерживается для повтора
+func (c *natsController) handle(ctx context.Context, msg jetstream.Msg) bool {
+	message := toMessage(msg)
+
+	order, err := c.decoder.Decode(ctx, message.Value, message.Headers)
+	if errors.Is(err, codec.ErrUnknownVersion) {
+		c.toQuarantine(ctx, msg, message, err)
+		return true
+	}
+	if err != nil {
+		// Повторная доставка не поможет — сообщение снимается с доставки
+		log.Printf("Failed to decode message %s: %v", message, err)
+		if err := msg.TermWithReason(err.Error()); err != nil {
+			log.Printf("Failed to terminate message %s: %v", message, err)
+		}
+		return true
+	}
+
+	if err := c.service.ProcessOrder(ctx, order); err != nil {
+		log.Printf("Failed to process order %s: %v", order.OrderUID, err)
+		if c.health != nil && c.health.Failure(err) && ctx.Err() == nil {
+			return false
+		}
+		if err := msg.NakWithDelay(c.cfg.NakDelay); err != nil {
+			log.Printf("Failed to nak message %s: %v", message, err)
+		}
+		return true
+	}
+
+	// Синхронное подтверждение: аналог коммита смещения в Kafka
+	if err := msg.DoubleAck(ctx); err != nil {
+		log.Printf("Failed to ack message %s: %v", message, err)
+		return true
+	}
+
+	log.Printf("Successfully processed order %s from NATS", order.OrderUID)
+	return true
+}
+
+// await удерживает сообщение, пока предохранитель хранилища не пропустит пробу.
+// Продление срока подтверждения не даёт JetStream доставить сообщение повторно и
+// израсходовать NATS_MAX_DELIVER. При остановке сообщение отдаётся на повтор и
+// возвращается false
+func (c *natsController) await(ctx context.Context, msg jetstream.Msg) bool {
+	ticker := time.NewTicker(probeInterval)
+	defer ticker.Stop()
+	extend := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := msg.Nak(); err != nil {
+				log.Printf("Failed to nak message %s: %v", toMessage(msg), err)
+			}
+			return false
+		case now := <-ticker.C:
+			if c.health.Ready() {
+				return true
+			}
+			if now.Sub(extend) >= c.cfg.AckWait/2 {
+				extend = now
+				if err := msg.InProgress(); err != nil {
+					log.Printf("Failed to extend ack deadline of message %s: %v", toMessage(msg), err)
+				}
+			}
+		}
+	}
+}
+
+// toQuarantine откладывает сообщение и подтверждает его; если карантин недоступен,
+// сообщение отдаётся на повтор
+func (c *natsController) toQuarantine(ctx context.Context, msg jetstream.Msg, message *source.Message, reason error) {
+	if err := c.quarantine.Put(ctx, message, reason); err != nil {
+		log.Printf("Failed to quarantine message %s: %v", message, err)
+		if err := msg.NakWithDelay(c.cfg.NakDelay); err != nil {
+			log.Printf("Failed to nak message %s: %v", message, err)
+		}
+		return
+	}
+	if err := msg.DoubleAck(ctx); err != nil {
+		log.Printf("Failed to ack message %s: %v", message, err)
+	}
+}
+
+func (c *natsController) Close() error {
+	c.messages.Stop()
+	return c.conn.Drain()
+}
+
+// toMessage приводит сообщение JetStream к общему виду: субъект вместо топика,
+// номер в стриме вместо смещения
+func toMessage(msg jetstream.Msg) *source.Message {
+	message := &source.Message{
+		Topic: msg.Subject(),
+		Value: msg.Data(),
+	}
+	if headers := msg.Headers(); len(headers) > 0 {
+		message.Headers = make(map[string]string, len(headers))
+		for key := range headers {
+			message.Headers[key] = headers.Get(key)
+		}
+	}
+	if meta, err := msg.Metadata(); err == nil {
+		message.Offset = int64(meta.Sequence.Stream)
+		message.Timestamp = meta.Timestamp
+	}
+	return message
+}
@@ -0,0 +1,253 @@
+package nats
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "order/config"
+    "order/internal/breaker"
+    "order/internal/codec"
+    "order/internal/entity"
+    "order/internal/service"
+    "order/internal/source"
+    "order/internal/storage"
+    "order/internal/storage/memory"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/nats-io/nats-server/v2/server"
+    "github.com/nats-io/nats.go"
+    "github.com/nats-io/nats.go/jetstream"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+// runServer запускает встроенный NATS-сервер с JetStream и стримом ORDERS
+func runServer(t *testing.T) (*nats.Conn, jetstream.JetStream) {
+    srv, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1, JetStream: true, StoreDir: t.TempDir(), NoLog: true, NoSigs: true})
+    require.NoError(t, err)
+    go srv.Start()
+    require.True(t, srv.ReadyForConnections(5*time.Second))
+    t.Cleanup(srv.Shutdown)
+
+    conn, err := nats.Connect(srv.ClientURL())
+    require.NoError(t, err)
+    t.Cleanup(conn.Close)
+    js, err := jetstream.New(conn)
+    require.NoError(t, err)
+    _, err = js.CreateStream(context.Background(), jetstream.StreamConfig{Name: "ORDERS", Subjects: []string{"orders.>"}})
+    require.NoError(t, err)
+    return conn, js
+}
+
+func testConfig() config.Nats {
+    return config.Nats{Stream: "ORDERS", Subjects: []string{"orders.>"}, Durable: "order-service", AckWait: 5 * time.Second, MaxDeliver: 5, NakDelay: 10 * time.Millisecond, QuarantineSubject: "orders-quarantine"}
+}
+
+func publishOrder(t *testing.T, js jetstream.JetStream, uid string, headers nats.Header) {
+    value, err := json.Marshal(entity.Order{OrderUID: uid, Items: []entity.Item{{ChrtID: 1, Price: entity.NewMoney(500)}}})
+    require.NoError(t, err)
+    _, err = js.PublishMsg(context.Background(), &nats.Msg{Subject: "orders.eu", Data: value, Header: headers})
+    require.NoError(t, err)
+}
+
+// flakyStore отказывает в первых failures сохранениях
+type flakyStore struct {
+    storage.Store
+    mu       sync.Mutex
+    failures int
+    attempts int
+}
+
+func (s *flakyStore) SaveOrder(ctx context.Context, order entity.Order) error {
+    s.mu.Lock()
+    s.attempts++
+    fail := s.attempts <= s.failures
+    s.mu.Unlock()
+    if fail {
+        return errors.New("database is unavailable")
+    }
+    return s.Store.SaveOrder(ctx, order)
+}
+
+type fakeQuarantine struct {
+    mu       sync.Mutex
+    messages []*source.Message
+}
+
+func (q *fakeQuarantine) Put(_ context.Context, msg *source.Message, _ error) error {
+    q.mu.Lock()
+    defer q.mu.Unlock()
+    q.messages = append(q.messages, msg)
+    return nil
+}
+
+func (q *fakeQuarantine) count() int {
+    q.mu.Lock()
+    defer q.mu.Unlock()
+    return len(q.messages)
+}
+
+// startController запускает Consume и возвращает функцию остановки
+func startController(t *testing.T, conn *nats.Conn, store storage.Store, quarantine Quarantine) func() {
+    decoder, err := codec.NewDecoder(codec.FormatJSON, nil, nil)
+    require.NoError(t, err)
+    c, err := newController(conn, testConfig(), decoder, quarantine, service.NewService(store), nil)
+    require.NoError(t, err)
+
+    done := make(chan error, 1)
+    go func() { done <- c.Consume(context.Background()) }()
+    return func() {
+        ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+        defer cancel()
+        require.NoError(t, c.Shutdown(ctx))
+        require.NoError(t, <-done)
+        c.messages.Stop()
+    }
+}
+
+func pending(t *testing.T, js jetstream.JetStream) (int, uint64) {
+    consumer, err := js.Consumer(context.Background(), "ORDERS", "order-service")
+    require.NoError(t, err)
+    info, err := consumer.Info(context.Background())
+    require.NoError(t, err)
+    return info.NumAckPending, info.NumPending
+}
+
+func TestNatsController_Consume(t *testing.T) {
+    conn, js := runServer(t)
+    store := &flakyStore{Store: memory.NewStore(), failures: 1}
+    quarantine := &fakeQuarantine{}
+
+    publishOrder(t, js, "uid-1", nil)
+    _, err := js.Publish(context.Background(), "orders.eu", []byte("not json"))
+    require.NoError(t, err)
+    publishOrder(t, js, "uid-future", nats.Header{codec.HeaderSchemaVersion: []string{"99"}})
+    publishOrder(t, js, "uid-2", nil)
+
+    stop := startController(t, conn, store, quarantine)
+    require.Eventually(t, func() bool {
+        ackPending, numPending := pending(t, js)
+        return ackPending == 0 && numPending == 0
+    }, 10*time.Second, 20*time.Millisecond)
+    stop()
+
+    // uid-1 сохранён после повторной доставки, битое сообщение снято с доставки
+    _, err = store.GetOrder(context.Background(), "uid-1")
+    assert.NoError(t, err)
+    _, err = store.GetOrder(context.Background(), "uid-2")
+    assert.NoError(t, err)
+    assert.Equal(t, 3, store.attempts)
+    require.Equal(t, 1, quarantine.count())
+    assert.Equal(t, "orders.eu", quarantine.messages[0].Topic)
+    assert.Equal(t, int64(3), quarantine.messages[0].Offset)
+}
+
+func TestNatsController_DurableConsumer(t *testing.T) {
+    conn, js := runServer(t)
+    store := &flakyStore{Store: memory.NewStore()}
+
+    publishOrder(t, js, "uid-1", nil)
+    stop := startController(t, conn, store, &fakeQuarantine{})
+    require.Eventually(t, func() bool {
+        _, err := store.GetOrder(context.Background(), "uid-1")
+        return err == nil
+    }, 10*time.Second, 20*time.Millisecond)
+    stop()
+
+    // После перезапуска durable-консюмер продолжает с места остановки
+    publishOrder(t, js, "uid-2", nil)
+    stop = startController(t, conn, store, &fakeQuarantine{})
+    require.Eventually(t, func() bool {
+        _, err := store.GetOrder(context.Background(), "uid-2")
+        return err == nil
+    }, 10*time.Second, 20*time.Millisecond)
+    stop()
+
+    assert.Equal(t, 2, store.attempts)
+}
+
+func TestNatsController_HoldsWhileStorageIsDown(t *testing.T) {
+    conn, js := runServer(t)
+    // Отказов больше, чем NATS_MAX_DELIVER: без удержания сообщение было бы потеряно
+    store := &flakyStore{Store: memory.NewStore(), failures: 8}
+    dbBreaker := breaker.New(breaker.Config{Name: "database", FailureThreshold: 2, OpenTimeout: 10 * time.Millisecond, MaxOpenTimeout: 20 * time.Millisecond})
+
+    publishOrder(t, js, "uid-1", nil)
+
+    decoder, err := codec.NewDecoder(codec.FormatJSON, nil, nil)
+    require.NoError(t, err)
+    c, err := newController(conn, testConfig(), decoder, &fakeQuarantine{}, service.NewService(storage.NewBreakerStore(store, dbBreaker)), dbBreaker)
+    require.NoError(t, err)
+    done := make(chan error, 1)
+    go func() { done <- c.Consume(context.Background()) }()
+
+    require.Eventually(t, func() bool {
+        ackPending, numPending := pending(t, js)
+        return ackPending == 0 && numPending == 0
+    }, 10*time.Second, 20*time.Millisecond)
+
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+    require.NoError(t, c.Shutdown(ctx))
+    require.NoError(t, <-done)
+    c.messages.Stop()
+
+    _, err = store.GetOrder(context.Background(), "uid-1")
+    assert.NoError(t, err)
+    assert.Equal(t, 9, store.attempts)
+
+    // Сообщение доставлено один раз: повторы шли внутри сервиса, а не через JetStream
+    consumer, err := js.Consumer(context.Background(), "ORDERS", "order-service")
+    require.NoError(t, err)
+    info, err := consumer.Info(context.Background())
+    require.NoError(t, err)
+    assert.Equal(t, 0, info.NumRedelivered)
+}
+
+func TestNatsController_Quarantine(t *testing.T) {
+    conn, js := runServer(t)
+    decoder, err := codec.NewDecoder(codec.FormatJSON, nil, nil)
+    require.NoError(t, err)
+    svc := service.NewService(memory.NewStore())
+
+    t.Run("Quarantine is required", func(t *testing.T) {
+        _, err := newController(conn, testConfig(), decoder, nil, svc, nil)
+        assert.ErrorContains(t, err, "quarantine is required")
+    })
+
+    t.Run("Subject without stream", func(t *testing.T) {
+        _, err := connect(conn, testConfig(), decoder, svc, nil)
+        assert.ErrorContains(t, err, "no stream captures quarantine subject orders-quarantine")
+    })
+
+    _, err = js.CreateStream(context.Background(), jetstream.StreamConfig{Name: "ORDERS_QUARANTINE", Subjects: []string{"orders-quarantine"}})
+    require.NoError(t, err)
+    publishOrder(t, js, "uid-future", nats.Header{codec.HeaderSchemaVersion: []string{"99"}})
+
+    c, err := connect(conn, testConfig(), decoder, svc, nil)
+    require.NoError(t, err)
+    done := make(chan error, 1)
+    go func() { done <- c.Consume(context.Background()) }()
+    require.Eventually(t, func() bool {
+        ackPending, numPending := pending(t, js)
+        return ackPending == 0 && numPending == 0
+    }, 10*time.Second, 20*time.Millisecond)
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+    require.NoError(t, c.Shutdown(ctx))
+    require.NoError(t, <-done)
+    c.messages.Stop()
+
+    // Сообщение лежит в карантинном стриме без изменений, с причиной и исходной позицией
+    stream, err := js.Stream(context.Background(), "ORDERS_QUARANTINE")
+    require.NoError(t, err)
+    raw, err := stream.GetMsg(context.Background(), 1)
+    require.NoError(t, err)
+    assert.Contains(t, string(raw.Data), "uid-future")
+    assert.Equal(t, "99", raw.Header.Get(codec.HeaderSchemaVersion))
+    assert.Equal(t, "orders.eu", raw.Header.Get(HeaderOriginalSubject))
+    assert.Equal(t, "1", raw.Header.Get(HeaderOriginalSequence))
+    assert.Contains(t, raw.Header.Get(HeaderQuarantineReason), "unknown schema version")
+}
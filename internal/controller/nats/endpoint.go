@@ -0,0 +1,26 @@
+package nats
+
+import (
+	"context"
+	"order/internal/source"
+)
+
+type NatsController interface {
+	Consume(ctx context.Context) error
+	// Shutdown прекращает чтение и ждёт завершения текущей обработки не дольше ctx
+	Shutdown(ctx context.Context) error
+	Close() error
+}
+
+// Quarantine откладывает сообщения неизвестной версии схемы (см. NewQuarantine)
+type Quarantine interface {
+	Put(ctx context.Context, msg *source.Message, reason error) error
+}
+
+// Health — состояние хранилища (см. breaker.Breaker)
+type Health interface {
+	// Ready сообщает, можно ли сейчас обращаться к хранилищу
+	Ready() bool
+	// Failure отличает сбой хранилища от ошибки конкретного заказа
+	Failure(err error) bool
+}
@@ -0,0 +1,56 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"order/internal/source"
+	"strconv"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Заголовки, которые добавляются к сообщению при отправке в карантин
+const (
+	HeaderQuarantineReason = "quarantine-reason"
+	HeaderOriginalSubject  = "original-subject"
+	HeaderOriginalSequence = "original-sequence"
+)
+
+type jetStreamQuarantine struct {
+	js      jetstream.JetStream
+	subject string
+}
+
+// NewQuarantine создаёт карантин, который публикует сообщения в subject без изменений
+// (значение и заголовки сохраняются). Если ни один стрим не захватывает subject,
+// возвращается ошибка: без хранилища отложенные сообщения были бы потеряны
+func NewQuarantine(ctx context.Context, js jetstream.JetStream, subject string) (Quarantine, error) {
+	stream, err := js.StreamNameBySubject(ctx, subject)
+	if err != nil {
+		return nil, fmt.Errorf("no stream captures quarantine subject %s: %w", subject, err)
+	}
+	log.Printf("NATS quarantine subject %s is stored in stream %s", subject, stream)
+	return &jetStreamQuarantine{js: js, subject: subject}, nil
+}
+
+func (q *jetStreamQuarantine) Put(ctx context.Context, msg *source.Message, reason error) error {
+	out := nats.NewMsg(q.subject)
+	out.Data = msg.Value
+	for key, value := range msg.Headers {
+		out.Header.Set(key, value)
+	}
+	out.Header.Set(HeaderQuarantineReason, reason.Error())
+	out.Header.Set(HeaderOriginalSubject, msg.Topic)
+	out.Header.Set(HeaderOriginalSequence, strconv.FormatInt(msg.Offset, 10))
+
+	// Повторная доставка того же сообщения не создаёт дубликат в карантине
+	// (в пределах окна дедупликации стрима)
+	id := fmt.Sprintf("%s@%d", msg.Topic, msg.Offset)
+	if _, err := q.js.PublishMsg(ctx, out, jetstream.WithMsgID(id)); err != nil {
+		return err
+	}
+	log.Printf("Message %s moved to quarantine subject %s: %v", msg, q.subject, reason)
+	return nil
+}
@@ -1,21 +1,23 @@
 package entity
 
+import "time"
+
 // Модель данных (из JSON)
 type Order struct {
-	OrderUID          string   `json:"order_uid"`
-	TrackNumber       string   `json:"track_number"`
-	Entry             string   `json:"entry"`
-	Delivery          Delivery `json:"delivery"`
-	Payment           Payment  `json:"payment"`
-	Items             []Item   `json:"items"`
-	Locale            string   `json:"locale"`
-	InternalSignature string   `json:"internal_signature"`
-	CustomerID        string   `json:"customer_id"`
-	DeliveryService   string   `json:"delivery_service"`
-	Shardkey          string   `json:"shardkey"`
-	SmID              int      `json:"sm_id"`
-	DateCreated       string   `json:"date_created"`
-	OofShard          string   `json:"oof_shard"`
+	OrderUID          string    `json:"order_uid"`
+	TrackNumber       string    `json:"track_number"`
+	Entry             string    `json:"entry"`
+	Delivery          Delivery  `json:"delivery"`
+	Payment           Payment   `json:"payment"`
+	Items             []Item    `json:"items"`
+	Locale            string    `json:"locale"`
+	InternalSignature string    `json:"internal_signature"`
+	CustomerID        string    `json:"customer_id"`
+	DeliveryService   string    `json:"delivery_service"`
+	Shardkey          string    `json:"shardkey"`
+	SmID              int       `json:"sm_id"`
+	DateCreated       time.Time `json:"date_created"` // в JSON — RFC 3339 (см. time.go)
+	OofShard          string    `json:"oof_shard"`
 }
 
 type Delivery struct {
@@ -29,27 +31,27 @@ type Delivery struct {
 }
 
 type Payment struct {
-	Transaction  string `json:"transaction"`
-	RequestID    string `json:"request_id"`
-	Currency     string `json:"currency"`
-	Provider     string `json:"provider"`
-	Amount       int    `json:"amount"`
-	PaymentDt    int64  `json:"payment_dt"`
-	Bank         string `json:"bank"`
-	DeliveryCost int    `json:"delivery_cost"`
-	GoodsTotal   int    `json:"goods_total"`
-	CustomFee    int    `json:"custom_fee"`
+	Transaction  string    `json:"transaction"`
+	RequestID    string    `json:"request_id"`
+	Currency     string    `json:"currency"`
+	Provider     string    `json:"provider"`
+	Amount       Money     `json:"amount"`
+	PaymentDt    time.Time `json:"payment_dt"` // на входе также Unix-время в секундах
+	Bank         string    `json:"bank"`
+	DeliveryCost Money     `json:"delivery_cost"`
+	GoodsTotal   Money     `json:"goods_total"`
+	CustomFee    Money     `json:"custom_fee"`
 }
 
 type Item struct {
 	ChrtID      int64  `json:"chrt_id"`
 	TrackNumber string `json:"track_number"`
-	Price       int64  `json:"price"`
+	Price       Money  `json:"price"`
 	Rid         string `json:"rid"`
 	Name        string `json:"name"`
 	Sale        int64  `json:"sale"`
 	Size        string `json:"size"`
-	TotalPrice  int64  `json:"total_price"`
+	TotalPrice  Money  `json:"total_price"`
 	NmID        int64  `json:"nm_id"`
 	Brand       string `json:"brand"`
 	Status      int64  `json:"status"`
@@ -0,0 +1,285 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MoneyScale — знаков после запятой у Money: наибольший показатель валют ISO 4217 (CLF, UYW)
+const MoneyScale = 4
+
+// moneyOne — одна основная единица валюты в долях Money
+const moneyOne = 10_000
+
+var (
+	ErrInvalidMoney    = errors.New("invalid amount")
+	ErrMoneyOverflow   = errors.New("amount out of range")
+	ErrMoneyPrecision  = errors.New("amount is more precise than the currency minor unit")
+	ErrUnknownCurrency = errors.New("unknown currency")
+)
+
+// Money — точная денежная сумма в основных единицах валюты (долларах, рублях) с точностью
+// до 1/10^MoneyScale. Валюта задаётся отдельно (Payment.Currency). В JSON — число, как
+// прежние целые поля: 1817 или 18.17; в БД — NUMERIC(19, 4)
+type Money struct {
+	scaled int64 // сумма × 10^MoneyScale
+}
+
+// NewMoney возвращает сумму из целого числа основных единиц. Паникует, если сумма больше
+// ±922 трлн, поэтому годится для констант; внешние данные разбирает ParseMoney
+func NewMoney(units int64) Money {
+	m, err := moneyFromUnits(units)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func moneyFromUnits(units int64) (Money, error) {
+	if units > math.MaxInt64/moneyOne || units < math.MinInt64/moneyOne {
+		return Money{}, fmt.Errorf("%w: %d", ErrMoneyOverflow, units)
+	}
+	return Money{scaled: units * moneyOne}, nil
+}
+
+// MoneyFromScaled возвращает сумму из долей 1/10^MoneyScale
+func MoneyFromScaled(scaled int64) Money {
+	return Money{scaled: scaled}
+}
+
+// MoneyFromMinor возвращает сумму из минорных единиц валюты (центов, копеек; у JPY — иен)
+func MoneyFromMinor(minor int64, currency string) (Money, error) {
+	exp, err := CurrencyExponent(currency)
+	if err != nil {
+		return Money{}, err
+	}
+	factor := pow10(MoneyScale - exp)
+	if minor > math.MaxInt64/factor || minor < math.MinInt64/factor {
+		return Money{}, fmt.Errorf("%w: %d %s minor units", ErrMoneyOverflow, minor, currency)
+	}
+	return Money{scaled: minor * factor}, nil
+}
+
+// ParseMoney разбирает десятичную запись: 1817, -5, 18.17, 0.0001. Лишние нули после
+// запятой допустимы, значащих знаков — не больше MoneyScale; экспонента не поддерживается
+func ParseMoney(s string) (Money, error) {
+	digits := strings.TrimLeft(s, "+-")
+	if len(s)-len(digits) > 1 {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+	whole, frac, hasPoint := strings.Cut(digits, ".")
+	if whole == "" || (hasPoint && frac == "") || !isDigits(whole) || !isDigits(frac) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+	if len(frac) > MoneyScale {
+		if strings.Trim(frac[MoneyScale:], "0") != "" {
+			return Money{}, fmt.Errorf("%w: %q has more than %d decimal places", ErrInvalidMoney, s, MoneyScale)
+		}
+		frac = frac[:MoneyScale]
+	}
+	frac += strings.Repeat("0", MoneyScale-len(frac))
+
+	scaled, err := strconv.ParseInt(s[:len(s)-len(digits)]+whole+frac, 10, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		return Money{}, fmt.Errorf("%w: %q", ErrMoneyOverflow, s)
+	}
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+	return Money{scaled: scaled}, nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Scaled возвращает сумму в долях 1/10^MoneyScale
+func (m Money) Scaled() int64 {
+	return m.scaled
+}
+
+// Minor возвращает сумму в минорных единицах валюты. Ошибка ErrMoneyPrecision — сумма
+// точнее минорной единицы (например, 0.5 JPY или 1.005 USD)
+func (m Money) Minor(currency string) (int64, error) {
+	exp, err := CurrencyExponent(currency)
+	if err != nil {
+		return 0, err
+	}
+	factor := pow10(MoneyScale - exp)
+	if m.scaled%factor != 0 {
+		return 0, fmt.Errorf("%w: %s %s", ErrMoneyPrecision, m, currency)
+	}
+	return m.scaled / factor, nil
+}
+
+// Add возвращает m + n
+func (m Money) Add(n Money) (Money, error) {
+	sum := m.scaled + n.scaled
+	if (m.scaled > 0 && n.scaled > 0 && sum < 0) || (m.scaled < 0 && n.scaled < 0 && sum >= 0) {
+		return Money{}, fmt.Errorf("%w: %s + %s", ErrMoneyOverflow, m, n)
+	}
+	return Money{scaled: sum}, nil
+}
+
+// Cmp возвращает -1, 0 или 1, если m меньше, равна или больше n
+func (m Money) Cmp(n Money) int {
+	switch {
+	case m.scaled < n.scaled:
+		return -1
+	case m.scaled > n.scaled:
+		return 1
+	}
+	return 0
+}
+
+func (m Money) IsZero() bool {
+	return m.scaled == 0
+}
+
+// String возвращает десятичную запись без лишних нулей: 1817, 18.17, -0.5
+func (m Money) String() string {
+	abs := uint64(m.scaled)
+	sign := ""
+	if m.scaled < 0 {
+		abs = -abs
+		sign = "-"
+	}
+	whole := strconv.FormatUint(abs/moneyOne, 10)
+	frac := abs % moneyOne
+	if frac == 0 {
+		return sign + whole
+	}
+	digits := strconv.FormatUint(frac+moneyOne, 10)[1:] // с ведущими нулями
+	return sign + whole + "." + strings.TrimRight(digits, "0")
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON принимает число или строку с числом; null оставляет сумму без изменений
+func (m *Money) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if text == "null" {
+		return nil
+	}
+	if strings.HasPrefix(text, `"`) {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+	}
+	parsed, err := ParseMoney(text)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Value передаёт сумму в БД текстом: NUMERIC разбирает его без потерь
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Scan читает NUMERIC (текстом) или целое число; NULL — ноль
+func (m *Money) Scan(src any) error {
+	var err error
+	switch v := src.(type) {
+	case nil:
+		*m = Money{}
+	case int64:
+		*m, err = moneyFromUnits(v)
+	case float64:
+		*m, err = ParseMoney(strconv.FormatFloat(v, 'f', -1, 64))
+	case []byte:
+		*m, err = ParseMoney(string(v))
+	case string:
+		*m, err = ParseMoney(v)
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrInvalidMoney, src)
+	}
+	return err
+}
+
+func pow10(n int) int64 {
+	p := int64(1)
+	for range n {
+		p *= 10
+	}
+	return p
+}
+
+// CurrencyExponent возвращает число знаков минорной единицы валюты по ISO 4217 (USD — 2,
+// JPY — 0, KWD — 3). Код — три заглавные буквы
+func CurrencyExponent(code string) (int, error) {
+	exp, ok := currencyExponents[code]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownCurrency, code)
+	}
+	return exp, nil
+}
+
+// currencyExponents — действующие коды ISO 4217. Коды без минорной единицы (драгметаллы,
+// XDR, XXX) не поддерживаются: суммы в них не переводятся в минорные единицы.
+// Тот же список заносит в таблицу currencies миграция 000004_money
+var currencyExponents = func() map[string]int {
+	byExponent := map[int]string{
+		0: "BIF CLP DJF GNF ISK JPY KMF KRW PYG RWF UGX UYI VND VUV XAF XOF XPF",
+		2: "AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BMD BND BOB BOV BRL BSD " +
+			"BTN BWP BYN BZD CAD CDF CHE CHF CHW CNY COP COU CRC CUP CVE CZK DKK DOP DZD EGP " +
+			"ERN ETB EUR FJD FKP GBP GEL GHS GIP GMD GTQ GYD HKD HNL HTG HUF IDR ILS INR IRR " +
+			"JMD KES KGS KHR KPW KYD KZT LAK LBP LKR LRD LSL MAD MDL MGA MKD MMK MNT MOP MRU " +
+			"MUR MVR MWK MXN MXV MYR MZN NAD NGN NIO NOK NPR NZD PAB PEN PGK PHP PKR PLN QAR " +
+			"RON RSD RUB SAR SBD SCR SDG SEK SGD SHP SLE SOS SRD SSP STN SVC SYP SZL THB TJS " +
+			"TMT TOP TRY TTD TWD TZS UAH USD USN UZS VED VES WST XCD XCG YER ZAR ZMW ZWG",
+		3: "BHD IQD JOD KWD LYD OMR TND",
+		4: "CLF UYW",
+	}
+	exponents := make(map[string]int)
+	for exp, codes := range byExponent {
+		for _, code := range strings.Fields(codes) {
+			exponents[code] = exp
+		}
+	}
+	return exponents
+}()
+
+// CheckMoney проверяет, что валюта оплаты есть в ISO 4217, а суммы оплаты и товаров (они
+// в валюте оплаты) не точнее её минорной единицы. Заказ без валюты не проверяется
+func (o Order) CheckMoney() error {
+	currency := o.Payment.Currency
+	if currency == "" {
+		return nil
+	}
+	if _, err := CurrencyExponent(currency); err != nil {
+		return err
+	}
+	check := func(name string, m Money) error {
+		if _, err := m.Minor(currency); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		return nil
+	}
+	errs := []error{
+		check("payment.amount", o.Payment.Amount),
+		check("payment.delivery_cost", o.Payment.DeliveryCost),
+		check("payment.goods_total", o.Payment.GoodsTotal),
+		check("payment.custom_fee", o.Payment.CustomFee),
+	}
+	for i, item := range o.Items {
+		errs = append(errs,
+			check(fmt.Sprintf("items[%d].price", i), item.Price),
+			check(fmt.Sprintf("items[%d].total_price", i), item.TotalPrice))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,129 @@
+package entity
+
+import (
+    "encoding/json"
+    "math"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func TestParseMoney(t *testing.T) {
+    tests := []struct {
+        in   string
+        want string
+        err  error
+    }{
+        {"1817", "1817", nil},
+        {"18.17", "18.17", nil},
+        {"-0.5", "-0.5", nil},
+        {"+7.000000", "7", nil},
+        {"0.0001", "0.0001", nil},
+        {"922337203685477.5807", "922337203685477.5807", nil},
+        {"922337203685477.5808", "", ErrMoneyOverflow},
+        {"0.00001", "", ErrInvalidMoney},
+        {"1e3", "", ErrInvalidMoney},
+        {"1.", "", ErrInvalidMoney},
+        {".5", "", ErrInvalidMoney},
+        {"--1", "", ErrInvalidMoney},
+        {"", "", ErrInvalidMoney},
+    }
+    for _, tt := range tests {
+        m, err := ParseMoney(tt.in)
+        if tt.err != nil {
+            assert.ErrorIs(t, err, tt.err, tt.in)
+            continue
+        }
+        require.NoError(t, err, tt.in)
+        assert.Equal(t, tt.want, m.String(), tt.in)
+    }
+    assert.Equal(t, "-922337203685477.5808", MoneyFromScaled(math.MinInt64).String())
+}
+
+func TestMoney_Minor(t *testing.T) {
+    price, err := ParseMoney("18.17")
+    require.NoError(t, err)
+
+    minor, err := price.Minor("USD")
+    assert.NoError(t, err)
+    assert.Equal(t, int64(1817), minor)
+
+    minor, err = price.Minor("KWD")
+    assert.NoError(t, err)
+    assert.Equal(t, int64(18170), minor)
+
+    _, err = price.Minor("JPY")
+    assert.ErrorIs(t, err, ErrMoneyPrecision)
+    _, err = price.Minor("usd")
+    assert.ErrorIs(t, err, ErrUnknownCurrency)
+
+    m, err := MoneyFromMinor(1817, "USD")
+    assert.NoError(t, err)
+    assert.Equal(t, price, m)
+    _, err = MoneyFromMinor(math.MaxInt64, "JPY")
+    assert.ErrorIs(t, err, ErrMoneyOverflow)
+}
+
+func TestMoney_Add(t *testing.T) {
+    sum, err := NewMoney(10).Add(MoneyFromScaled(5))
+    assert.NoError(t, err)
+    assert.Equal(t, "10.0005", sum.String())
+    assert.Equal(t, 1, sum.Cmp(NewMoney(10)))
+
+    _, err = MoneyFromScaled(math.MaxInt64).Add(MoneyFromScaled(1))
+    assert.ErrorIs(t, err, ErrMoneyOverflow)
+    assert.Panics(t, func() { NewMoney(math.MaxInt64) })
+}
+
+func TestMoney_JSON(t *testing.T) {
+    // Прежний формат — целые числа — читается и пишется без изменений
+    in := `{"amount":1817,"delivery_cost":"1500.50","goods_total":0.25,"custom_fee":null}`
+    var payment Payment
+    require.NoError(t, json.Unmarshal([]byte(in), &payment))
+    assert.Equal(t, NewMoney(1817), payment.Amount)
+    assert.Equal(t, "1500.5", payment.DeliveryCost.String())
+    assert.Equal(t, MoneyFromScaled(2500), payment.GoodsTotal)
+    assert.True(t, payment.CustomFee.IsZero())
+
+    out, err := json.Marshal(Item{Price: NewMoney(453), TotalPrice: MoneyFromScaled(3_171_500)})
+    require.NoError(t, err)
+    assert.Contains(t, string(out), `"price":453,`)
+    assert.Contains(t, string(out), `"total_price":317.15,`)
+
+    assert.Error(t, json.Unmarshal([]byte(`{"amount":true}`), &payment))
+}
+
+func TestMoney_Scan(t *testing.T) {
+    var m Money
+    assert.NoError(t, m.Scan([]byte("317.1500")))
+    assert.Equal(t, "317.15", m.String())
+    assert.NoError(t, m.Scan(int64(1817)))
+    assert.Equal(t, NewMoney(1817), m)
+    assert.NoError(t, m.Scan(nil))
+    assert.True(t, m.IsZero())
+
+    value, err := MoneyFromScaled(-5).Value()
+    assert.NoError(t, err)
+    assert.Equal(t, "-0.0005", value)
+}
+
+func TestOrder_CheckMoney(t *testing.T) {
+    order := Order{
+        Payment: Payment{Currency: "JPY", Amount: NewMoney(1817)},
+        Items:   []Item{{Price: NewMoney(453), TotalPrice: NewMoney(317)}},
+    }
+    assert.NoError(t, order.CheckMoney())
+
+    order.Items[0].TotalPrice = MoneyFromScaled(3_171_500)
+    err := order.CheckMoney()
+    assert.ErrorIs(t, err, ErrMoneyPrecision)
+    assert.ErrorContains(t, err, "items[0].total_price")
+
+    order.Payment.Currency = "XYZ"
+    assert.ErrorIs(t, order.CheckMoney(), ErrUnknownCurrency)
+
+    // Валюта не указана — суммы не с чем сверять
+    order.Payment.Currency = ""
+    assert.NoError(t, order.CheckMoney())
+}
@@ -0,0 +1,98 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// RateScale — знаков после запятой у курса валют в БД (NUMERIC(30, 12))
+const RateScale = 12
+
+// ErrInvalidRate возвращается для неположительного или нечислового курса и курса валюты к себе
+var ErrInvalidRate = errors.New("invalid exchange rate")
+
+// ExchangeRate — курс валют на день: одна единица Base стоит Rate единиц Currency
+type ExchangeRate struct {
+	Day      time.Time // полночь UTC
+	Base     string
+	Currency string
+	Rate     *big.Rat
+}
+
+// NewExchangeRate проверяет коды валют (ISO 4217) и курс, день приводит к полуночи UTC
+func NewExchangeRate(day time.Time, base, currency, rate string) (ExchangeRate, error) {
+	if _, err := CurrencyExponent(base); err != nil {
+		return ExchangeRate{}, err
+	}
+	if _, err := CurrencyExponent(currency); err != nil {
+		return ExchangeRate{}, err
+	}
+	if base == currency {
+		return ExchangeRate{}, fmt.Errorf("%w: %s to itself", ErrInvalidRate, base)
+	}
+	r, err := ParseRate(rate)
+	if err != nil {
+		return ExchangeRate{}, err
+	}
+	return ExchangeRate{Day: Day(day), Base: base, Currency: currency, Rate: r}, nil
+}
+
+// ParseRate разбирает положительную десятичную запись курса: 1.0876, 97, 0.000123.
+// Значащих знаков после запятой — не больше RateScale; дроби и экспонента не поддерживаются
+func ParseRate(s string) (*big.Rat, error) {
+	whole, frac, hasPoint := strings.Cut(s, ".")
+	if whole == "" || (hasPoint && frac == "") || !isDigits(whole) || !isDigits(frac) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRate, s)
+	}
+	if len(frac) > RateScale && strings.Trim(frac[RateScale:], "0") != "" {
+		return nil, fmt.Errorf("%w: %q has more than %d decimal places", ErrInvalidRate, s, RateScale)
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok || r.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRate, s)
+	}
+	return r, nil
+}
+
+// FormatRate возвращает десятичную запись курса без лишних нулей, с точностью RateScale
+func FormatRate(r *big.Rat) string {
+	text := r.FloatString(RateScale)
+	text = strings.TrimRight(text, "0")
+	return strings.TrimSuffix(text, ".")
+}
+
+// Day возвращает полночь UTC дня, на который приходится t
+func Day(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Convert умножает сумму на курс и округляет до минорной единицы валюты currency
+// (половина — от нуля): 10.00 EUR по курсу 1.08765 — 10.88 USD
+func (m Money) Convert(rate *big.Rat, currency string) (Money, error) {
+	exp, err := CurrencyExponent(currency)
+	if err != nil {
+		return Money{}, err
+	}
+	// Результат в минорных единицах валюты: scaled × rate / 10^(MoneyScale-exp)
+	factor := big.NewInt(pow10(MoneyScale - exp))
+	minor := new(big.Rat).Mul(new(big.Rat).SetInt64(m.scaled), rate)
+	minor.Quo(minor, new(big.Rat).SetInt(factor))
+
+	num, den := minor.Num(), minor.Denom()
+	rounded := new(big.Int).Abs(num)
+	rounded.Mul(rounded, big.NewInt(2)).Add(rounded, den)
+	rounded.Quo(rounded, new(big.Int).Mul(den, big.NewInt(2)))
+	if num.Sign() < 0 {
+		rounded.Neg(rounded)
+	}
+
+	scaled := rounded.Mul(rounded, factor)
+	if !scaled.IsInt64() {
+		return Money{}, fmt.Errorf("%w: %s × %s", ErrMoneyOverflow, m, FormatRate(rate))
+	}
+	return Money{scaled: scaled.Int64()}, nil
+}
@@ -0,0 +1,80 @@
+package entity
+
+import (
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func TestParseRate(t *testing.T) {
+    tests := []struct {
+        in   string
+        want string
+        err  error
+    }{
+        {"1.0876", "1.0876", nil},
+        {"97", "97", nil},
+        {"0.000000000001", "0.000000000001", nil},
+        {"1.50000000000000", "1.5", nil},
+        {"0.0000000000001", "", ErrInvalidRate},
+        {"0", "", ErrInvalidRate},
+        {"-1.2", "", ErrInvalidRate},
+        {"1/3", "", ErrInvalidRate},
+        {"1e3", "", ErrInvalidRate},
+        {"", "", ErrInvalidRate},
+    }
+    for _, tt := range tests {
+        r, err := ParseRate(tt.in)
+        if tt.err != nil {
+            assert.ErrorIs(t, err, tt.err, tt.in)
+            continue
+        }
+        require.NoError(t, err, tt.in)
+        assert.Equal(t, tt.want, FormatRate(r), tt.in)
+    }
+}
+
+func TestNewExchangeRate(t *testing.T) {
+    day := time.Date(2024, 3, 1, 23, 30, 0, 0, time.FixedZone("", -2*3600))
+    rate, err := NewExchangeRate(day, "EUR", "USD", "1.0876")
+    require.NoError(t, err)
+    assert.Equal(t, time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), rate.Day)
+
+    _, err = NewExchangeRate(day, "EUR", "EUR", "1")
+    assert.ErrorIs(t, err, ErrInvalidRate)
+    _, err = NewExchangeRate(day, "EUR", "XYZ", "1")
+    assert.ErrorIs(t, err, ErrUnknownCurrency)
+}
+
+func TestMoney_Convert(t *testing.T) {
+    tests := []struct {
+        amount   string
+        rate     string
+        currency string
+        want     string
+    }{
+        {"10", "1.08765", "USD", "10.88"},
+        {"10", "1.0865", "USD", "10.87"},   // 10.865 — половина округляется от нуля
+        {"-10", "1.0865", "USD", "-10.87"},
+        {"1817", "161.23", "JPY", "292955"},
+        {"2.5", "1", "JPY", "3"},
+        {"1.2345", "1", "KWD", "1.235"},
+        {"0", "97.5", "RUB", "0"},
+    }
+    for _, tt := range tests {
+        amount, err := ParseMoney(tt.amount)
+        require.NoError(t, err)
+        rate, err := ParseRate(tt.rate)
+        require.NoError(t, err)
+
+        converted, err := amount.Convert(rate, tt.currency)
+        require.NoError(t, err)
+        assert.Equal(t, tt.want, converted.String(), "%s × %s %s", tt.amount, tt.rate, tt.currency)
+    }
+
+    huge, _ := ParseRate("1000000")
+    _, err := NewMoney(100_000_000_000).Convert(huge, "USD")
+    assert.ErrorIs(t, err, ErrMoneyOverflow)
+}
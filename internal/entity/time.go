@@ -0,0 +1,142 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidTime возвращается ParseTime для строки, не похожей на RFC 3339
+var ErrInvalidTime = errors.New("invalid time")
+
+// timeLayouts — варианты RFC 3339, которые присылают источники. Доли секунды разбираются
+// без указания в макете; время без часового пояса считается UTC
+var timeLayouts = []string{
+	time.RFC3339,               // 2021-11-26T06:22:19Z, 2021-11-26T06:22:19.123+03:00
+	"2006-01-02T15:04:05Z0700", // +0300
+	"2006-01-02T15:04:05Z07",   // +03
+	"2006-01-02T15:04:05",      // без часового пояса
+	"2006-01-02T15:04Z07:00",   // без секунд
+	"2006-01-02T15:04",
+	time.DateOnly,
+}
+
+// ParseTime разбирает время в вариантах RFC 3339: с T, пробелом или строчной t между датой
+// и временем, с долями секунды или без, с поясом Z, +03:00, +0300, +03 или без пояса (UTC),
+// а также одну дату. Результат — в UTC
+func ParseTime(s string) (time.Time, error) {
+	text := strings.TrimSpace(s)
+	if len(text) > len(time.DateOnly) && (text[10] == ' ' || text[10] == 't') {
+		text = text[:10] + "T" + text[11:]
+	}
+	if strings.HasSuffix(text, "z") {
+		text = text[:len(text)-1] + "Z"
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, text); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidTime, s)
+}
+
+// FormatTime возвращает время в RFC 3339 в UTC (доли секунды — только ненулевые);
+// нулевое время — пустая строка
+func FormatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// jsonTime — время в JSON заказа. Пишется строкой RFC 3339 в UTC, нулевое — null.
+// Читается строка в любом варианте ParseTime, целое число — Unix-время в секундах
+// (так приходит payment_dt); null, пустая строка и 0 — нулевое время
+type jsonTime time.Time
+
+func (t jsonTime) MarshalJSON() ([]byte, error) {
+	if time.Time(t).IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(FormatTime(time.Time(t)))
+}
+
+func (t *jsonTime) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	switch {
+	case text == "null":
+		*t = jsonTime{}
+		return nil
+	case strings.HasPrefix(text, `"`):
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		if text == "" {
+			*t = jsonTime{}
+			return nil
+		}
+		parsed, err := ParseTime(text)
+		if err != nil {
+			return err
+		}
+		*t = jsonTime(parsed)
+		return nil
+	}
+	sec, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidTime, text)
+	}
+	if sec == 0 {
+		*t = jsonTime{}
+		return nil
+	}
+	*t = jsonTime(time.Unix(sec, 0).UTC())
+	return nil
+}
+
+// MarshalJSON и UnmarshalJSON заказа и оплаты подменяют поля времени на jsonTime
+
+func (o Order) MarshalJSON() ([]byte, error) {
+	type plain Order
+	return json.Marshal(struct {
+		plain
+		DateCreated jsonTime `json:"date_created"`
+	}{plain(o), jsonTime(o.DateCreated)})
+}
+
+func (o *Order) UnmarshalJSON(data []byte) error {
+	type plain Order
+	aux := struct {
+		*plain
+		DateCreated jsonTime `json:"date_created"`
+	}{plain: (*plain)(o), DateCreated: jsonTime(o.DateCreated)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	o.DateCreated = time.Time(aux.DateCreated)
+	return nil
+}
+
+func (p Payment) MarshalJSON() ([]byte, error) {
+	type plain Payment
+	return json.Marshal(struct {
+		plain
+		PaymentDt jsonTime `json:"payment_dt"`
+	}{plain(p), jsonTime(p.PaymentDt)})
+}
+
+func (p *Payment) UnmarshalJSON(data []byte) error {
+	type plain Payment
+	aux := struct {
+		*plain
+		PaymentDt jsonTime `json:"payment_dt"`
+	}{plain: (*plain)(p), PaymentDt: jsonTime(p.PaymentDt)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	p.PaymentDt = time.Time(aux.PaymentDt)
+	return nil
+}
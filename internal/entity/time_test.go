@@ -0,0 +1,76 @@
+package entity
+
+import (
+    "encoding/json"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func TestParseTime(t *testing.T) {
+    want := time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC)
+    for _, in := range []string{
+        "2021-11-26T06:22:19Z",
+        "2021-11-26T09:22:19+03:00",
+        "2021-11-26T09:22:19+0300",
+        "2021-11-26T09:22:19+03",
+        "2021-11-26 06:22:19Z",
+        "2021-11-26t06:22:19z",
+        "2021-11-26T06:22:19",
+        " 2021-11-26 06:22:19 ",
+    } {
+        got, err := ParseTime(in)
+        require.NoError(t, err, in)
+        assert.Equal(t, want, got, in)
+        assert.Equal(t, time.UTC, got.Location(), in)
+    }
+
+    got, err := ParseTime("2021-11-26T06:22:19.123456Z")
+    assert.NoError(t, err)
+    assert.Equal(t, 123456000, got.Nanosecond())
+    got, err = ParseTime("2021-11-26")
+    assert.NoError(t, err)
+    assert.Equal(t, time.Date(2021, 11, 26, 0, 0, 0, 0, time.UTC), got)
+
+    for _, in := range []string{"", "26.11.2021", "2021-11-26T25:00:00Z", "1637907739"} {
+        _, err := ParseTime(in)
+        assert.ErrorIs(t, err, ErrInvalidTime, in)
+    }
+}
+
+func TestOrder_JSONTime(t *testing.T) {
+    // date_created — RFC 3339 с поясом, payment_dt — Unix-время, как их присылают источники
+    in := `{"order_uid":"uid-1","date_created":"2021-11-26T09:22:19+03:00",` +
+        `"payment":{"amount":1817,"payment_dt":1637907739},"items":[]}`
+    var order Order
+    require.NoError(t, json.Unmarshal([]byte(in), &order))
+    assert.Equal(t, "uid-1", order.OrderUID)
+    assert.Equal(t, time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC), order.DateCreated)
+    assert.Equal(t, time.Unix(1637907739, 0).UTC(), order.Payment.PaymentDt)
+    assert.Equal(t, NewMoney(1817), order.Payment.Amount)
+
+    // На выходе оба поля — RFC 3339 в UTC
+    order.DateCreated = order.DateCreated.In(time.FixedZone("MSK", 3*60*60))
+    out, err := json.Marshal(order)
+    require.NoError(t, err)
+    assert.Contains(t, string(out), `"date_created":"2021-11-26T06:22:19Z"`)
+    assert.Contains(t, string(out), `"payment_dt":"2021-11-26T06:22:19Z"`)
+
+    var back Order
+    require.NoError(t, json.Unmarshal(out, &back))
+    assert.True(t, order.DateCreated.Equal(back.DateCreated))
+    assert.Equal(t, order.Payment, back.Payment)
+
+    // Пустые значения — нулевое время, которое пишется как null
+    require.NoError(t, json.Unmarshal([]byte(`{"date_created":"","payment":{"payment_dt":0}}`), &order))
+    assert.True(t, order.DateCreated.IsZero())
+    assert.True(t, order.Payment.PaymentDt.IsZero())
+    out, err = json.Marshal(order)
+    require.NoError(t, err)
+    assert.Contains(t, string(out), `"date_created":null`)
+
+    err = json.Unmarshal([]byte(`{"date_created":"yesterday"}`), &order)
+    assert.ErrorIs(t, err, ErrInvalidTime)
+}
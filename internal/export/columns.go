@@ -0,0 +1,158 @@
+package export
+
+import (
+	"order/internal/entity"
+	"slices"
+	"time"
+)
+
+// kind — тип значения колонки; от него зависят JSON-представление и тип в Parquet
+type kind int
+
+const (
+	kindString kind = iota
+	kindInt
+	kindMoney // entity.Money: число в JSON и CSV, DECIMAL(18, 4) в Parquet
+	kindTime  // time.Time: RFC 3339 в UTC, TIMESTAMP_MICROS в Parquet; нулевое время пусто
+)
+
+// column — колонка плоской выгрузки. order и item возвращают указатель на поле (*string, *int,
+// *int64, *entity.Money или *time.Time), через который значение и читается при выгрузке, и записывается при импорте.
+// item не nil у колонок товара: в режиме RowsPerOrder они недоступны
+type column struct {
+	name  string
+	kind  kind
+	order func(o *entity.Order) any
+	item  func(i *entity.Item) any
+}
+
+func orderColumn(name string, k kind, field func(o *entity.Order) any) column {
+	return column{name: name, kind: k, order: field}
+}
+
+func itemColumn(name string, k kind, field func(i *entity.Item) any) column {
+	return column{name: name, kind: k, item: field}
+}
+
+// columns — все колонки выгрузки в порядке по умолчанию
+var columns = []column{
+	orderColumn("order_uid", kindString, func(o *entity.Order) any { return &o.OrderUID }),
+	orderColumn("track_number", kindString, func(o *entity.Order) any { return &o.TrackNumber }),
+	orderColumn("entry", kindString, func(o *entity.Order) any { return &o.Entry }),
+	orderColumn("locale", kindString, func(o *entity.Order) any { return &o.Locale }),
+	orderColumn("internal_signature", kindString, func(o *entity.Order) any { return &o.InternalSignature }),
+	orderColumn("customer_id", kindString, func(o *entity.Order) any { return &o.CustomerID }),
+	orderColumn("delivery_service", kindString, func(o *entity.Order) any { return &o.DeliveryService }),
+	orderColumn("shardkey", kindString, func(o *entity.Order) any { return &o.Shardkey }),
+	orderColumn("sm_id", kindInt, func(o *entity.Order) any { return &o.SmID }),
+	orderColumn("date_created", kindTime, func(o *entity.Order) any { return &o.DateCreated }),
+	orderColumn("oof_shard", kindString, func(o *entity.Order) any { return &o.OofShard }),
+
+	orderColumn("delivery_name", kindString, func(o *entity.Order) any { return &o.Delivery.Name }),
+	orderColumn("delivery_phone", kindString, func(o *entity.Order) any { return &o.Delivery.Phone }),
+	orderColumn("delivery_zip", kindString, func(o *entity.Order) any { return &o.Delivery.Zip }),
+	orderColumn("delivery_city", kindString, func(o *entity.Order) any { return &o.Delivery.City }),
+	orderColumn("delivery_address", kindString, func(o *entity.Order) any { return &o.Delivery.Address }),
+	orderColumn("delivery_region", kindString, func(o *entity.Order) any { return &o.Delivery.Region }),
+	orderColumn("delivery_email", kindString, func(o *entity.Order) any { return &o.Delivery.Email }),
+
+	orderColumn("payment_transaction", kindString, func(o *entity.Order) any { return &o.Payment.Transaction }),
+	orderColumn("payment_request_id", kindString, func(o *entity.Order) any { return &o.Payment.RequestID }),
+	orderColumn("payment_currency", kindString, func(o *entity.Order) any { return &o.Payment.Currency }),
+	orderColumn("payment_provider", kindString, func(o *entity.Order) any { return &o.Payment.Provider }),
+	orderColumn("payment_amount", kindMoney, func(o *entity.Order) any { return &o.Payment.Amount }),
+	orderColumn("payment_dt", kindTime, func(o *entity.Order) any { return &o.Payment.PaymentDt }),
+	orderColumn("payment_bank", kindString, func(o *entity.Order) any { return &o.Payment.Bank }),
+	orderColumn("payment_delivery_cost", kindMoney, func(o *entity.Order) any { return &o.Payment.DeliveryCost }),
+	orderColumn("payment_goods_total", kindMoney, func(o *entity.Order) any { return &o.Payment.GoodsTotal }),
+	orderColumn("payment_custom_fee", kindMoney, func(o *entity.Order) any { return &o.Payment.CustomFee }),
+
+	// items_count вычисляется: при импорте значение только сверяется с числом товаров
+	orderColumn("items_count", kindInt, func(o *entity.Order) any { n := int64(len(o.Items)); return &n }),
+
+	itemColumn("item_chrt_id", kindInt, func(i *entity.Item) any { return &i.ChrtID }),
+	itemColumn("item_track_number", kindString, func(i *entity.Item) any { return &i.TrackNumber }),
+	itemColumn("item_price", kindMoney, func(i *entity.Item) any { return &i.Price }),
+	itemColumn("item_rid", kindString, func(i *entity.Item) any { return &i.Rid }),
+	itemColumn("item_name", kindString, func(i *entity.Item) any { return &i.Name }),
+	itemColumn("item_sale", kindInt, func(i *entity.Item) any { return &i.Sale }),
+	itemColumn("item_size", kindString, func(i *entity.Item) any { return &i.Size }),
+	itemColumn("item_total_price", kindMoney, func(i *entity.Item) any { return &i.TotalPrice }),
+	itemColumn("item_nm_id", kindInt, func(i *entity.Item) any { return &i.NmID }),
+	itemColumn("item_brand", kindString, func(i *entity.Item) any { return &i.Brand }),
+	itemColumn("item_status", kindInt, func(i *entity.Item) any { return &i.Status }),
+}
+
+// ColumnNames возвращает имена колонок, доступных в режиме rows
+func ColumnNames(rows Rows) []string {
+	var names []string
+	for _, c := range columns {
+		if c.item == nil || rows == RowsPerItem {
+			names = append(names, c.name)
+		}
+	}
+	return names
+}
+
+// value возвращает значение колонки; у заказа без товаров колонки товара пусты (nil)
+func (c column) value(order *entity.Order, item *entity.Item) any {
+	if c.item == nil {
+		return deref(c.order(order))
+	}
+	if item == nil {
+		return nil
+	}
+	return deref(c.item(item))
+}
+
+// set записывает значение колонки (string, int64, entity.Money или time.Time, по kind) в заказ или товар
+func (c column) set(order *entity.Order, item *entity.Item, v any) {
+	var field any
+	if c.item == nil {
+		field = c.order(order)
+	} else {
+		field = c.item(item)
+	}
+	switch field := field.(type) {
+	case *string:
+		*field = v.(string)
+	case *int:
+		*field = int(v.(int64))
+	case *int64:
+		*field = v.(int64)
+	case *entity.Money:
+		*field = v.(entity.Money)
+	case *time.Time:
+		*field = v.(time.Time)
+	}
+}
+
+// deref возвращает значение поля: строку, int64, entity.Money или time.Time в UTC;
+// нулевое время — nil
+func deref(field any) any {
+	switch field := field.(type) {
+	case *string:
+		return *field
+	case *int:
+		return int64(*field)
+	case *int64:
+		return *field
+	case *entity.Money:
+		return *field
+	case *time.Time:
+		if field.IsZero() {
+			return nil
+		}
+		return field.UTC()
+	}
+	return nil
+}
+
+// columnByName возвращает колонку по имени
+func columnByName(name string) (column, bool) {
+	i := slices.IndexFunc(columns, func(c column) bool { return c.name == name })
+	if i < 0 {
+		return column{}, false
+	}
+	return columns[i], true
+}
@@ -0,0 +1,158 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"order/internal/entity"
+	"slices"
+)
+
+// Format — формат выгрузки
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatNDJSON  Format = "ndjson"
+	FormatParquet Format = "parquet"
+)
+
+// ContentType возвращает MIME-тип формата для HTTP-ответа
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "application/vnd.apache.parquet"
+	}
+}
+
+// Rows — чему соответствует строка выгрузки
+type Rows string
+
+const (
+	RowsPerItem  Rows = "item"  // строка на товар; заказ без товаров — одна строка с пустыми колонками товара
+	RowsPerOrder Rows = "order" // строка на заказ; колонки товара недоступны, есть items_count
+)
+
+// ErrInvalidOptions возвращается для неизвестного формата, режима или колонки
+var ErrInvalidOptions = errors.New("invalid export options")
+
+type Options struct {
+	Format Format
+	Rows   Rows // пусто — RowsPerItem
+	// Columns — колонки в нужном порядке; пусто — все колонки режима (ColumnNames)
+	Columns []string
+}
+
+func (o Options) Validate() error {
+	_, err := o.columns()
+	return err
+}
+
+// columns проверяет параметры и возвращает выбранные колонки
+func (o Options) columns() ([]column, error) {
+	if !slices.Contains([]Format{FormatCSV, FormatNDJSON, FormatParquet}, o.Format) {
+		return nil, fmt.Errorf("%w: unknown format %q", ErrInvalidOptions, o.Format)
+	}
+	rows := o.rows()
+	if rows != RowsPerItem && rows != RowsPerOrder {
+		return nil, fmt.Errorf("%w: unknown rows mode %q", ErrInvalidOptions, o.Rows)
+	}
+	names := o.Columns
+	if len(names) == 0 {
+		names = ColumnNames(rows)
+	}
+
+	selected := make([]column, 0, len(names))
+	for _, name := range names {
+		c, ok := columnByName(name)
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("%w: unknown column %q", ErrInvalidOptions, name)
+		case c.item != nil && rows == RowsPerOrder:
+			return nil, fmt.Errorf("%w: item column %q requires rows=%s", ErrInvalidOptions, name, RowsPerItem)
+		case slices.ContainsFunc(selected, func(c column) bool { return c.name == name }):
+			return nil, fmt.Errorf("%w: duplicate column %q", ErrInvalidOptions, name)
+		}
+		selected = append(selected, c)
+	}
+	return selected, nil
+}
+
+func (o Options) rows() Rows {
+	if o.Rows == "" {
+		return RowsPerItem
+	}
+	return o.Rows
+}
+
+// Summary — итог выгрузки
+type Summary struct {
+	Orders int
+	Rows   int
+}
+
+// rowWriter пишет строки выгрузки в конкретном формате
+type rowWriter interface {
+	write(values []any) error
+	close() error
+}
+
+// Export пишет заказы из orders в w построчно, не накапливая их: память ограничена одной строкой
+// (для Parquet — группой строк). Ошибка последовательности прерывает выгрузку; уже записанное
+// в w остаётся, поэтому по HTTP обрыв виден как неполный ответ
+func Export(w io.Writer, orders iter.Seq2[entity.Order, error], opts Options) (Summary, error) {
+	cols, err := opts.columns()
+	if err != nil {
+		return Summary{}, err
+	}
+
+	var out rowWriter
+	switch opts.Format {
+	case FormatCSV:
+		out, err = newCSVWriter(w, cols)
+	case FormatNDJSON:
+		out = newNDJSONWriter(w, cols)
+	case FormatParquet:
+		out, err = newParquetWriter(w, cols)
+	}
+	if err != nil {
+		return Summary{}, err
+	}
+
+	var summary Summary
+	values := make([]any, len(cols))
+	row := func(order *entity.Order, item *entity.Item) error {
+		for i, c := range cols {
+			values[i] = c.value(order, item)
+		}
+		summary.Rows++
+		return out.write(values)
+	}
+	for order, err := range orders {
+		if err != nil {
+			return summary, err
+		}
+		summary.Orders++
+		if opts.rows() == RowsPerOrder || len(order.Items) == 0 {
+			err = row(&order, nil)
+		} else {
+			for i := range order.Items {
+				if err = row(&order, &order.Items[i]); err != nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			return summary, fmt.Errorf("failed to write order %s: %w", order.OrderUID, err)
+		}
+	}
+	if err := out.close(); err != nil {
+		return summary, fmt.Errorf("failed to finish export: %w", err)
+	}
+	return summary, nil
+}
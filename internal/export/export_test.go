@@ -0,0 +1,272 @@
+package export
+
+import (
+    "bytes"
+    "encoding/csv"
+    "encoding/json"
+    "errors"
+    "iter"
+    "order/internal/entity"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "github.com/xitongsys/parquet-go-source/buffer"
+    "github.com/xitongsys/parquet-go/parquet"
+    "github.com/xitongsys/parquet-go/reader"
+)
+
+var testOrders = []entity.Order{
+    {
+        OrderUID:    "uid-1",
+        DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+        Delivery:    entity.Delivery{Name: "Test, \"Testov\"", City: "Kiryat Mozkin"},
+        Payment:     entity.Payment{Amount: entity.NewMoney(1817), Currency: "USD", PaymentDt: time.Unix(1637907727, 0).UTC()},
+        Items:       []entity.Item{{ChrtID: 9934930, Name: "Mascaras", Price: entity.NewMoney(453)}, {ChrtID: 9934931, Name: "Lipstick", Price: entity.NewMoney(100)}},
+    },
+    {OrderUID: "uid-2", Payment: entity.Payment{Amount: entity.NewMoney(10)}},
+}
+
+func ordersOf(orders []entity.Order, err error) iter.Seq2[entity.Order, error] {
+    return func(yield func(entity.Order, error) bool) {
+        for _, order := range orders {
+            if !yield(order, nil) {
+                return
+            }
+        }
+        if err != nil {
+            yield(entity.Order{}, err)
+        }
+    }
+}
+
+func TestExport_CSV(t *testing.T) {
+    t.Run("Row per item", func(t *testing.T) {
+        var out bytes.Buffer
+        summary, err := Export(&out, ordersOf(testOrders, nil), Options{
+            Format:  FormatCSV,
+            Columns: []string{"order_uid", "delivery_name", "payment_amount", "item_name", "item_price"},
+        })
+        require.NoError(t, err)
+        assert.Equal(t, Summary{Orders: 2, Rows: 3}, summary)
+
+        records, err := csv.NewReader(&out).ReadAll()
+        require.NoError(t, err)
+        assert.Equal(t, [][]string{
+            {"order_uid", "delivery_name", "payment_amount", "item_name", "item_price"},
+            {"uid-1", "Test, \"Testov\"", "1817", "Mascaras", "453"},
+            {"uid-1", "Test, \"Testov\"", "1817", "Lipstick", "100"},
+            {"uid-2", "", "10", "", ""},
+        }, records)
+    })
+
+    t.Run("Row per order", func(t *testing.T) {
+        var out bytes.Buffer
+        summary, err := Export(&out, ordersOf(testOrders, nil), Options{Format: FormatCSV, Rows: RowsPerOrder})
+        require.NoError(t, err)
+        assert.Equal(t, Summary{Orders: 2, Rows: 2}, summary)
+
+        records, err := csv.NewReader(&out).ReadAll()
+        require.NoError(t, err)
+        require.Len(t, records, 3)
+        assert.Equal(t, ColumnNames(RowsPerOrder), records[0])
+        assert.Equal(t, "items_count", records[0][len(records[0])-1])
+        assert.Equal(t, "2", records[1][len(records[1])-1])
+    })
+}
+
+func TestExport_NDJSON(t *testing.T) {
+    var out bytes.Buffer
+    _, err := Export(&out, ordersOf(testOrders, nil), Options{
+        Format:  FormatNDJSON,
+        Columns: []string{"order_uid", "item_chrt_id"},
+    })
+    require.NoError(t, err)
+
+    lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+    assert.Equal(t, []string{
+        `{"order_uid":"uid-1","item_chrt_id":9934930}`,
+        `{"order_uid":"uid-1","item_chrt_id":9934931}`,
+        `{"order_uid":"uid-2","item_chrt_id":null}`,
+    }, lines)
+    for _, line := range lines {
+        assert.True(t, json.Valid([]byte(line)))
+    }
+}
+
+func TestExport_Parquet(t *testing.T) {
+    var out bytes.Buffer
+    summary, err := Export(&out, ordersOf(testOrders, nil), Options{
+        Format:  FormatParquet,
+        Columns: []string{"order_uid", "payment_amount", "item_name", "date_created"},
+    })
+    require.NoError(t, err)
+    assert.Equal(t, 3, summary.Rows)
+
+    file, err := buffer.NewBufferFile(out.Bytes())
+    require.NoError(t, err)
+    pr, err := reader.NewParquetReader(file, nil, 1)
+    require.NoError(t, err)
+    defer pr.ReadStop()
+
+    assert.Equal(t, int64(3), pr.GetNumRows())
+    var names []string
+    // Читатель переименовывает колонки в Go-стиль; имена в файле — ExName
+    for _, info := range pr.SchemaHandler.Infos[1:] {
+        names = append(names, info.ExName)
+    }
+    assert.Equal(t, []string{"order_uid", "payment_amount", "item_name", "date_created"}, names)
+
+    amount := pr.SchemaHandler.SchemaElements[2]
+    assert.Equal(t, parquet.ConvertedType_DECIMAL, amount.GetConvertedType())
+    assert.Equal(t, int32(entity.MoneyScale), amount.GetScale())
+    assert.Equal(t, parquet.ConvertedType_TIMESTAMP_MICROS, pr.SchemaHandler.SchemaElements[4].GetConvertedType())
+}
+
+func TestExport_Errors(t *testing.T) {
+    tests := []struct {
+        name string
+        opts Options
+    }{
+        {"Unknown format", Options{Format: "xml"}},
+        {"Unknown rows mode", Options{Format: FormatCSV, Rows: "payment"}},
+        {"Unknown column", Options{Format: FormatCSV, Columns: []string{"order_uid", "secret"}}},
+        {"Item column per order", Options{Format: FormatCSV, Rows: RowsPerOrder, Columns: []string{"item_name"}}},
+        {"Duplicate column", Options{Format: FormatCSV, Columns: []string{"order_uid", "order_uid"}}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            _, err := Export(&bytes.Buffer{}, ordersOf(testOrders, nil), tt.opts)
+            assert.ErrorIs(t, err, ErrInvalidOptions)
+        })
+    }
+
+    t.Run("Source error stops export", func(t *testing.T) {
+        summary, err := Export(&bytes.Buffer{}, ordersOf(testOrders[:1], errors.New("connection refused")), Options{Format: FormatCSV})
+        assert.EqualError(t, err, "connection refused")
+        assert.Equal(t, 1, summary.Orders)
+    })
+}
+
+func TestRead(t *testing.T) {
+    collect := func(t *testing.T, data string, format Format) []Record {
+        var records []Record
+        for record, err := range Read(strings.NewReader(data), format) {
+            require.NoError(t, err)
+            records = append(records, record)
+        }
+        return records
+    }
+
+    for _, format := range []Format{FormatCSV, FormatNDJSON} {
+        t.Run("Round trip "+string(format), func(t *testing.T) {
+            var out bytes.Buffer
+            _, err := Export(&out, ordersOf(testOrders, nil), Options{Format: format})
+            require.NoError(t, err)
+
+            records := collect(t, out.String(), format)
+            require.Len(t, records, 2)
+            for i, record := range records {
+                assert.NoError(t, record.Err)
+                assert.Equal(t, testOrders[i], record.Order)
+            }
+            // У CSV первая строка — заголовок; у заказа с двумя товарами — две строки
+            firstLine := int64(1)
+            if format == FormatCSV {
+                firstLine = 2
+            }
+            assert.Equal(t, []int64{firstLine, firstLine + 2}, []int64{records[0].Line, records[1].Line})
+        })
+    }
+
+    t.Run("Rows per order cannot restore items", func(t *testing.T) {
+        var out bytes.Buffer
+        _, err := Export(&out, ordersOf(testOrders, nil), Options{Format: FormatNDJSON, Rows: RowsPerOrder})
+        require.NoError(t, err)
+
+        records := collect(t, out.String(), FormatNDJSON)
+        require.Len(t, records, 2)
+        assert.ErrorIs(t, records[0].Err, ErrInvalidRow)
+        assert.ErrorContains(t, records[0].Err, "items_count is 2, but 0 items found")
+        assert.NoError(t, records[1].Err)
+    })
+
+    t.Run("Invalid rows", func(t *testing.T) {
+        data := "order_uid,payment_amount,item_name\n" +
+            "uid-1,abc,Mascaras\n" +
+            "uid-1,10,Lipstick\n" +
+            ",10,\n" +
+            "uid-2,10\n" +
+            "uid-3,10,\n"
+        records := collect(t, data, FormatCSV)
+        require.Len(t, records, 4)
+        assert.ErrorContains(t, records[0].Err, "line 2: payment_amount must be a decimal number")
+        assert.ErrorContains(t, records[1].Err, "line 4: order_uid is missing")
+        assert.ErrorContains(t, records[2].Err, "line 5: wrong number of fields")
+        assert.NoError(t, records[3].Err)
+        assert.Equal(t, entity.Order{OrderUID: "uid-3", Payment: entity.Payment{Amount: entity.NewMoney(10)}}, records[3].Order)
+
+        data = `{"order_uid":"uid-1","sm_id":"10"}` + "\n" +
+            `{"order_uid":"uid-2","secret":1}` + "\n" +
+            "not json\n" +
+            `{"order_uid":"uid-3","payment_amount":"18.17","payment_custom_fee":0.5}` + "\n" +
+            `{"order_uid":"uid-4","date_created":"2021-11-26 09:22:19+03","payment_dt":1637907727}` + "\n" +
+            `{"order_uid":"uid-5","date_created":"yesterday"}` + "\n"
+        records = collect(t, data, FormatNDJSON)
+        require.Len(t, records, 6)
+        assert.ErrorContains(t, records[0].Err, "sm_id must be an integer")
+        assert.ErrorContains(t, records[1].Err, `unknown column "secret"`)
+        assert.ErrorIs(t, records[2].Err, ErrInvalidRow)
+        assert.NoError(t, records[3].Err)
+        assert.Equal(t, "18.17", records[3].Order.Payment.Amount.String())
+        assert.Equal(t, "0.5", records[3].Order.Payment.CustomFee.String())
+        // Выгрузки до перехода на time.Time: payment_dt — Unix-время
+        assert.NoError(t, records[4].Err)
+        assert.Equal(t, testOrders[0].DateCreated, records[4].Order.DateCreated)
+        assert.Equal(t, testOrders[0].Payment.PaymentDt, records[4].Order.Payment.PaymentDt)
+        assert.ErrorContains(t, records[5].Err, "date_created must be an RFC 3339 time")
+    })
+
+    t.Run("Unix time only in payment_dt", func(t *testing.T) {
+        data := "order_uid,date_created,payment_dt\n" +
+            "uid-1,2021-11-26T06:22:19Z,1637907727\n" +
+            "uid-2,1637907727,2021-11-26T06:22:19Z\n"
+        records := collect(t, data, FormatCSV)
+        require.Len(t, records, 2)
+        assert.NoError(t, records[0].Err)
+        assert.Equal(t, testOrders[0].Payment.PaymentDt, records[0].Order.Payment.PaymentDt)
+        assert.ErrorContains(t, records[1].Err, "line 3: date_created must be an RFC 3339 time")
+
+        records = collect(t, `{"order_uid":"uid-1","date_created":1637907727}`+"\n", FormatNDJSON)
+        require.Len(t, records, 1)
+        assert.ErrorContains(t, records[0].Err, "date_created must be an RFC 3339 time")
+    })
+
+    t.Run("Unreadable row inside an order", func(t *testing.T) {
+        data := "order_uid,item_name\n" +
+            "uid-1,Mascaras\n" +
+            "uid-1,Lip\"stick\n" +
+            "uid-1,Powder\n" +
+            "uid-2,\n"
+        records := collect(t, data, FormatCSV)
+        require.Len(t, records, 3)
+        // Заказ не восстанавливается без своей строки, а нечитаемая строка идёт отдельной записью
+        assert.Equal(t, "uid-1", records[0].Order.OrderUID)
+        assert.ErrorContains(t, records[0].Err, "line 4: order continues after unreadable line 3")
+        assert.Empty(t, records[1].Order.OrderUID)
+        assert.Equal(t, int64(3), records[1].Line)
+        assert.ErrorIs(t, records[1].Err, ErrInvalidRow)
+        assert.Equal(t, "uid-2", records[2].Order.OrderUID)
+        assert.NoError(t, records[2].Err)
+    })
+
+    t.Run("Invalid CSV header", func(t *testing.T) {
+        for _, header := range []string{"order_uid,secret\n", "payment_amount\n", "order_uid,order_uid\n"} {
+            for _, err := range Read(strings.NewReader(header), FormatCSV) {
+                assert.ErrorIs(t, err, ErrInvalidOptions)
+            }
+        }
+    })
+}
@@ -0,0 +1,304 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"order/internal/entity"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidRow возвращается в Record.Err для строки, которую не удалось разобрать
+var ErrInvalidRow = errors.New("invalid export row")
+
+// maxLineSize — предел длины одной строки NDJSON
+const maxLineSize = 64 << 20
+
+// Record — заказ, собранный из идущих подряд строк выгрузки с одним order_uid
+type Record struct {
+	Order entity.Order
+	Line  int64 // первая строка заказа во входе, считая с 1 (у CSV — с заголовком)
+	Err   error // ErrInvalidRow: заказ не разобран, остальные его строки пропущены
+}
+
+// Read разбирает выгрузку в формате CSV или NDJSON обратно в заказы. Строки одного заказа
+// должны идти подряд, как их пишет Export. Колонки могут быть любым подмножеством и в любом
+// порядке, но order_uid обязателен; отсутствующие поля остаются пустыми. Строка без order_uid
+// (в том числе нечитаемая строка CSV) отдаётся отдельной записью с пустым Order и ошибкой. Если в строках есть
+// items_count, он сверяется с числом товаров: выгрузка с rows=order товаров не содержит
+// и восстановить заказы без потерь не может. Ошибка последовательности (чтение входа,
+// заголовок CSV) прерывает разбор, ошибки отдельных строк передаются в Record.Err
+func Read(r io.Reader, format Format) iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		var rows iter.Seq2[row, error]
+		switch format {
+		case FormatCSV:
+			rows = csvRows(r)
+		case FormatNDJSON:
+			rows = ndjsonRows(r)
+		default:
+			yield(Record{}, fmt.Errorf("%w: cannot read format %q", ErrInvalidOptions, format))
+			return
+		}
+
+		var current *Record
+		itemsCount := int64(-1) // -1 — колонки items_count нет
+		// Строки без order_uid не относятся ни к одному заказу и отдаются отдельными записями
+		// без Order.OrderUID — после заказа, посреди которого встретились
+		var orphans []Record
+		flush := func() bool {
+			if current != nil {
+				record := *current
+				current = nil
+				if record.Err == nil && itemsCount >= 0 && itemsCount != int64(len(record.Order.Items)) {
+					record.Err = fmt.Errorf("%w: line %d: items_count is %d, but %d items found (rows=%s export keeps items)",
+						ErrInvalidRow, record.Line, itemsCount, len(record.Order.Items), RowsPerItem)
+				}
+				if !yield(record, nil) {
+					return false
+				}
+			}
+			for _, orphan := range orphans {
+				if !yield(orphan, nil) {
+					return false
+				}
+			}
+			orphans = orphans[:0]
+			return true
+		}
+
+		for row, err := range rows {
+			if err != nil {
+				yield(Record{}, err)
+				return
+			}
+			if row.uid == "" {
+				row.fail(errors.New("order_uid is missing"))
+				orphans = append(orphans, Record{Line: row.line, Err: row.err})
+				continue
+			}
+			if current != nil && current.Order.OrderUID == row.uid && len(orphans) > 0 && current.Err == nil {
+				// Нечитаемая строка посреди строк заказа, скорее всего, его собственная:
+				// без неё заказ восстановился бы не полностью
+				current.Err = fmt.Errorf("%w: line %d: order continues after unreadable line %d",
+					ErrInvalidRow, row.line, orphans[0].Line)
+			}
+			if current == nil || current.Order.OrderUID != row.uid {
+				if !flush() {
+					return
+				}
+				current = &Record{Line: row.line}
+				itemsCount = -1
+				for _, f := range row.fields {
+					switch {
+					case f.column.name == "items_count":
+						itemsCount = f.value.(int64)
+					case f.column.item == nil:
+						f.column.set(&current.Order, nil, f.value)
+					}
+				}
+			}
+			if row.err != nil {
+				if current.Err == nil {
+					current.Err = row.err
+				}
+				continue
+			}
+
+			var item entity.Item
+			hasItem := false
+			for _, f := range row.fields {
+				if f.column.item != nil {
+					f.column.set(nil, &item, f.value)
+					hasItem = true
+				}
+			}
+			if hasItem {
+				current.Order.Items = append(current.Order.Items, item)
+			}
+		}
+		flush()
+	}
+}
+
+// row — разобранная строка выгрузки. В fields только заданные значения: пустые ячейки
+// CSV колонок товара и null в NDJSON пропускаются
+type row struct {
+	line   int64
+	uid    string
+	fields []field
+	err    error
+}
+
+type field struct {
+	column column
+	value  any // string, int64, entity.Money или time.Time
+}
+
+// add разбирает текстовое значение колонки; для JSON text — исходный литерал
+func (r *row) add(c column, text string, quoted bool) {
+	var value any
+	switch {
+	case c.kind == kindString:
+		value = text
+	case c.kind == kindMoney:
+		// В NDJSON сумма допустима и строкой, как в JSON заказа (entity.Money)
+		m, err := entity.ParseMoney(text)
+		if err != nil {
+			r.fail(fmt.Errorf("%s must be a decimal number, got %q", c.name, text))
+			return
+		}
+		value = m
+	case c.kind == kindTime:
+		// Выгрузки до перехода на time.Time писали payment_dt Unix-временем (date_created всегда
+		// был строкой). Число без кавычек в NDJSON или ячейка CSV из одних цифр читаются как
+		// секунды Unix; в других колонках времени такое значение — ошибка
+		if c.name == "payment_dt" && !quoted {
+			if sec, err := strconv.ParseInt(text, 10, 64); err == nil {
+				value = time.Unix(sec, 0).UTC()
+				break
+			}
+		}
+		t, err := entity.ParseTime(text)
+		if err != nil {
+			r.fail(fmt.Errorf("%s must be an RFC 3339 time, got %q", c.name, text))
+			return
+		}
+		value = t
+	default:
+		n, err := strconv.ParseInt(text, 10, 64)
+		if err != nil || quoted {
+			r.fail(fmt.Errorf("%s must be an integer, got %q", c.name, text))
+			return
+		}
+		value = n
+	}
+	if c.name == "order_uid" {
+		r.uid = text
+	}
+	r.fields = append(r.fields, field{column: c, value: value})
+}
+
+func (r *row) fail(err error) {
+	if r.err == nil {
+		r.err = fmt.Errorf("%w: line %d: %v", ErrInvalidRow, r.line, err)
+	}
+}
+
+// csvRows читает CSV с заголовком из имён колонок
+func csvRows(r io.Reader) iter.Seq2[row, error] {
+	return func(yield func(row, error) bool) {
+		cr := csv.NewReader(r)
+		cr.ReuseRecord = true
+		header, err := cr.Read()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			yield(row{}, fmt.Errorf("failed to read CSV header: %w", err))
+			return
+		}
+		cols := make([]column, len(header))
+		seen := make(map[string]bool, len(header))
+		for i, name := range header {
+			c, ok := columnByName(name)
+			switch {
+			case !ok:
+				yield(row{}, fmt.Errorf("%w: unknown column %q in CSV header", ErrInvalidOptions, name))
+				return
+			case seen[name]:
+				yield(row{}, fmt.Errorf("%w: duplicate column %q in CSV header", ErrInvalidOptions, name))
+				return
+			}
+			seen[name] = true
+			cols[i] = c
+		}
+		if !seen["order_uid"] {
+			yield(row{}, fmt.Errorf("%w: CSV header has no order_uid column", ErrInvalidOptions))
+			return
+		}
+
+		for {
+			record, err := cr.Read()
+			if err == io.EOF {
+				return
+			}
+			var parseErr *csv.ParseError
+			switch {
+			case errors.As(err, &parseErr):
+				r := row{line: int64(parseErr.StartLine)}
+				r.fail(parseErr.Err)
+				if !yield(r, nil) {
+					return
+				}
+				continue
+			case err != nil:
+				yield(row{}, err)
+				return
+			}
+			line, _ := cr.FieldPos(0)
+			r := row{line: int64(line)}
+			for i, text := range record {
+				// Пустая ячейка колонки товара — нет товара, пустое число — ноль
+				if text == "" && (cols[i].item != nil || cols[i].kind != kindString) {
+					continue
+				}
+				r.add(cols[i], text, false)
+			}
+			if !yield(r, nil) {
+				return
+			}
+		}
+	}
+}
+
+// ndjsonRows читает строки-объекты; пустые строки пропускаются
+func ndjsonRows(r io.Reader) iter.Seq2[row, error] {
+	return func(yield func(row, error) bool) {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 1<<20), maxLineSize)
+		var line int64
+		for scanner.Scan() {
+			line++
+			if len(scanner.Bytes()) == 0 {
+				continue
+			}
+			r := row{line: line}
+			var object map[string]json.RawMessage
+			if err := json.Unmarshal(scanner.Bytes(), &object); err != nil {
+				r.fail(err)
+			}
+			for name, raw := range object {
+				c, ok := columnByName(name)
+				if !ok {
+					r.fail(fmt.Errorf("unknown column %q", name))
+					continue
+				}
+				if string(raw) == "null" {
+					continue
+				}
+				var text string
+				quoted := json.Unmarshal(raw, &text) == nil
+				if !quoted {
+					text = string(raw)
+				}
+				if c.kind == kindString && !quoted {
+					r.fail(fmt.Errorf("%s must be a string, got %s", name, raw))
+					continue
+				}
+				r.add(c, text, quoted)
+			}
+			if !yield(r, nil) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield(row{}, fmt.Errorf("failed to read line %d: %w", line+1, err))
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"order/internal/entity"
+	"strconv"
+	"time"
+
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// csvWriter пишет заголовок и строки; пустое значение колонки товара — пустая ячейка
+type csvWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func newCSVWriter(w io.Writer, cols []column) (*csvWriter, error) {
+	out := &csvWriter{w: csv.NewWriter(w), record: make([]string, len(cols))}
+	for i, c := range cols {
+		out.record[i] = c.name
+	}
+	if err := out.w.Write(out.record); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *csvWriter) write(values []any) error {
+	for i, v := range values {
+		switch v := v.(type) {
+		case nil:
+			c.record[i] = ""
+		case string:
+			c.record[i] = v
+		case int64:
+			c.record[i] = strconv.FormatInt(v, 10)
+		case entity.Money:
+			c.record[i] = v.String()
+		case time.Time:
+			c.record[i] = entity.FormatTime(v)
+		}
+	}
+	return c.w.Write(c.record)
+}
+
+func (c *csvWriter) close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// ndjsonWriter пишет строку как JSON-объект с колонками в заданном порядке
+type ndjsonWriter struct {
+	w     *bufio.Writer
+	names [][]byte // имена колонок, уже закодированные в JSON
+	line  []byte
+}
+
+func newNDJSONWriter(w io.Writer, cols []column) *ndjsonWriter {
+	out := &ndjsonWriter{w: bufio.NewWriter(w)}
+	for _, c := range cols {
+		name, _ := json.Marshal(c.name)
+		out.names = append(out.names, name)
+	}
+	return out
+}
+
+func (n *ndjsonWriter) write(values []any) error {
+	n.line = append(n.line[:0], '{')
+	for i, v := range values {
+		if i > 0 {
+			n.line = append(n.line, ',')
+		}
+		n.line = append(n.line, n.names[i]...)
+		n.line = append(n.line, ':')
+		value, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		n.line = append(n.line, value...)
+	}
+	n.line = append(n.line, '}', '\n')
+	_, err := n.w.Write(n.line)
+	return err
+}
+
+func (n *ndjsonWriter) close() error {
+	return n.w.Flush()
+}
+
+// parquetRowGroupSize ограничивает память: группа строк копится в памяти до записи
+const parquetRowGroupSize = 8 << 20
+
+// parquetWriter пишет колонки как UTF8-строки, INT64, суммы как DECIMAL(18, 4) и время как
+// TIMESTAMP_MICROS поверх INT64; колонки товара (NULL без товара) и времени необязательные
+type parquetWriter struct {
+	w *writer.CSVWriter
+}
+
+func newParquetWriter(w io.Writer, cols []column) (*parquetWriter, error) {
+	schema := make([]string, len(cols))
+	for i, c := range cols {
+		typ := "type=BYTE_ARRAY, convertedtype=UTF8"
+		switch c.kind {
+		case kindInt:
+			typ = "type=INT64"
+		case kindMoney:
+			typ = fmt.Sprintf("type=INT64, convertedtype=DECIMAL, scale=%d, precision=18", entity.MoneyScale)
+		case kindTime:
+			typ = "type=INT64, convertedtype=TIMESTAMP_MICROS"
+		}
+		repetition := "REQUIRED"
+		if c.item != nil || c.kind == kindTime {
+			repetition = "OPTIONAL"
+		}
+		schema[i] = fmt.Sprintf("name=%s, %s, repetitiontype=%s", c.name, typ, repetition)
+	}
+	pw, err := writer.NewCSVWriterFromWriter(schema, w, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create parquet writer: %w", err)
+	}
+	pw.RowGroupSize = parquetRowGroupSize
+	pw.CompressionType = parquet.CompressionCodec_SNAPPY
+	return &parquetWriter{w: pw}, nil
+}
+
+func (p *parquetWriter) write(values []any) error {
+	// Писатель сохраняет запись до сброса группы строк, поэтому срез копируется
+	row := append([]any(nil), values...)
+	for i, v := range row {
+		switch v := v.(type) {
+		case entity.Money:
+			row[i] = v.Scaled()
+		case time.Time:
+			row[i] = v.UnixMicro()
+		}
+	}
+	return p.w.Write(row)
+}
+
+func (p *parquetWriter) close() error {
+	return p.w.WriteStop()
+}
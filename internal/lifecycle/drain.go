@@ -0,0 +1,77 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Drainer координирует остановку цикла чтения: после Drain цикл перестаёт брать новые
+// сообщения и дообрабатывает текущее; если срок истёк, обработка прерывается
+type Drainer struct {
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+
+	mu      sync.Mutex
+	started bool
+	abort   context.CancelFunc
+}
+
+func NewDrainer() *Drainer {
+	return &Drainer{stop: make(chan struct{}), done: make(chan struct{})}
+}
+
+// Begin вызывается в начале цикла. fetch отменяется при остановке и нужен для чтения,
+// work отменяется только по ctx или по истечении срока Drain и нужен для обработки.
+// end вызывается при выходе из цикла
+func (d *Drainer) Begin(ctx context.Context) (fetch, work context.Context, end func()) {
+	work, abort := context.WithCancel(ctx)
+	fetch, cancelFetch := context.WithCancel(work)
+
+	d.mu.Lock()
+	d.started = true
+	d.abort = abort
+	d.mu.Unlock()
+
+	select {
+	case <-d.stop:
+		cancelFetch() // Остановка запрошена до начала цикла
+	default:
+		go func() {
+			select {
+			case <-d.stop:
+				cancelFetch()
+			case <-fetch.Done():
+			}
+		}()
+	}
+
+	return fetch, work, func() {
+		cancelFetch()
+		abort()
+		close(d.done)
+	}
+}
+
+// Drain останавливает чтение и ждёт выхода из цикла. По истечении ctx текущая
+// обработка прерывается, а Drain возвращает ошибку
+func (d *Drainer) Drain(ctx context.Context) error {
+	d.stopOnce.Do(func() { close(d.stop) })
+
+	d.mu.Lock()
+	started, abort := d.started, d.abort
+	d.mu.Unlock()
+	if !started {
+		return nil
+	}
+
+	select {
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		abort()
+		<-d.done
+		return fmt.Errorf("in-flight work aborted: %w", ctx.Err())
+	}
+}
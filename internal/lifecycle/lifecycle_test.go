@@ -0,0 +1,91 @@
+package lifecycle
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+// loop — цикл чтения, который обрабатывает одно «сообщение» за step
+func loop(d *Drainer, ctx context.Context, step time.Duration, processed chan<- error) {
+    fetch, work, end := d.Begin(ctx)
+    defer end()
+    for fetch.Err() == nil {
+        select {
+        case <-time.After(step):
+            processed <- nil
+        case <-work.Done():
+            processed <- work.Err()
+            return
+        }
+    }
+}
+
+func TestDrainer(t *testing.T) {
+    t.Run("In-flight work finishes", func(t *testing.T) {
+        d := NewDrainer()
+        processed := make(chan error, 100)
+        go loop(d, context.Background(), 50*time.Millisecond, processed)
+        time.Sleep(10 * time.Millisecond)
+
+        ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+        defer cancel()
+        require.NoError(t, d.Drain(ctx))
+        assert.NoError(t, <-processed)
+    })
+
+    t.Run("Deadline aborts in-flight work", func(t *testing.T) {
+        d := NewDrainer()
+        processed := make(chan error, 100)
+        go loop(d, context.Background(), time.Hour, processed)
+        time.Sleep(10 * time.Millisecond)
+
+        ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+        defer cancel()
+        err := d.Drain(ctx)
+        assert.ErrorIs(t, err, context.DeadlineExceeded)
+        assert.ErrorIs(t, <-processed, context.Canceled)
+    })
+
+    t.Run("Drain before start", func(t *testing.T) {
+        d := NewDrainer()
+        require.NoError(t, d.Drain(context.Background()))
+
+        processed := make(chan error, 1)
+        loop(d, context.Background(), time.Hour, processed) // Сразу выходит
+        assert.Empty(t, processed)
+    })
+}
+
+func TestShutdown(t *testing.T) {
+    var order []string
+    step := func(name string, err error) Step {
+        return Step{Name: name, Run: func(context.Context) error {
+            order = append(order, name)
+            return err
+        }}
+    }
+    failed := errors.New("flush failed")
+    slow := Step{Name: "slow", Run: func(ctx context.Context) error {
+        order = append(order, "slow")
+        <-ctx.Done()
+        return ctx.Err()
+    }}
+    afterDeadline := Step{Name: "db", Run: func(ctx context.Context) error {
+        order = append(order, "db")
+        assert.Error(t, ctx.Err()) // Срок истёк, но шаг всё равно выполняется
+        return nil
+    }}
+
+    results, err := Shutdown(20*time.Millisecond, step("consumers", nil), step("quarantine", failed), slow, afterDeadline)
+    assert.Equal(t, []string{"consumers", "quarantine", "slow", "db"}, order)
+    assert.ErrorIs(t, err, failed)
+    assert.ErrorIs(t, err, context.DeadlineExceeded)
+    require.Len(t, results, 4)
+    assert.NoError(t, results[0].Err)
+    assert.NoError(t, results[3].Err)
+}
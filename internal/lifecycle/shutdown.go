@@ -0,0 +1,51 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+// Step — шаг остановки приложения
+type Step struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// StepResult — итог шага остановки
+type StepResult struct {
+	Name     string
+	Duration time.Duration
+	Err      error
+}
+
+// Shutdown выполняет шаги по порядку в пределах общего срока timeout и пишет итог в лог.
+// Шаги после истечения срока всё равно выполняются — с отменённым контекстом, — чтобы
+// освободить ресурсы. Возвращает объединённую ошибку шагов
+func Shutdown(timeout time.Duration, steps ...Step) ([]StepResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	results := make([]StepResult, 0, len(steps))
+	var errs []error
+	for _, step := range steps {
+		stepStart := time.Now()
+		err := step.Run(ctx)
+		results = append(results, StepResult{Name: step.Name, Duration: time.Since(stepStart), Err: err})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	log.Printf("Shutdown summary (deadline %s, took %s):", timeout, time.Since(start).Round(time.Millisecond))
+	for _, r := range results {
+		if r.Err != nil {
+			log.Printf("  %-24s failed after %s: %v", r.Name, r.Duration.Round(time.Millisecond), r.Err)
+			continue
+		}
+		log.Printf("  %-24s ok in %s", r.Name, r.Duration.Round(time.Millisecond))
+	}
+	return results, errors.Join(errs...)
+}
@@ -0,0 +1,23 @@
+package rates
+
+import (
+	"context"
+	"errors"
+	"order/internal/entity"
+	"time"
+)
+
+// ErrNoRate возвращается, если курса пары валют на день нет и его нельзя вывести через общую базу
+var ErrNoRate = errors.New("no exchange rate")
+
+// Provider — источник дневных курсов валют
+type Provider interface {
+	// Rates возвращает курсы за дни с from по to включительно. Дней без публикации
+	// (выходных, праздников) в ответе может не быть
+	Rates(ctx context.Context, from, to time.Time) ([]entity.ExchangeRate, error)
+}
+
+// Store — хранилище курсов (таблица exchange_rates)
+type Store interface {
+	SaveRates(ctx context.Context, rates []entity.ExchangeRate) error
+}
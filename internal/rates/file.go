@@ -0,0 +1,92 @@
+package rates
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"order/internal/entity"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// csvHeader — колонки файла курсов: день (YYYY-MM-DD), база, валюта, курс
+var csvHeader = []string{"day", "base", "currency", "rate"}
+
+// ReadCSV читает курсы из CSV с заголовком day,base,currency,rate:
+//
+//	day,base,currency,rate
+//	2024-03-01,EUR,USD,1.0838
+func ReadCSV(r io.Reader) ([]entity.ExchangeRate, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(csvHeader)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rates header: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+	}
+	if !slices.Equal(header, csvHeader) {
+		return nil, fmt.Errorf("unexpected rates header %q, want %q", strings.Join(header, ","), strings.Join(csvHeader, ","))
+	}
+
+	var rates []entity.ExchangeRate
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rates, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read rates: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		day, err := time.Parse(time.DateOnly, record[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid day %q", line, record[0])
+		}
+		rate, err := entity.NewExchangeRate(day, strings.ToUpper(record[1]), strings.ToUpper(record[2]), record[3])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rates = append(rates, rate)
+	}
+}
+
+// File — курсы из CSV-файла (см. ReadCSV), который перечитывается при каждом запросе
+type File struct {
+	path string
+}
+
+func NewFile(path string) *File {
+	return &File{path: path}
+}
+
+func (f *File) Rates(_ context.Context, from, to time.Time) ([]entity.ExchangeRate, error) {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open rates file: %w", err)
+	}
+	defer file.Close()
+
+	all, err := ReadCSV(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", f.path, err)
+	}
+	from, to = entity.Day(from), entity.Day(to)
+	var rates []entity.ExchangeRate
+	for _, rate := range all {
+		if !rate.Day.Before(from) && !rate.Day.After(to) {
+			rates = append(rates, rate)
+		}
+	}
+	return rates, nil
+}
@@ -0,0 +1,98 @@
+package rates
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"order/internal/entity"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Frankfurter — курсы ЕЦБ через API Frankfurter (https://frankfurter.dev): курсы
+// публикуются по рабочим дням, одна база на запрос
+type Frankfurter struct {
+	url    string
+	base   string
+	client *http.Client
+}
+
+func NewFrankfurter(url, base string) *Frankfurter {
+	return &Frankfurter{
+		url:    strings.TrimRight(url, "/"),
+		base:   base,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// frankfurterSeries — ответ на запрос курсов за период
+type frankfurterSeries struct {
+	Base  string                            `json:"base"`
+	Rates map[string]map[string]json.Number `json:"rates"` // день -> валюта -> курс
+}
+
+func (f *Frankfurter) Rates(ctx context.Context, from, to time.Time) ([]entity.ExchangeRate, error) {
+	target := fmt.Sprintf("%s/%s..%s?base=%s", f.url,
+		entity.Day(from).Format(time.DateOnly), entity.Day(to).Format(time.DateOnly), url.QueryEscape(f.base))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch rates: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("rates provider responded %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+
+	var series frankfurterSeries
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber() // курсы разбираются без потерь через float64
+	if err := dec.Decode(&series); err != nil {
+		return nil, fmt.Errorf("failed to decode rates: %w", err)
+	}
+
+	var rates []entity.ExchangeRate
+	var skipped []string
+	for dayText, quotes := range series.Rates {
+		day, err := time.Parse(time.DateOnly, dayText)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rates day %q", dayText)
+		}
+		for currency, number := range quotes {
+			// Число может прийти с экспонентой; ParseRate принимает только десятичную запись
+			value, ok := new(big.Rat).SetString(number.String())
+			if !ok {
+				return nil, fmt.Errorf("%w: %s %s on %s", entity.ErrInvalidRate, number, currency, dayText)
+			}
+			rate, err := entity.NewExchangeRate(day, series.Base, currency, entity.FormatRate(value))
+			if errors.Is(err, entity.ErrUnknownCurrency) {
+				if !slices.Contains(skipped, currency) {
+					skipped = append(skipped, currency)
+				}
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			rates = append(rates, rate)
+		}
+	}
+	if len(skipped) > 0 {
+		log.Printf("Skipped rates for unsupported currencies: %s", strings.Join(skipped, ", "))
+	}
+	return rates, nil
+}
@@ -0,0 +1,121 @@
+package rates
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "order/internal/entity"
+    "order/internal/storage/memory"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func date(day int) time.Time {
+    return time.Date(2024, 3, day, 0, 0, 0, 0, time.UTC)
+}
+
+func rate(t *testing.T, day int, base, currency, value string) entity.ExchangeRate {
+    r, err := entity.NewExchangeRate(date(day), base, currency, value)
+    require.NoError(t, err)
+    return r
+}
+
+func TestTable_Rate(t *testing.T) {
+    table := NewTable([]entity.ExchangeRate{
+        rate(t, 1, "EUR", "USD", "1.0838"),
+        rate(t, 1, "EUR", "RUB", "99.0"),
+        rate(t, 4, "EUR", "USD", "1.0858"),
+    })
+
+    tests := []struct {
+        name     string
+        from, to string
+        day      time.Time
+        want     string
+        err      error
+    }{
+        {"Same currency", "JPY", "JPY", date(1), "1", nil},
+        {"Direct", "EUR", "USD", date(1), "1.0838", nil},
+        {"Inverse", "USD", "EUR", date(4), "0.920979922638", nil},
+        {"Cross through the base", "USD", "RUB", date(1), "91.345266654364", nil},
+        {"Weekend takes the last published rate", "EUR", "USD", date(3).Add(15 * time.Hour), "1.0838", nil},
+        {"Before the first rate", "EUR", "USD", date(1).Add(-time.Hour), "", ErrNoRate},
+        {"Stale rate", "EUR", "RUB", date(1 + MaxRateAgeDays + 1), "", ErrNoRate},
+        {"Unknown pair", "EUR", "GBP", date(1), "", ErrNoRate},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            r, err := table.Rate(tt.from, tt.to, tt.day)
+            if tt.err != nil {
+                assert.ErrorIs(t, err, tt.err)
+                return
+            }
+            require.NoError(t, err)
+            assert.Equal(t, tt.want, entity.FormatRate(r))
+        })
+    }
+
+    converted, err := table.Convert(entity.NewMoney(1000), "USD", "RUB", date(1))
+    require.NoError(t, err)
+    assert.Equal(t, "91345.27", converted.String())
+}
+
+func TestReadCSV(t *testing.T) {
+    rates, err := ReadCSV(strings.NewReader("day,base,currency,rate\n2024-03-01,EUR,USD,1.0838\n2024-03-01, eur, rub, 99\n"))
+    require.NoError(t, err)
+    assert.Equal(t, []entity.ExchangeRate{rate(t, 1, "EUR", "USD", "1.0838"), rate(t, 1, "EUR", "RUB", "99")}, rates)
+
+    _, err = ReadCSV(strings.NewReader("date,from,to,rate\n"))
+    assert.ErrorContains(t, err, "unexpected rates header")
+
+    _, err = ReadCSV(strings.NewReader("day,base,currency,rate\n2024-03-01,EUR,USD,-1\n"))
+    assert.ErrorIs(t, err, entity.ErrInvalidRate)
+    assert.ErrorContains(t, err, "line 2")
+}
+
+func TestFile_Rates(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "rates.csv")
+    require.NoError(t, os.WriteFile(path, []byte("day,base,currency,rate\n2024-02-29,EUR,USD,1.0820\n2024-03-01,EUR,USD,1.0838\n"), 0o644))
+
+    rates, err := NewFile(path).Rates(context.Background(), date(1), date(7))
+    require.NoError(t, err)
+    assert.Equal(t, []entity.ExchangeRate{rate(t, 1, "EUR", "USD", "1.0838")}, rates)
+}
+
+func TestFrankfurter_Rates(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        assert.Equal(t, "/v1/2024-03-01..2024-03-04", r.URL.Path)
+        assert.Equal(t, "EUR", r.URL.Query().Get("base"))
+        w.Write([]byte(`{"amount":1.0,"base":"EUR","start_date":"2024-03-01","end_date":"2024-03-04",
+            "rates":{"2024-03-01":{"USD":1.0838,"XAU":0.0005},"2024-03-04":{"USD":1.0858,"JPY":1.6e2}}}`))
+    }))
+    defer server.Close()
+
+    rates, err := NewFrankfurter(server.URL+"/v1/", "EUR").Rates(context.Background(), date(1), date(4))
+    require.NoError(t, err)
+    assert.ElementsMatch(t, []entity.ExchangeRate{
+        rate(t, 1, "EUR", "USD", "1.0838"),
+        rate(t, 4, "EUR", "USD", "1.0858"),
+        rate(t, 4, "EUR", "JPY", "160"),
+    }, rates)
+}
+
+func TestSync(t *testing.T) {
+    store := memory.NewStore()
+    file := filepath.Join(t.TempDir(), "rates.csv")
+    require.NoError(t, os.WriteFile(file, []byte("day,base,currency,rate\n2024-03-01,EUR,USD,1.0838\n"), 0o644))
+
+    n, err := Sync(context.Background(), NewFile(file), store, date(1), date(1))
+    require.NoError(t, err)
+    assert.Equal(t, 1, n)
+
+    saved, err := store.Rates(context.Background(), date(1), date(1))
+    require.NoError(t, err)
+    assert.Equal(t, []entity.ExchangeRate{rate(t, 1, "EUR", "USD", "1.0838")}, saved)
+}
@@ -0,0 +1,80 @@
+package rates
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"order/config"
+	"time"
+)
+
+// NewProvider создаёт источник курсов по конфигурации; для пустого RATES_PROVIDER — nil
+func NewProvider(cfg config.Rates) (Provider, error) {
+	switch cfg.Provider {
+	case "":
+		return nil, nil
+	case config.RatesFrankfurter:
+		return NewFrankfurter(cfg.URL, cfg.Base), nil
+	case config.RatesFile:
+		return NewFile(cfg.File), nil
+	default:
+		return nil, fmt.Errorf("unknown rates provider %q", cfg.Provider)
+	}
+}
+
+// Sync загружает курсы за дни с from по to включительно и сохраняет их; возвращает
+// число сохранённых курсов
+func Sync(ctx context.Context, provider Provider, store Store, from, to time.Time) (int, error) {
+	rates, err := provider.Rates(ctx, from, to)
+	if err != nil {
+		return 0, err
+	}
+	if len(rates) == 0 {
+		return 0, nil
+	}
+	if err := store.SaveRates(ctx, rates); err != nil {
+		return 0, fmt.Errorf("failed to save rates: %w", err)
+	}
+	return len(rates), nil
+}
+
+// Syncer периодически загружает курсы за последние days дней: повторная загрузка
+// исправляет курсы, опубликованные задним числом, и заполняет пропуски после простоя
+type Syncer struct {
+	provider Provider
+	store    Store
+	interval time.Duration
+	days     int
+}
+
+func NewSyncer(provider Provider, store Store, interval time.Duration, days int) *Syncer {
+	return &Syncer{provider: provider, store: store, interval: interval, days: days}
+}
+
+// Run загружает курсы сразу и затем каждые interval до отмены ctx. Ошибки загрузки
+// пишутся в лог: отчёты пересчитываются по уже сохранённым курсам
+func (s *Syncer) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		s.sync(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Syncer) sync(ctx context.Context) {
+	to := time.Now()
+	from := to.AddDate(0, 0, 1-s.days)
+	n, err := Sync(ctx, s.provider, s.store, from, to)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Failed to sync exchange rates: %v", err)
+		}
+		return
+	}
+	log.Printf("Synced %d exchange rates", n)
+}
@@ -0,0 +1,90 @@
+package rates
+
+import (
+	"fmt"
+	"math/big"
+	"order/internal/entity"
+	"slices"
+	"sort"
+	"time"
+)
+
+// MaxRateAgeDays — на сколько дней курс может быть старше дня оплаты: в выходные
+// и праздники курсы не публикуются, и действует последний опубликованный
+const MaxRateAgeDays = 7
+
+type pair struct {
+	base, currency string
+}
+
+type dayRate struct {
+	day  time.Time
+	rate *big.Rat
+}
+
+// Table пересчитывает суммы между валютами по курсам на день. Курс пары берётся прямой,
+// обратный или кросс-курс через общую базу (курсы EUR/USD и EUR/RUB дают USD/RUB)
+type Table struct {
+	rates map[pair][]dayRate // по возрастанию дня
+	bases []string
+}
+
+func NewTable(rates []entity.ExchangeRate) *Table {
+	t := &Table{rates: make(map[pair][]dayRate)}
+	for _, rate := range rates {
+		p := pair{base: rate.Base, currency: rate.Currency}
+		t.rates[p] = append(t.rates[p], dayRate{day: entity.Day(rate.Day), rate: rate.Rate})
+		if !slices.Contains(t.bases, rate.Base) {
+			t.bases = append(t.bases, rate.Base)
+		}
+	}
+	for p, days := range t.rates {
+		slices.SortStableFunc(days, func(a, b dayRate) int { return a.day.Compare(b.day) })
+		t.rates[p] = days
+	}
+	slices.Sort(t.bases)
+	return t
+}
+
+// Rate возвращает, сколько единиц to стоит одна единица from на день day
+func (t *Table) Rate(from, to string, day time.Time) (*big.Rat, error) {
+	day = entity.Day(day)
+	if r, ok := t.quote(from, to, day); ok {
+		return r, nil
+	}
+	if r, ok := t.quote(to, from, day); ok {
+		return new(big.Rat).Inv(r), nil
+	}
+	for _, base := range t.bases {
+		toFrom, okFrom := t.quote(base, from, day)
+		toTo, okTo := t.quote(base, to, day)
+		if okFrom && okTo {
+			return new(big.Rat).Quo(toTo, toFrom), nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s/%s on %s", ErrNoRate, from, to, day.Format(time.DateOnly))
+}
+
+// Convert пересчитывает amount из from в to по курсу на день day с округлением
+// до минорной единицы to
+func (t *Table) Convert(amount entity.Money, from, to string, day time.Time) (entity.Money, error) {
+	rate, err := t.Rate(from, to, day)
+	if err != nil {
+		return entity.Money{}, err
+	}
+	return amount.Convert(rate, to)
+}
+
+// quote возвращает прямой курс пары на день или последний опубликованный
+// не раньше чем за MaxRateAgeDays до него
+func (t *Table) quote(base, currency string, day time.Time) (*big.Rat, bool) {
+	if base == currency {
+		return big.NewRat(1, 1), true
+	}
+	days := t.rates[pair{base: base, currency: currency}]
+	i := sort.Search(len(days), func(i int) bool { return days[i].day.After(day) })
+	if i == 0 || days[i-1].day.Before(day.AddDate(0, 0, -MaxRateAgeDays)) {
+		return nil, false
+	}
+	return days[i-1].rate, true
+}
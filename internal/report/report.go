@@ -0,0 +1,219 @@
+package report
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"order/internal/entity"
+	"order/internal/rates"
+	"order/internal/storage"
+	"slices"
+	"time"
+)
+
+// Group — период, по которому суммируется выручка
+type Group string
+
+const (
+	GroupDay   Group = "day"
+	GroupMonth Group = "month"
+	GroupTotal Group = "total" // один период на весь отчёт
+)
+
+// MaxDays — наибольшая длина периода отчёта
+const MaxDays = 731
+
+// ErrInvalidQuery возвращается для неизвестной валюты или группировки и неверного периода
+var ErrInvalidQuery = errors.New("invalid report query")
+
+// Query — отчёт о выручке в валюте Currency за дни с From по To включительно (UTC)
+type Query struct {
+	Currency string
+	From, To time.Time
+	// Group — группировка; пусто — GroupDay
+	Group Group
+}
+
+func (q Query) Validate() error {
+	if _, err := entity.CurrencyExponent(q.Currency); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+	}
+	if q.From.IsZero() || q.To.IsZero() {
+		return fmt.Errorf("%w: from and to are required", ErrInvalidQuery)
+	}
+	from, to := entity.Day(q.From), entity.Day(q.To)
+	if to.Before(from) {
+		return fmt.Errorf("%w: to is before from", ErrInvalidQuery)
+	}
+	if to.After(from.AddDate(0, 0, MaxDays-1)) {
+		return fmt.Errorf("%w: period is longer than %d days", ErrInvalidQuery, MaxDays)
+	}
+	switch q.Group {
+	case "", GroupDay, GroupMonth, GroupTotal:
+	default:
+		return fmt.Errorf("%w: unknown group %q", ErrInvalidQuery, q.Group)
+	}
+	return nil
+}
+
+// Source — данные для отчёта (storage.Store)
+type Source interface {
+	Rates(ctx context.Context, from, to time.Time) ([]entity.ExchangeRate, error)
+	DailyRevenue(ctx context.Context, from, to time.Time) ([]storage.DailyRevenue, error)
+}
+
+// Revenue — выручка в валюте отчёта. Каждая оплата пересчитывается по курсу дня оплаты
+// (см. rates.Table); суммы без курса в итоги не входят и перечисляются в Unconverted
+type Revenue struct {
+	Currency    string        `json:"currency"`
+	From        string        `json:"from"` // YYYY-MM-DD
+	To          string        `json:"to"`
+	Group       Group         `json:"group"`
+	Orders      int           `json:"orders"`
+	Total       entity.Money  `json:"total"`
+	Periods     []Period      `json:"periods"` // только периоды с оплатами
+	Unconverted []Unconverted `json:"unconverted,omitempty"`
+}
+
+// Period — выручка за день, месяц или весь отчёт
+type Period struct {
+	Start      string          `json:"start"` // первый день периода, YYYY-MM-DD
+	Orders     int             `json:"orders"`
+	Total      entity.Money    `json:"total"`
+	Currencies []CurrencyTotal `json:"currencies"`
+}
+
+// CurrencyTotal — оплаты периода в одной валюте и их сумма в валюте отчёта
+type CurrencyTotal struct {
+	Currency  string       `json:"currency"`
+	Orders    int          `json:"orders"`
+	Amount    entity.Money `json:"amount"`
+	Converted entity.Money `json:"converted"`
+}
+
+// Unconverted — оплаты дня, которые не удалось пересчитать
+type Unconverted struct {
+	Day      string       `json:"day"`
+	Currency string       `json:"currency"`
+	Orders   int          `json:"orders"`
+	Amount   entity.Money `json:"amount"`
+	Reason   string       `json:"reason"`
+}
+
+// BuildRevenue строит отчёт о выручке. Курсы читаются с запасом в rates.MaxRateAgeDays
+// до начала периода: в его первый день может действовать курс предыдущей недели
+func BuildRevenue(ctx context.Context, src Source, q Query) (Revenue, error) {
+	if err := q.Validate(); err != nil {
+		return Revenue{}, err
+	}
+	if q.Group == "" {
+		q.Group = GroupDay
+	}
+	from, to := entity.Day(q.From), entity.Day(q.To)
+
+	daily, err := src.DailyRevenue(ctx, from, to)
+	if err != nil {
+		return Revenue{}, err
+	}
+	quotes, err := src.Rates(ctx, from.AddDate(0, 0, -rates.MaxRateAgeDays), to)
+	if err != nil {
+		return Revenue{}, err
+	}
+	table := rates.NewTable(quotes)
+
+	report := Revenue{
+		Currency: q.Currency,
+		From:     from.Format(time.DateOnly),
+		To:       to.Format(time.DateOnly),
+		Group:    q.Group,
+	}
+	periods := make(map[time.Time]*period)
+	for _, day := range daily {
+		converted, err := table.Convert(day.Amount, day.Currency, q.Currency, day.Day)
+		if errors.Is(err, rates.ErrNoRate) || errors.Is(err, entity.ErrUnknownCurrency) {
+			report.Unconverted = append(report.Unconverted, Unconverted{
+				Day:      day.Day.Format(time.DateOnly),
+				Currency: day.Currency,
+				Orders:   day.Orders,
+				Amount:   day.Amount,
+				Reason:   err.Error(),
+			})
+			continue
+		}
+		if err != nil {
+			return Revenue{}, err
+		}
+
+		start := periodStart(day.Day, q.Group, from)
+		p, ok := periods[start]
+		if !ok {
+			p = &period{start: start, currencies: make(map[string]*CurrencyTotal)}
+			periods[start] = p
+		}
+		if err := p.add(day, converted); err != nil {
+			return Revenue{}, err
+		}
+		if report.Total, err = report.Total.Add(converted); err != nil {
+			return Revenue{}, err
+		}
+		report.Orders += day.Orders
+	}
+
+	report.Periods = make([]Period, 0, len(periods))
+	for _, p := range periods {
+		report.Periods = append(report.Periods, p.result())
+	}
+	slices.SortFunc(report.Periods, func(a, b Period) int { return cmp.Compare(a.Start, b.Start) })
+	return report, nil
+}
+
+// periodStart возвращает первый день периода, в который попадает day
+func periodStart(day time.Time, group Group, from time.Time) time.Time {
+	switch group {
+	case GroupMonth:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case GroupTotal:
+		return from
+	default:
+		return day
+	}
+}
+
+// period накапливает суммы периода по валютам
+type period struct {
+	start      time.Time
+	orders     int
+	total      entity.Money
+	currencies map[string]*CurrencyTotal
+}
+
+func (p *period) add(day storage.DailyRevenue, converted entity.Money) error {
+	c, ok := p.currencies[day.Currency]
+	if !ok {
+		c = &CurrencyTotal{Currency: day.Currency}
+		p.currencies[day.Currency] = c
+	}
+	var err error
+	if c.Amount, err = c.Amount.Add(day.Amount); err != nil {
+		return err
+	}
+	if c.Converted, err = c.Converted.Add(converted); err != nil {
+		return err
+	}
+	if p.total, err = p.total.Add(converted); err != nil {
+		return err
+	}
+	c.Orders += day.Orders
+	p.orders += day.Orders
+	return nil
+}
+
+func (p *period) result() Period {
+	result := Period{Start: p.start.Format(time.DateOnly), Orders: p.orders, Total: p.total}
+	for _, c := range p.currencies {
+		result.Currencies = append(result.Currencies, *c)
+	}
+	slices.SortFunc(result.Currencies, func(a, b CurrencyTotal) int { return cmp.Compare(a.Currency, b.Currency) })
+	return result
+}
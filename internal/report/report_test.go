@@ -0,0 +1,85 @@
+package report
+
+import (
+    "context"
+    "order/internal/entity"
+    "order/internal/storage/memory"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func paidOrder(uid, currency, amount string, paidAt time.Time) entity.Order {
+    m, err := entity.ParseMoney(amount)
+    if err != nil {
+        panic(err)
+    }
+    return entity.Order{OrderUID: uid, DateCreated: paidAt, Payment: entity.Payment{Currency: currency, Amount: m, PaymentDt: paidAt}}
+}
+
+func TestBuildRevenue(t *testing.T) {
+    ctx := context.Background()
+    day := func(d int, hour int) time.Time { return time.Date(2024, 3, d, hour, 0, 0, 0, time.UTC) }
+
+    store := memory.NewStore()
+    require.NoError(t, store.SaveOrders(ctx, []entity.Order{
+        paidOrder("usd-1", "USD", "100", day(1, 10)),
+        paidOrder("eur-1", "EUR", "50", day(1, 12)),
+        paidOrder("eur-2", "EUR", "10", day(4, 9)), // понедельник: курс за 4-е
+        paidOrder("rub-1", "RUB", "1000", day(2, 8)), // суббота: курс пятницы
+        paidOrder("gbp-1", "GBP", "20", day(2, 9)),   // курса GBP нет
+        paidOrder("old-1", "USD", "999", day(29, 0).AddDate(0, -1, 0)),
+    }))
+    var quotes []entity.ExchangeRate
+    for _, r := range []struct {
+        day            int
+        currency, rate string
+    }{{1, "USD", "1.08"}, {1, "RUB", "100"}, {4, "USD", "1.10"}} {
+        rate, err := entity.NewExchangeRate(day(r.day, 0), "EUR", r.currency, r.rate)
+        require.NoError(t, err)
+        quotes = append(quotes, rate)
+    }
+    require.NoError(t, store.SaveRates(ctx, quotes))
+
+    t.Run("By day", func(t *testing.T) {
+        report, err := BuildRevenue(ctx, store, Query{Currency: "USD", From: day(1, 0), To: day(4, 0)})
+        require.NoError(t, err)
+
+        assert.Equal(t, GroupDay, report.Group)
+        assert.Equal(t, "2024-03-01", report.From)
+        assert.Equal(t, 4, report.Orders)
+        // 100 + 50×1.08 + 1000/100×1.08 + 10×1.10
+        assert.Equal(t, "175.8", report.Total.String())
+        require.Len(t, report.Periods, 3)
+        assert.Equal(t, Period{Start: "2024-03-01", Orders: 2, Total: entity.NewMoney(154), Currencies: []CurrencyTotal{
+            {Currency: "EUR", Orders: 1, Amount: entity.NewMoney(50), Converted: entity.NewMoney(54)},
+            {Currency: "USD", Orders: 1, Amount: entity.NewMoney(100), Converted: entity.NewMoney(100)},
+        }}, report.Periods[0])
+        assert.Equal(t, "10.8", report.Periods[1].Total.String())
+        assert.Equal(t, "11", report.Periods[2].Total.String())
+
+        require.Len(t, report.Unconverted, 1)
+        assert.Equal(t, "GBP", report.Unconverted[0].Currency)
+        assert.Equal(t, "2024-03-02", report.Unconverted[0].Day)
+    })
+
+    t.Run("By month in another currency", func(t *testing.T) {
+        report, err := BuildRevenue(ctx, store, Query{Currency: "EUR", From: day(1, 0), To: day(31, 0), Group: GroupMonth})
+        require.NoError(t, err)
+        require.Len(t, report.Periods, 1)
+        assert.Equal(t, "2024-03-01", report.Periods[0].Start)
+        // 100/1.08 + 50 + 1000/100 + 10
+        assert.Equal(t, "162.59", report.Total.String())
+    })
+
+    t.Run("Invalid query", func(t *testing.T) {
+        _, err := BuildRevenue(ctx, store, Query{Currency: "XYZ", From: day(1, 0), To: day(4, 0)})
+        assert.ErrorIs(t, err, ErrInvalidQuery)
+        _, err = BuildRevenue(ctx, store, Query{Currency: "USD", From: day(4, 0), To: day(1, 0)})
+        assert.ErrorIs(t, err, ErrInvalidQuery)
+        _, err = BuildRevenue(ctx, store, Query{Currency: "USD", From: day(1, 0), To: day(4, 0), Group: "week"})
+        assert.ErrorIs(t, err, ErrInvalidQuery)
+    })
+}
@@ -0,0 +1,240 @@
+package restore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"order/internal/backfill"
+	"order/internal/entity"
+	"order/internal/export"
+	"order/internal/service"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RejectConflict — заказ уже есть в хранилище, а политика конфликтов service.ConflictFail.
+// Остальные причины отклонения — как у backfill
+const RejectConflict = "conflict"
+
+type Options struct {
+	// Формат входов: export.FormatCSV или export.FormatNDJSON, в раскладке Export
+	Format export.Format
+	// Что делать с заказами, которые уже есть в хранилище
+	Conflict service.Conflict
+	// Только проверить входы и конфликты, ничего не записывая
+	DryRun bool
+	// Размер пачки, записываемой одной транзакцией
+	BatchSize int
+	// Интервал отчёта о прогрессе в логе; 0 — без отчётов
+	Progress time.Duration
+	// Куда писать отклонённые заказы в формате NDJSON (backfill.Reject); nil — не писать
+	Rejects io.Writer
+}
+
+func (o Options) Validate() error {
+	if o.Format != export.FormatCSV && o.Format != export.FormatNDJSON {
+		return fmt.Errorf("%w: cannot import format %q", export.ErrInvalidOptions, o.Format)
+	}
+	if !slices.Contains(service.Conflicts, o.Conflict) {
+		return fmt.Errorf("%w: unknown conflict policy %q", export.ErrInvalidOptions, o.Conflict)
+	}
+	return nil
+}
+
+// Summary — итог прогона; при DryRun New и Updated — сколько заказов было бы записано
+type Summary struct {
+	DryRun        bool             `json:"dry_run"`
+	Inputs        int              `json:"inputs"`
+	Orders        int64            `json:"orders"`
+	New           int64            `json:"new"`
+	Updated       int64            `json:"updated"`
+	Skipped       int64            `json:"skipped"`
+	Rejected      int64            `json:"rejected"`
+	RejectsByKind map[string]int64 `json:"rejects_by_kind"`
+	// Строки без order_uid (в том числе нечитаемые строки CSV): их не к чему отнести,
+	// поэтому в Orders и Rejected они не входят, но попадают в отчёт об отклонениях
+	UnidentifiedRows int64         `json:"unidentified_rows"`
+	Duration         time.Duration `json:"duration"`
+}
+
+func (s Summary) String() string {
+	var b strings.Builder
+	if s.DryRun {
+		b.WriteString("dry run, nothing written; ")
+	}
+	fmt.Fprintf(&b, "inputs: %d, orders: %d, new: %d, updated: %d, skipped: %d, rejected: %d, took %s",
+		s.Inputs, s.Orders, s.New, s.Updated, s.Skipped, s.Rejected, s.Duration.Round(time.Millisecond))
+	kinds := make([]string, 0, len(s.RejectsByKind))
+	for kind := range s.RejectsByKind {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		fmt.Fprintf(&b, "\n  rejected (%s): %d", kind, s.RejectsByKind[kind])
+	}
+	if s.UnidentifiedRows > 0 {
+		fmt.Fprintf(&b, "\n  rows without order_uid: %d", s.UnidentifiedRows)
+	}
+	return b.String()
+}
+
+// Restore загружает выгрузки Export обратно в хранилище через service.ImportOrders:
+// с той же валидацией, что и консюмер, и с выбранной политикой конфликтов
+type Restore struct {
+	service service.Service
+	opts    Options
+	rejects *json.Encoder
+
+	summary      Summary
+	start        time.Time
+	lastProgress time.Time
+}
+
+func New(service service.Service, opts Options) *Restore {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	r := &Restore{service: service, opts: opts}
+	if opts.Rejects != nil {
+		r.rejects = json.NewEncoder(opts.Rejects)
+	}
+	return r
+}
+
+// batch — заказы входа, которые записываются одной транзакцией
+type batch struct {
+	input   string
+	orders  []entity.Order
+	lines   []int64
+	rejects []backfill.Reject
+}
+
+// Run обрабатывает входы по порядку. При ошибке хранилища, чтения входа или отмене контекста
+// прогон прерывается; уже записанные пачки остаются, и повторный запуск с ConflictSkip
+// или ConflictOverwrite догрузит остальное
+func (r *Restore) Run(ctx context.Context, inputs []backfill.Input) (Summary, error) {
+	r.summary = Summary{DryRun: r.opts.DryRun, RejectsByKind: make(map[string]int64)}
+	r.start = time.Now()
+	r.lastProgress = r.start
+	if err := r.opts.Validate(); err != nil {
+		return r.summary, err
+	}
+
+	for _, in := range inputs {
+		r.summary.Inputs++
+		if err := r.runInput(ctx, in); err != nil {
+			r.summary.Duration = time.Since(r.start)
+			return r.summary, err
+		}
+	}
+	r.summary.Duration = time.Since(r.start)
+	return r.summary, nil
+}
+
+func (r *Restore) runInput(ctx context.Context, in backfill.Input) error {
+	rc, err := in.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", in.Name, err)
+	}
+	defer rc.Close()
+
+	current := &batch{input: in.Name}
+	for record, err := range export.Read(rc, r.opts.Format) {
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", in.Name, err)
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if record.Order.OrderUID != "" {
+			r.summary.Orders++
+		}
+		if record.Err != nil {
+			current.rejects = append(current.rejects, backfill.Reject{
+				Input: in.Name, Line: record.Line, OrderUID: record.Order.OrderUID,
+				Kind: backfill.RejectDecode, Reason: record.Err.Error(),
+			})
+			continue
+		}
+		current.orders = append(current.orders, record.Order)
+		current.lines = append(current.lines, record.Line)
+
+		if len(current.orders) >= r.opts.BatchSize {
+			if err := r.flush(ctx, current); err != nil {
+				return err
+			}
+			current = &batch{input: in.Name}
+		}
+	}
+	return r.flush(ctx, current)
+}
+
+// flush записывает пачку (или только проверяет при DryRun) и пишет отклонения
+func (r *Restore) flush(ctx context.Context, current *batch) error {
+	if len(current.orders) > 0 {
+		results, err := r.service.ImportOrders(ctx, current.orders, r.opts.Conflict, r.opts.DryRun)
+		if err != nil {
+			return fmt.Errorf("failed to import batch from %s at line %d: %w", current.input, current.lines[0], err)
+		}
+		for i, result := range results {
+			switch result.Outcome {
+			case service.OutcomeNew:
+				r.summary.New++
+				continue
+			case service.OutcomeUpdated:
+				r.summary.Updated++
+				continue
+			case service.OutcomeSkipped:
+				r.summary.Skipped++
+				continue
+			}
+			kind := backfill.RejectStorage
+			switch {
+			case errors.Is(result.Err, service.ErrInvalidOrder):
+				kind = backfill.RejectInvalid
+			case errors.Is(result.Err, service.ErrConflict):
+				kind = RejectConflict
+			}
+			current.rejects = append(current.rejects, backfill.Reject{
+				Input:    current.input,
+				Line:     current.lines[i],
+				OrderUID: current.orders[i].OrderUID,
+				Kind:     kind,
+				Reason:   result.Err.Error(),
+			})
+		}
+	}
+
+	sort.Slice(current.rejects, func(i, j int) bool { return current.rejects[i].Line < current.rejects[j].Line })
+	for _, reject := range current.rejects {
+		if reject.OrderUID == "" {
+			r.summary.UnidentifiedRows++
+		} else {
+			r.summary.Rejected++
+			r.summary.RejectsByKind[reject.Kind]++
+		}
+		if r.rejects != nil {
+			if err := r.rejects.Encode(reject); err != nil {
+				return fmt.Errorf("failed to write rejects report: %w", err)
+			}
+		}
+	}
+	r.reportProgress(current.input)
+	return nil
+}
+
+func (r *Restore) reportProgress(input string) {
+	if r.opts.Progress <= 0 || time.Since(r.lastProgress) < r.opts.Progress {
+		return
+	}
+	r.lastProgress = time.Now()
+	elapsed := time.Since(r.start).Seconds()
+	log.Printf("Restore progress: %s, orders %d, new %d, updated %d, skipped %d, rejected %d (%.0f orders/s)",
+		input, r.summary.Orders, r.summary.New, r.summary.Updated, r.summary.Skipped, r.summary.Rejected,
+		float64(r.summary.Orders)/elapsed)
+}
@@ -0,0 +1,201 @@
+package restore
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "order/internal/backfill"
+    "order/internal/entity"
+    "order/internal/export"
+    "order/internal/service"
+    "order/internal/storage"
+    "order/internal/storage/memory"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+var testOrders = []entity.Order{
+    {OrderUID: "uid-1", Delivery: entity.Delivery{Name: "Test Testov"}, Items: []entity.Item{{ChrtID: 1, Name: "Mascaras"}, {ChrtID: 2}}},
+    {OrderUID: "uid-2", Payment: entity.Payment{Amount: entity.NewMoney(10)}},
+    {OrderUID: "uid-3", Items: []entity.Item{{ChrtID: 3}}},
+}
+
+// dump выгружает заказы в файл и возвращает его как вход
+func dump(t *testing.T, orders []entity.Order, format export.Format) []backfill.Input {
+    store := memory.NewStore()
+    require.NoError(t, store.SaveOrders(context.Background(), orders))
+    var buf bytes.Buffer
+    _, err := export.Export(&buf, store.Orders(context.Background(), storage.Filter{}), export.Options{Format: format})
+    require.NoError(t, err)
+
+    path := filepath.Join(t.TempDir(), "orders."+string(format))
+    require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+    inputs, err := backfill.Inputs([]string{path}, nil)
+    require.NoError(t, err)
+    return inputs
+}
+
+func TestRestore_RoundTrip(t *testing.T) {
+    for _, format := range []export.Format{export.FormatCSV, export.FormatNDJSON} {
+        t.Run(string(format), func(t *testing.T) {
+            ctx := context.Background()
+            store := memory.NewStore()
+
+            summary, err := New(service.NewService(store), Options{Format: format, Conflict: service.ConflictFail, BatchSize: 2}).
+                Run(ctx, dump(t, testOrders, format))
+            require.NoError(t, err)
+            assert.Equal(t, int64(3), summary.Orders)
+            assert.Equal(t, int64(3), summary.New)
+            assert.Equal(t, int64(0), summary.Rejected)
+
+            restored, err := storage.Collect(store.Orders(ctx, storage.Filter{}))
+            require.NoError(t, err)
+            assert.Equal(t, testOrders, restored)
+        })
+    }
+}
+
+func TestRestore_Conflicts(t *testing.T) {
+    ctx := context.Background()
+    changed := testOrders[0]
+    changed.TrackNumber = "CHANGED"
+    inputs := dump(t, append([]entity.Order{changed}, testOrders[1:]...), export.FormatNDJSON)
+
+    seed := func() storage.Store {
+        store := memory.NewStore()
+        require.NoError(t, store.SaveOrders(ctx, testOrders[:2]))
+        return store
+    }
+
+    t.Run("Dry run", func(t *testing.T) {
+        store := seed()
+        summary, err := New(service.NewService(store), Options{Format: export.FormatNDJSON, Conflict: service.ConflictOverwrite, DryRun: true}).
+            Run(ctx, inputs)
+        require.NoError(t, err)
+        assert.True(t, summary.DryRun)
+        assert.Equal(t, int64(1), summary.New)
+        assert.Equal(t, int64(2), summary.Updated)
+
+        _, err = store.GetOrder(ctx, "uid-3")
+        assert.ErrorIs(t, err, storage.ErrNotFound)
+        order, err := store.GetOrder(ctx, "uid-1")
+        require.NoError(t, err)
+        assert.Empty(t, order.TrackNumber)
+    })
+
+    t.Run("Skip", func(t *testing.T) {
+        store := seed()
+        summary, err := New(service.NewService(store), Options{Format: export.FormatNDJSON, Conflict: service.ConflictSkip}).
+            Run(ctx, inputs)
+        require.NoError(t, err)
+        assert.Equal(t, int64(1), summary.New)
+        assert.Equal(t, int64(2), summary.Skipped)
+
+        order, err := store.GetOrder(ctx, "uid-1")
+        require.NoError(t, err)
+        assert.Empty(t, order.TrackNumber)
+    })
+
+    t.Run("Overwrite", func(t *testing.T) {
+        store := seed()
+        summary, err := New(service.NewService(store), Options{Format: export.FormatNDJSON, Conflict: service.ConflictOverwrite}).
+            Run(ctx, inputs)
+        require.NoError(t, err)
+        assert.Equal(t, int64(2), summary.Updated)
+
+        order, err := store.GetOrder(ctx, "uid-1")
+        require.NoError(t, err)
+        assert.Equal(t, "CHANGED", order.TrackNumber)
+    })
+
+    t.Run("Fail", func(t *testing.T) {
+        var rejects bytes.Buffer
+        summary, err := New(service.NewService(seed()), Options{Format: export.FormatNDJSON, Conflict: service.ConflictFail, Rejects: &rejects}).
+            Run(ctx, inputs)
+        require.NoError(t, err)
+        assert.Equal(t, int64(1), summary.New)
+        assert.Equal(t, map[string]int64{RejectConflict: 2}, summary.RejectsByKind)
+
+        var first backfill.Reject
+        require.NoError(t, json.NewDecoder(&rejects).Decode(&first))
+        assert.Equal(t, "uid-1", first.OrderUID)
+        assert.Equal(t, int64(1), first.Line)
+        assert.Equal(t, RejectConflict, first.Kind)
+    })
+}
+
+func TestRestore_Rejects(t *testing.T) {
+    ctx := context.Background()
+    path := filepath.Join(t.TempDir(), "orders.csv")
+    data := "order_uid,items_count,item_chrt_id\n" +
+        "uid-1,2,1\n" +
+        "uid-1,2,2\n" +
+        "uid-2,1,\n" +
+        "uid-3,1,abc\n"
+    require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+    inputs, err := backfill.Inputs([]string{path}, nil)
+    require.NoError(t, err)
+
+    var rejects bytes.Buffer
+    summary, err := New(service.NewService(memory.NewStore()), Options{Format: export.FormatCSV, Conflict: service.ConflictSkip, Rejects: &rejects}).
+        Run(ctx, inputs)
+    require.NoError(t, err)
+    assert.Equal(t, int64(3), summary.Orders)
+    assert.Equal(t, int64(1), summary.New)
+    assert.Equal(t, map[string]int64{backfill.RejectDecode: 2}, summary.RejectsByKind)
+
+    lines := strings.Split(strings.TrimSpace(rejects.String()), "\n")
+    require.Len(t, lines, 2)
+    assert.Contains(t, lines[0], `"line":4`)
+    assert.Contains(t, lines[0], "items_count is 1, but 0 items found")
+    assert.Contains(t, lines[1], `"order_uid":"uid-3"`)
+}
+
+func TestRestore_UnidentifiedRows(t *testing.T) {
+    ctx := context.Background()
+    path := filepath.Join(t.TempDir(), "orders.csv")
+    data := "order_uid,item_chrt_id\n" +
+        "uid-1,1\n" +
+        "uid-1,2\"\n" + // Кавычка внутри поля: строка не читается, order_uid неизвестен
+        "uid-2,3\n" +
+        ",4\n"
+    require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+    inputs, err := backfill.Inputs([]string{path}, nil)
+    require.NoError(t, err)
+
+    var rejects bytes.Buffer
+    summary, err := New(service.NewService(memory.NewStore()), Options{Format: export.FormatCSV, Conflict: service.ConflictSkip, Rejects: &rejects}).
+        Run(ctx, inputs)
+    require.NoError(t, err)
+    assert.Equal(t, int64(2), summary.Orders)
+    assert.Equal(t, int64(2), summary.New)
+    assert.Equal(t, int64(0), summary.Rejected)
+    assert.Equal(t, int64(2), summary.UnidentifiedRows)
+    assert.Contains(t, summary.String(), "rows without order_uid: 2")
+
+    lines := strings.Split(strings.TrimSpace(rejects.String()), "\n")
+    require.Len(t, lines, 2)
+    assert.Contains(t, lines[0], `"line":3`)
+    assert.Contains(t, lines[1], `"line":5`)
+    assert.NotContains(t, rejects.String(), `"order_uid":`)
+}
+
+func TestRestore_InvalidInput(t *testing.T) {
+    ctx := context.Background()
+    path := filepath.Join(t.TempDir(), "orders.csv")
+    require.NoError(t, os.WriteFile(path, []byte("secret\n1\n"), 0o644))
+    inputs, err := backfill.Inputs([]string{path}, nil)
+    require.NoError(t, err)
+
+    _, err = New(service.NewService(memory.NewStore()), Options{Format: export.FormatParquet, Conflict: service.ConflictSkip}).Run(ctx, inputs)
+    assert.ErrorIs(t, err, export.ErrInvalidOptions)
+
+    _, err = New(service.NewService(memory.NewStore()), Options{Format: export.FormatCSV, Conflict: service.ConflictSkip}).Run(ctx, inputs)
+    assert.ErrorIs(t, err, export.ErrInvalidOptions)
+    assert.ErrorContains(t, err, path)
+}
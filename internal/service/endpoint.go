@@ -2,11 +2,84 @@ package service
 
 import (
 	"context"
+	"errors"
+	"iter"
 	"order/internal/entity"
+	"order/internal/report"
+	"order/internal/storage"
 )
 
+// ErrInvalidOrder оборачивает ошибки валидации заказа
+var ErrInvalidOrder = errors.New("invalid order")
+
+// Outcome описывает результат повторной обработки заказа
+type Outcome string
+
+const (
+	OutcomeNew       Outcome = "new"       // заказа не было в хранилище
+	OutcomeUpdated   Outcome = "updated"   // заказ был и отличался — перезаписан
+	OutcomeUnchanged Outcome = "unchanged" // заказ был и совпал с сохранённым
+	OutcomeRejected  Outcome = "rejected"  // заказ не прошёл валидацию
+	OutcomeSkipped   Outcome = "skipped"   // заказ был, импорт с ConflictSkip его не тронул
+)
+
+// Conflict — политика импорта для заказа, который уже есть в хранилище
+type Conflict string
+
+const (
+	ConflictSkip      Conflict = "skip"      // оставить сохранённый заказ
+	ConflictOverwrite Conflict = "overwrite" // заменить сохранённый заказ целиком
+	ConflictFail      Conflict = "fail"      // отклонить импортируемый заказ с ErrConflict
+)
+
+// Conflicts — все политики импорта
+var Conflicts = []Conflict{ConflictSkip, ConflictOverwrite, ConflictFail}
+
+// ErrConflict возвращается при импорте с ConflictFail для заказа, который уже есть в хранилище
+var ErrConflict = errors.New("order already exists")
+
+// ImportResult — итог импорта одного заказа; Err задан только для OutcomeRejected
+type ImportResult struct {
+	Outcome Outcome
+	Err     error
+}
+
 type Service interface {
 	ProcessOrder(ctx context.Context, order entity.Order) error
+	// ReprocessOrder перезаписывает и неполный заказ (без доставки или оплаты) — это OutcomeUpdated
+	ReprocessOrder(ctx context.Context, order entity.Order) (Outcome, error)
+	// ProcessBatch валидирует и сохраняет пачку заказов. Для каждого заказа возвращается
+	// его ошибка (ErrInvalidOrder или ошибка хранилища); общая ошибка означает, что
+	// хранилище недоступно и ни один заказ пачки не сохранён
+	ProcessBatch(ctx context.Context, orders []entity.Order) ([]error, error)
+	// ImportOrders валидирует пачку так же, как ProcessBatch, разрешает конфликты по policy
+	// (повтор order_uid внутри пачки — тоже конфликт) и записывает её одной транзакцией.
+	// С dryRun хранилище только читается. Общая ошибка означает, что хранилище недоступно
+	// и ни один заказ пачки не записан
+	ImportOrders(ctx context.Context, orders []entity.Order, policy Conflict, dryRun bool) ([]ImportResult, error)
 	GetOrder(ctx context.Context, orderUID string) (entity.Order, error)
+	// FindOrders ищет заказы по вторичному ключу. Ключ, все заказы которого уже в кэше
+	// и проверены по БД не раньше lookupTTL назад, обслуживается из кэша
+	FindOrders(ctx context.Context, lookup storage.Lookup) ([]entity.Order, error)
+	// SearchOrders — полнотекстовый поиск заказов; выполняется в БД, кэш не используется
+	SearchOrders(ctx context.Context, search storage.Search) (storage.SearchPage, error)
+	// Revenue строит отчёт о выручке в валюте отчёта по курсам из БД; кэш не используется
+	Revenue(ctx context.Context, q report.Query) (report.Revenue, error)
+	// IncompleteOrders перечисляет заказы без доставки или оплаты
+	IncompleteOrders(ctx context.Context) ([]storage.IncompleteOrder, error)
+	// Orders обходит заказы БД мимо кэша (см. storage.Store.Orders) — для выгрузок
+	Orders(ctx context.Context, filter storage.Filter) iter.Seq2[entity.Order, error]
 	LoadCacheFromDB(ctx context.Context) error
+	// Evict убирает заказ из кэша (например, после его изменения другим экземпляром)
+	Evict(orderUID string)
+	// Refresh перечитывает заказ из БД, если он есть в кэше; пропавший из БД заказ вытесняется
+	Refresh(ctx context.Context, orderUID string) error
+	// Resync заменяет содержимое кэша актуальными данными из БД
+	Resync(ctx context.Context) error
+	// FlushCache очищает кэш и возвращает число вытесненных заказов
+	FlushCache() int
+	CacheSize() int
+	// SetOwnership ограничивает кэш заказами, для которых owns возвращает true
+	// (nil — все заказы), и вытесняет остальные. С warm недостающие заказы догружаются из БД
+	SetOwnership(ctx context.Context, owns func(orderUID string) bool, warm bool) error
 }
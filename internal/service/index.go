@@ -0,0 +1,80 @@
+package service
+
+import (
+	"order/internal/entity"
+	"order/internal/storage"
+	"time"
+)
+
+// lookupTTL — сколько полный результат поиска считается актуальным. Заказы, сохранённые
+// другими экземплярами, в индекс не попадают, поэтому дольше доверять ему нельзя
+const lookupTTL = 30 * time.Second
+
+// index — вторичный индекс кэша: заказы по ключам поиска.
+// Защищён mu сервиса
+type index struct {
+	uids map[storage.LookupKey]map[string]struct{}
+	keys map[string][]storage.LookupKey // ключи, под которыми проиндексирован заказ
+	// complete — ключи, для которых в кэше лежат все найденные в БД заказы, и время проверки
+	complete map[storage.LookupKey]time.Time
+}
+
+func newIndex() *index {
+	idx := &index{}
+	idx.reset()
+	return idx
+}
+
+func (idx *index) reset() {
+	idx.uids = make(map[storage.LookupKey]map[string]struct{})
+	idx.keys = make(map[string][]storage.LookupKey)
+	idx.complete = make(map[storage.LookupKey]time.Time)
+}
+
+func (idx *index) add(order entity.Order) {
+	keys := storage.KeysOf(order)
+	for _, key := range keys {
+		set, ok := idx.uids[key]
+		if !ok {
+			set = make(map[string]struct{})
+			idx.uids[key] = set
+		}
+		set[order.OrderUID] = struct{}{}
+	}
+	idx.keys[order.OrderUID] = keys
+}
+
+// remove убирает заказ из индекса. С evicted ключи заказа перестают быть полными:
+// найденный по ним заказ больше не лежит в кэше
+func (idx *index) remove(orderUID string, evicted bool) {
+	for _, key := range idx.keys[orderUID] {
+		delete(idx.uids[key], orderUID)
+		if len(idx.uids[key]) == 0 {
+			delete(idx.uids, key)
+		}
+		if evicted {
+			delete(idx.complete, key)
+		}
+	}
+	delete(idx.keys, orderUID)
+}
+
+// find возвращает order_uid заказов по ключу, если индекс по нему полон и свеж
+func (idx *index) find(key storage.LookupKey, now time.Time) ([]string, bool) {
+	checked, ok := idx.complete[key]
+	if !ok || now.Sub(checked) > lookupTTL {
+		return nil, false
+	}
+	uids := make([]string, 0, len(idx.uids[key]))
+	for uid := range idx.uids[key] {
+		uids = append(uids, uid)
+	}
+	return uids, true
+}
+
+// markComplete отмечает ключ полным, если в индексе лежат все n найденных заказов
+func (idx *index) markComplete(key storage.LookupKey, n int, now time.Time) {
+	if len(idx.uids[key]) == n {
+		idx.complete[key] = now
+	}
+}
@@ -11,7 +11,11 @@ package mock
 
 import (
 	context "context"
+	iter "iter"
 	entity "order/internal/entity"
+	report "order/internal/report"
+	service "order/internal/service"
+	storage "order/internal/storage"
 	reflect "reflect"
 
 	gomock "go.uber.org/mock/gomock"
@@ -41,6 +45,61 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// CacheSize mocks base method.
+func (m *MockService) CacheSize() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CacheSize")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// CacheSize indicates an expected call of CacheSize.
+func (mr *MockServiceMockRecorder) CacheSize() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CacheSize", reflect.TypeOf((*MockService)(nil).CacheSize))
+}
+
+// Evict mocks base method.
+func (m *MockService) Evict(orderUID string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Evict", orderUID)
+}
+
+// Evict indicates an expected call of Evict.
+func (mr *MockServiceMockRecorder) Evict(orderUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Evict", reflect.TypeOf((*MockService)(nil).Evict), orderUID)
+}
+
+// FindOrders mocks base method.
+func (m *MockService) FindOrders(ctx context.Context, lookup storage.Lookup) ([]entity.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOrders", ctx, lookup)
+	ret0, _ := ret[0].([]entity.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOrders indicates an expected call of FindOrders.
+func (mr *MockServiceMockRecorder) FindOrders(ctx, lookup any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOrders", reflect.TypeOf((*MockService)(nil).FindOrders), ctx, lookup)
+}
+
+// FlushCache mocks base method.
+func (m *MockService) FlushCache() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FlushCache")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// FlushCache indicates an expected call of FlushCache.
+func (mr *MockServiceMockRecorder) FlushCache() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlushCache", reflect.TypeOf((*MockService)(nil).FlushCache))
+}
+
 // GetOrder mocks base method.
 func (m *MockService) GetOrder(ctx context.Context, orderUID string) (entity.Order, error) {
 	m.ctrl.T.Helper()
@@ -56,6 +115,36 @@ func (mr *MockServiceMockRecorder) GetOrder(ctx, orderUID any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockService)(nil).GetOrder), ctx, orderUID)
 }
 
+// ImportOrders mocks base method.
+func (m *MockService) ImportOrders(ctx context.Context, orders []entity.Order, policy service.Conflict, dryRun bool) ([]service.ImportResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportOrders", ctx, orders, policy, dryRun)
+	ret0, _ := ret[0].([]service.ImportResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportOrders indicates an expected call of ImportOrders.
+func (mr *MockServiceMockRecorder) ImportOrders(ctx, orders, policy, dryRun any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportOrders", reflect.TypeOf((*MockService)(nil).ImportOrders), ctx, orders, policy, dryRun)
+}
+
+// IncompleteOrders mocks base method.
+func (m *MockService) IncompleteOrders(ctx context.Context) ([]storage.IncompleteOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncompleteOrders", ctx)
+	ret0, _ := ret[0].([]storage.IncompleteOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncompleteOrders indicates an expected call of IncompleteOrders.
+func (mr *MockServiceMockRecorder) IncompleteOrders(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncompleteOrders", reflect.TypeOf((*MockService)(nil).IncompleteOrders), ctx)
+}
+
 // LoadCacheFromDB mocks base method.
 func (m *MockService) LoadCacheFromDB(ctx context.Context) error {
 	m.ctrl.T.Helper()
@@ -70,6 +159,35 @@ func (mr *MockServiceMockRecorder) LoadCacheFromDB(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadCacheFromDB", reflect.TypeOf((*MockService)(nil).LoadCacheFromDB), ctx)
 }
 
+// Orders mocks base method.
+func (m *MockService) Orders(ctx context.Context, filter storage.Filter) iter.Seq2[entity.Order, error] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Orders", ctx, filter)
+	ret0, _ := ret[0].(iter.Seq2[entity.Order, error])
+	return ret0
+}
+
+// Orders indicates an expected call of Orders.
+func (mr *MockServiceMockRecorder) Orders(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Orders", reflect.TypeOf((*MockService)(nil).Orders), ctx, filter)
+}
+
+// ProcessBatch mocks base method.
+func (m *MockService) ProcessBatch(ctx context.Context, orders []entity.Order) ([]error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessBatch", ctx, orders)
+	ret0, _ := ret[0].([]error)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProcessBatch indicates an expected call of ProcessBatch.
+func (mr *MockServiceMockRecorder) ProcessBatch(ctx, orders any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessBatch", reflect.TypeOf((*MockService)(nil).ProcessBatch), ctx, orders)
+}
+
 // ProcessOrder mocks base method.
 func (m *MockService) ProcessOrder(ctx context.Context, order entity.Order) error {
 	m.ctrl.T.Helper()
@@ -83,3 +201,90 @@ func (mr *MockServiceMockRecorder) ProcessOrder(ctx, order any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessOrder", reflect.TypeOf((*MockService)(nil).ProcessOrder), ctx, order)
 }
+
+// Refresh mocks base method.
+func (m *MockService) Refresh(ctx context.Context, orderUID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", ctx, orderUID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockServiceMockRecorder) Refresh(ctx, orderUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockService)(nil).Refresh), ctx, orderUID)
+}
+
+// ReprocessOrder mocks base method.
+func (m *MockService) ReprocessOrder(ctx context.Context, order entity.Order) (service.Outcome, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReprocessOrder", ctx, order)
+	ret0, _ := ret[0].(service.Outcome)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReprocessOrder indicates an expected call of ReprocessOrder.
+func (mr *MockServiceMockRecorder) ReprocessOrder(ctx, order any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReprocessOrder", reflect.TypeOf((*MockService)(nil).ReprocessOrder), ctx, order)
+}
+
+// Resync mocks base method.
+func (m *MockService) Resync(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resync", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Resync indicates an expected call of Resync.
+func (mr *MockServiceMockRecorder) Resync(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resync", reflect.TypeOf((*MockService)(nil).Resync), ctx)
+}
+
+// Revenue mocks base method.
+func (m *MockService) Revenue(ctx context.Context, q report.Query) (report.Revenue, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revenue", ctx, q)
+	ret0, _ := ret[0].(report.Revenue)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Revenue indicates an expected call of Revenue.
+func (mr *MockServiceMockRecorder) Revenue(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revenue", reflect.TypeOf((*MockService)(nil).Revenue), ctx, q)
+}
+
+// SearchOrders mocks base method.
+func (m *MockService) SearchOrders(ctx context.Context, search storage.Search) (storage.SearchPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchOrders", ctx, search)
+	ret0, _ := ret[0].(storage.SearchPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchOrders indicates an expected call of SearchOrders.
+func (mr *MockServiceMockRecorder) SearchOrders(ctx, search any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchOrders", reflect.TypeOf((*MockService)(nil).SearchOrders), ctx, search)
+}
+
+// SetOwnership mocks base method.
+func (m *MockService) SetOwnership(ctx context.Context, owns func(string) bool, warm bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOwnership", ctx, owns, warm)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetOwnership indicates an expected call of SetOwnership.
+func (mr *MockServiceMockRecorder) SetOwnership(ctx, owns, warm any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOwnership", reflect.TypeOf((*MockService)(nil).SetOwnership), ctx, owns, warm)
+}
@@ -2,10 +2,17 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"iter"
 	"log"
 	"order/internal/entity"
+	"order/internal/report"
 	"order/internal/storage"
+	"reflect"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	lru "github.com/hashicorp/golang-lru/v2"
@@ -14,25 +21,40 @@ import (
 type service struct {
 	store storage.Store
 	cache *lru.Cache[string, entity.Order]
+	index *index // вторичный индекс кэша для FindOrders; защищено mu
 	mu    sync.Mutex
+	owns  func(orderUID string) bool // nil — кэшируются все заказы; защищено mu
+	size  int                        // ёмкость кэша
+	now   func() time.Time
 }
 
 func NewService(store storage.Store) Service {
-	cache, err := lru.New[string, entity.Order](1000) // Лимит 1000 заказов
+	return newService(store, 1000) // Лимит 1000 заказов
+}
+
+func newService(store storage.Store, size int) *service {
+	s := &service{
+		store: store,
+		index: newIndex(),
+		size:  size,
+		now:   time.Now,
+	}
+	// Вытеснение из кэша (по лимиту, Remove или Purge) убирает заказ и из индекса
+	cache, err := lru.NewWithEvict(size, func(orderUID string, _ entity.Order) {
+		s.index.remove(orderUID, true)
+	})
 	if err != nil {
 		log.Fatalf("Failed to create LRU cache: %v", err)
 	}
-	return &service{
-		store: store,
-		cache: cache,
-	}
+	s.cache = cache
+	return s
 }
 
 func (s *service) ProcessOrder(ctx context.Context, order entity.Order) error {
 
 	// Валидация с использованием validator (order.OrderUID, order.Items, order.Delivery.Name, order.Delivery.Phone, order.Payment.Amount)
 	validate := validator.New()
-	if err := validate.Struct(order); err != nil {
+	if err := validateOrder(validate, order); err != nil {
 		log.Printf("Invalid order %s: %v", order.OrderUID, err)
 		return nil
 	}
@@ -44,13 +66,182 @@ func (s *service) ProcessOrder(ctx context.Context, order entity.Order) error {
 	}
 
 	// Обновление кэша
-	s.mu.Lock()
-	s.cache.Add(order.OrderUID, order)
-	s.mu.Unlock()
-	log.Printf("Order %s added to cache", order.OrderUID)
+	if s.addToCache(order) {
+		log.Printf("Order %s added to cache", order.OrderUID)
+	}
 	return nil
 }
 
+// ReprocessOrder повторно обрабатывает заказ (например, при replay из Kafka)
+// и сообщает, был ли он новым, изменённым, неизменным или отклонённым
+func (s *service) ReprocessOrder(ctx context.Context, order entity.Order) (Outcome, error) {
+	validate := validator.New()
+	if err := validateOrder(validate, order); err != nil {
+		log.Printf("Invalid order %s: %v", order.OrderUID, err)
+		return OutcomeRejected, nil
+	}
+
+	existing, err := s.store.GetOrder(ctx, order.OrderUID)
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		if err := s.store.SaveOrder(ctx, order); err != nil {
+			log.Printf("Failed to save order %s: %v", order.OrderUID, err)
+			return "", err
+		}
+		s.addToCache(order)
+		return OutcomeNew, nil
+	case errors.Is(err, storage.ErrIncomplete):
+		// Частично записанный заказ чинится полной перезаписью
+		if err := s.store.UpsertOrder(ctx, order); err != nil {
+			log.Printf("Failed to repair order %s: %v", order.OrderUID, err)
+			return "", err
+		}
+		s.addToCache(order)
+		log.Printf("Incomplete order %s repaired", order.OrderUID)
+		return OutcomeUpdated, nil
+	case err != nil:
+		log.Printf("Failed to get order %s from DB: %v", order.OrderUID, err)
+		return "", err
+	}
+
+	if sameOrder(existing, order) {
+		s.addToCache(existing)
+		return OutcomeUnchanged, nil
+	}
+
+	if err := s.store.UpsertOrder(ctx, order); err != nil {
+		log.Printf("Failed to update order %s: %v", order.OrderUID, err)
+		return "", err
+	}
+	s.addToCache(order)
+	log.Printf("Order %s updated", order.OrderUID)
+	return OutcomeUpdated, nil
+}
+
+func (s *service) ProcessBatch(ctx context.Context, orders []entity.Order) ([]error, error) {
+	results := make([]error, len(orders))
+	valid := make([]entity.Order, 0, len(orders))
+	index := make([]int, 0, len(orders))
+
+	validate := validator.New()
+	for i, order := range orders {
+		if err := validateOrder(validate, order); err != nil {
+			results[i] = fmt.Errorf("%w: %w", ErrInvalidOrder, err)
+			continue
+		}
+		valid = append(valid, order)
+		index = append(index, i)
+	}
+	if len(valid) == 0 {
+		return results, nil
+	}
+
+	err := s.store.SaveOrders(ctx, valid)
+	if err == nil {
+		for _, order := range valid {
+			s.addToCache(order)
+		}
+		return results, nil
+	}
+	log.Printf("Failed to save batch of %d orders, retrying one by one: %v", len(valid), err)
+
+	// Пачка откатилась — сохраняем по одному, чтобы найти заказы, которые ломают транзакцию
+	failed := 0
+	for i, order := range valid {
+		if err := s.store.SaveOrder(ctx, order); err != nil {
+			results[index[i]] = err
+			failed++
+			continue
+		}
+		s.addToCache(order)
+	}
+	// Если не сохранился ни один заказ, дело не в данных, а в хранилище
+	if failed == len(valid) {
+		return nil, fmt.Errorf("failed to save any order of the batch: %w", results[index[0]])
+	}
+	return results, nil
+}
+
+func (s *service) ImportOrders(ctx context.Context, orders []entity.Order, policy Conflict, dryRun bool) ([]ImportResult, error) {
+	if !slices.Contains(Conflicts, policy) {
+		return nil, fmt.Errorf("unknown conflict policy %q", policy)
+	}
+	results := make([]ImportResult, len(orders))
+	uids := make([]string, 0, len(orders))
+
+	validate := validator.New()
+	for i, order := range orders {
+		if err := validateOrder(validate, order); err != nil {
+			results[i] = ImportResult{Outcome: OutcomeRejected, Err: fmt.Errorf("%w: %w", ErrInvalidOrder, err)}
+			continue
+		}
+		uids = append(uids, order.OrderUID)
+	}
+	if len(uids) == 0 {
+		return results, nil
+	}
+
+	existing, err := s.store.ExistingOrders(ctx, uids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing orders: %w", err)
+	}
+	seen := make(map[string]bool, len(uids))
+	for _, uid := range existing {
+		seen[uid] = true
+	}
+
+	write := make([]entity.Order, 0, len(uids))
+	index := make([]int, 0, len(uids))
+	for i, order := range orders {
+		if results[i].Outcome == OutcomeRejected {
+			continue
+		}
+		exists := seen[order.OrderUID]
+		seen[order.OrderUID] = true
+		switch {
+		case !exists:
+			results[i].Outcome = OutcomeNew
+		case policy == ConflictSkip:
+			results[i].Outcome = OutcomeSkipped
+			continue
+		case policy == ConflictFail:
+			results[i] = ImportResult{Outcome: OutcomeRejected, Err: fmt.Errorf("order %s: %w", order.OrderUID, ErrConflict)}
+			continue
+		default:
+			results[i].Outcome = OutcomeUpdated
+		}
+		write = append(write, order)
+		index = append(index, i)
+	}
+	if dryRun || len(write) == 0 {
+		return results, nil
+	}
+
+	err = s.store.UpsertOrders(ctx, write)
+	if err == nil {
+		for _, order := range write {
+			s.addToCache(order)
+		}
+		return results, nil
+	}
+	log.Printf("Failed to import batch of %d orders, retrying one by one: %v", len(write), err)
+
+	// Как в ProcessBatch: по одному, чтобы найти заказы, которые ломают транзакцию
+	failed := 0
+	for i, order := range write {
+		if err := s.store.UpsertOrder(ctx, order); err != nil {
+			results[index[i]] = ImportResult{Outcome: OutcomeRejected, Err: err}
+			failed++
+			continue
+		}
+		s.addToCache(order)
+	}
+	if failed == len(write) {
+		return nil, fmt.Errorf("failed to import any order of the batch: %w", results[index[0]].Err)
+	}
+	return results, nil
+}
+
 func (s *service) GetOrder(ctx context.Context, orderUID string) (entity.Order, error) {
 	s.mu.Lock()
 	order, ok := s.cache.Get(orderUID)
@@ -66,25 +257,254 @@ func (s *service) GetOrder(ctx context.Context, orderUID string) (entity.Order,
 		return entity.Order{}, err
 	}
 
-	s.mu.Lock()
-	s.cache.Add(orderUID, order)
-	s.mu.Unlock()
-	log.Printf("Order %s fetched from DB and added to cache", orderUID)
+	if s.addToCache(order) {
+		log.Printf("Order %s fetched from DB and added to cache", orderUID)
+	} else {
+		log.Printf("Order %s fetched from DB (not owned by this instance)", orderUID)
+	}
 	return order, nil
 }
 
 func (s *service) LoadCacheFromDB(ctx context.Context) error {
-	orders, err := s.store.GetAllOrders(ctx)
+	for order, err := range s.store.Orders(ctx, storage.Filter{}) {
+		if err != nil {
+			log.Printf("Failed to load orders from DB: %v", err)
+			return err
+		}
+		s.addToCache(order)
+	}
+	log.Printf("Loaded %d orders into cache", s.cache.Len())
+	return nil
+}
+
+func (s *service) FindOrders(ctx context.Context, lookup storage.Lookup) ([]entity.Order, error) {
+	if err := lookup.Validate(); err != nil {
+		return nil, err
+	}
+	key := lookup.Key()
+
+	s.mu.Lock()
+	uids, ok := s.index.find(key, s.now())
+	var orders []entity.Order
+	for _, uid := range uids {
+		order, cached := s.cache.Get(uid)
+		if !cached {
+			ok = false
+			break
+		}
+		orders = append(orders, order)
+	}
+	s.mu.Unlock()
+	if ok {
+		storage.SortNewestFirst(orders)
+		if len(orders) > lookup.Size() {
+			orders = orders[:lookup.Size()]
+		}
+		log.Printf("%d orders by %s found in cache", len(orders), key.Field)
+		return orders, nil
+	}
+
+	orders, err := s.store.FindOrders(ctx, lookup)
 	if err != nil {
-		log.Printf("Failed to load orders from DB: %v", err)
-		return err
+		log.Printf("Failed to find orders by %s in DB: %v", key.Field, err)
+		return nil, err
 	}
 
 	s.mu.Lock()
+	owned := 0
 	for _, order := range orders {
-		s.cache.Add(order.OrderUID, order)
+		if s.owns == nil || s.owns(order.OrderUID) {
+			s.put(order)
+			owned++
+		}
+	}
+	// Ответ меньше лимита — значит, найдены все заказы; кэш отвечает за ключ, только если все они наши
+	if len(orders) < lookup.Size() && owned == len(orders) {
+		s.index.markComplete(key, len(orders), s.now())
 	}
 	s.mu.Unlock()
-	log.Printf("Loaded %d orders into cache", s.cache.Len())
+	return orders, nil
+}
+
+func (s *service) SearchOrders(ctx context.Context, search storage.Search) (storage.SearchPage, error) {
+	page, err := s.store.SearchOrders(ctx, search)
+	if err != nil {
+		log.Printf("Failed to search orders: %v", err)
+		return storage.SearchPage{}, err
+	}
+	return page, nil
+}
+
+func (s *service) Revenue(ctx context.Context, q report.Query) (report.Revenue, error) {
+	revenue, err := report.BuildRevenue(ctx, s.store, q)
+	if err != nil {
+		log.Printf("Failed to build revenue report: %v", err)
+		return report.Revenue{}, err
+	}
+	return revenue, nil
+}
+
+func (s *service) Orders(ctx context.Context, filter storage.Filter) iter.Seq2[entity.Order, error] {
+	return s.store.Orders(ctx, filter)
+}
+
+func (s *service) IncompleteOrders(ctx context.Context) ([]storage.IncompleteOrder, error) {
+	return s.store.IncompleteOrders(ctx)
+}
+
+func (s *service) Evict(orderUID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cache.Remove(orderUID) {
+		log.Printf("Order %s evicted from cache", orderUID)
+	}
+}
+
+func (s *service) Refresh(ctx context.Context, orderUID string) error {
+	s.mu.Lock()
+	cached := s.cache.Contains(orderUID)
+	s.mu.Unlock()
+	if !cached {
+		return nil
+	}
+
+	order, err := s.store.GetOrder(ctx, orderUID)
+	if err != nil {
+		// Устаревшая копия не должна пережить неудачное обновление
+		s.Evict(orderUID)
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if s.addToCache(order) {
+		log.Printf("Order %s refreshed in cache", orderUID)
+	}
+	return nil
+}
+
+func (s *service) Resync(ctx context.Context) error {
+	// Кэш заменяется только после успешного обхода. В нём остались бы последние size заказов,
+	// поэтому копятся только они — в кольцевом буфере
+	staged := make([]entity.Order, 0, s.size)
+	next := 0
+	for order, err := range s.store.Orders(ctx, storage.Filter{}) {
+		if err != nil {
+			log.Printf("Failed to load orders from DB: %v", err)
+			return err
+		}
+		if !s.owned(order.OrderUID) {
+			continue
+		}
+		if len(staged) < s.size {
+			staged = append(staged, order)
+			continue
+		}
+		staged[next] = order
+		next = (next + 1) % s.size
+	}
+
+	s.mu.Lock()
+	s.cache.Purge()
+	for _, order := range append(staged[next:], staged[:next]...) {
+		if s.owns == nil || s.owns(order.OrderUID) {
+			s.put(order)
+		}
+	}
+	n := s.cache.Len()
+	s.mu.Unlock()
+	log.Printf("Cache resynced with %d orders", n)
 	return nil
 }
+
+func (s *service) FlushCache() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := s.cache.Len()
+	s.cache.Purge()
+	log.Printf("Cache flushed, %d orders evicted", n)
+	return n
+}
+
+func (s *service) CacheSize() int {
+	return s.cache.Len()
+}
+
+func (s *service) SetOwnership(ctx context.Context, owns func(orderUID string) bool, warm bool) error {
+	s.mu.Lock()
+	s.owns = owns
+	// Полнота ключей зависела от прежнего распределения заказов
+	clear(s.index.complete)
+	evicted := 0
+	if owns != nil {
+		for _, uid := range s.cache.Keys() {
+			if !owns(uid) {
+				s.cache.Remove(uid)
+				evicted++
+			}
+		}
+	}
+	s.mu.Unlock()
+	if evicted > 0 {
+		log.Printf("Evicted %d orders not owned by this instance from cache", evicted)
+	}
+
+	if !warm {
+		return nil
+	}
+	return s.LoadCacheFromDB(ctx)
+}
+
+// addToCache кэширует заказ, если он принадлежит этому экземпляру
+func (s *service) addToCache(order entity.Order) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.owns != nil && !s.owns(order.OrderUID) {
+		return false
+	}
+	s.put(order)
+	return true
+}
+
+// owned сообщает, кэширует ли экземпляр заказ
+func (s *service) owned(orderUID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.owns == nil || s.owns(orderUID)
+}
+
+// put кладёт заказ в кэш и индекс; вызывается под mu.
+// Add существующего ключа не вызывает колбэк вытеснения, поэтому старые ключи снимаются явно
+func (s *service) put(order entity.Order) {
+	s.index.remove(order.OrderUID, false)
+	s.cache.Add(order.OrderUID, order)
+	s.index.add(order)
+}
+
+// validateOrder проверяет заказ тегами validate и денежные суммы: валюту по ISO 4217
+// и точность сумм (entity.Order.CheckMoney)
+func validateOrder(validate *validator.Validate, order entity.Order) error {
+	if err := validate.Struct(order); err != nil {
+		return err
+	}
+	return order.CheckMoney()
+}
+
+// sameOrder сравнивает заказы с учётом того, как они возвращаются из БД: время — момент
+// с точностью до микросекунды (TIMESTAMPTZ) в UTC, а пустой список товаров читается как nil
+func sameOrder(stored, incoming entity.Order) bool {
+	if !sameTime(stored.DateCreated, incoming.DateCreated) ||
+		!sameTime(stored.Payment.PaymentDt, incoming.Payment.PaymentDt) {
+		return false
+	}
+	stored.DateCreated, incoming.DateCreated = time.Time{}, time.Time{}
+	stored.Payment.PaymentDt, incoming.Payment.PaymentDt = time.Time{}, time.Time{}
+	if len(stored.Items) == 0 && len(incoming.Items) == 0 {
+		stored.Items, incoming.Items = nil, nil
+	}
+	return reflect.DeepEqual(stored, incoming)
+}
+
+func sameTime(a, b time.Time) bool {
+	return a.Round(time.Microsecond).Equal(b.Round(time.Microsecond))
+}
@@ -3,23 +3,36 @@ package service
 import (
     "context"
     "errors"
+    "fmt"
+    "iter"
     "order/internal/entity"
+    "order/internal/storage"
     "order/internal/storage/mock"
     "testing"
+    "time"
 
-    "github.com/hashicorp/golang-lru/v2"
     "github.com/stretchr/testify/assert"
     "go.uber.org/mock/gomock"
 )
 
+// ordersOf — последовательность заказов, как её отдаёт Store.Orders; err передаётся последним
+func ordersOf(orders []entity.Order, err error) iter.Seq2[entity.Order, error] {
+    return func(yield func(entity.Order, error) bool) {
+        for _, order := range orders {
+            if !yield(order, nil) {
+                return
+            }
+        }
+        if err != nil {
+            yield(entity.Order{}, err)
+        }
+    }
+}
+
 func setupService(t *testing.T) (*service, *mock.MockStore, *gomock.Controller) {
     ctrl := gomock.NewController(t)
     mockStore := mock.NewMockStore(ctrl)
-    cache, _ := lru.New[string, entity.Order](1000)
-    svc := &service{
-        store: mockStore,
-        cache: cache,
-    }
+    svc := newService(mockStore, 1000)
     return svc, mockStore, ctrl
 }
 
@@ -33,9 +46,9 @@ func TestService_ProcessOrder(t *testing.T) {
         order := entity.Order{
             OrderUID:    "test-uid",
             Delivery:    entity.Delivery{Name: "John", Phone: "1234567890"},
-            Payment:     entity.Payment{Amount: 1000},
-            Items:       []entity.Item{{ChrtID: 1, Price: 500}},
-            DateCreated: "2025-08-09T10:30:00Z",
+            Payment:     entity.Payment{Amount: entity.NewMoney(1000)},
+            Items:       []entity.Item{{ChrtID: 1, Price: entity.NewMoney(500)}},
+            DateCreated: time.Date(2025, 8, 9, 10, 30, 0, 0, time.UTC),
         }
         mockStore.EXPECT().SaveOrder(ctx, order).Return(nil)
 
@@ -67,9 +80,9 @@ func TestService_ProcessOrder(t *testing.T) {
         order := entity.Order{
             OrderUID:    "test-uid",
             Delivery:    entity.Delivery{Name: "John", Phone: "1234567890"},
-            Payment:     entity.Payment{Amount: 1000},
-            Items:       []entity.Item{{ChrtID: 1, Price: 500}},
-            DateCreated: "2025-08-09T10:30:00Z",
+            Payment:     entity.Payment{Amount: entity.NewMoney(1000)},
+            Items:       []entity.Item{{ChrtID: 1, Price: entity.NewMoney(500)}},
+            DateCreated: time.Date(2025, 8, 9, 10, 30, 0, 0, time.UTC),
         }
         mockStore.EXPECT().SaveOrder(ctx, order).Return(errors.New("db error"))
 
@@ -85,9 +98,9 @@ func TestService_ProcessOrder(t *testing.T) {
 
         order := entity.Order{
             Delivery:    entity.Delivery{Name: "John", Phone: "1234567890"},
-            Payment:     entity.Payment{Amount: 1000},
-            Items:       []entity.Item{{ChrtID: 1, Price: 500}},
-            DateCreated: "2025-08-09T10:30:00Z",
+            Payment:     entity.Payment{Amount: entity.NewMoney(1000)},
+            Items:       []entity.Item{{ChrtID: 1, Price: entity.NewMoney(500)}},
+            DateCreated: time.Date(2025, 8, 9, 10, 30, 0, 0, time.UTC),
         }
         mockStore.EXPECT().SaveOrder(ctx, order).Return(nil)
 
@@ -105,9 +118,9 @@ func TestService_ProcessOrder(t *testing.T) {
         order := entity.Order{
             OrderUID:    "test-uid",
             Delivery:    entity.Delivery{Name: "John", Phone: "1234567890"},
-            Payment:     entity.Payment{Amount: -1000},
-            Items:       []entity.Item{{ChrtID: 1, Price: 500}},
-            DateCreated: "2025-08-09T10:30:00Z",
+            Payment:     entity.Payment{Amount: entity.NewMoney(-1000)},
+            Items:       []entity.Item{{ChrtID: 1, Price: entity.NewMoney(500)}},
+            DateCreated: time.Date(2025, 8, 9, 10, 30, 0, 0, time.UTC),
         }
         mockStore.EXPECT().SaveOrder(ctx, order).Return(nil)
 
@@ -130,9 +143,9 @@ func TestService_GetOrder(t *testing.T) {
         order := entity.Order{
             OrderUID:    orderUID,
             Delivery:    entity.Delivery{Name: "John", Phone: "1234567890"},
-            Payment:     entity.Payment{Amount: 1000},
-            Items:       []entity.Item{{ChrtID: 1, Price: 500}},
-            DateCreated: "2025-08-09T10:30:00Z",
+            Payment:     entity.Payment{Amount: entity.NewMoney(1000)},
+            Items:       []entity.Item{{ChrtID: 1, Price: entity.NewMoney(500)}},
+            DateCreated: time.Date(2025, 8, 9, 10, 30, 0, 0, time.UTC),
         }
         svc.cache.Add(orderUID, order)
 
@@ -149,9 +162,9 @@ func TestService_GetOrder(t *testing.T) {
         order := entity.Order{
             OrderUID:    orderUID,
             Delivery:    entity.Delivery{Name: "John", Phone: "1234567890"},
-            Payment:     entity.Payment{Amount: 1000},
-            Items:       []entity.Item{{ChrtID: 1, Price: 500}},
-            DateCreated: "2025-08-09T10:30:00Z",
+            Payment:     entity.Payment{Amount: entity.NewMoney(1000)},
+            Items:       []entity.Item{{ChrtID: 1, Price: entity.NewMoney(500)}},
+            DateCreated: time.Date(2025, 8, 9, 10, 30, 0, 0, time.UTC),
         }
         mockStore.EXPECT().GetOrder(ctx, orderUID).Return(order, nil)
 
@@ -203,19 +216,19 @@ func TestService_LoadCacheFromDB(t *testing.T) {
             {
                 OrderUID:    "uid1",
                 Delivery:    entity.Delivery{Name: "John", Phone: "1234567890"},
-                Payment:     entity.Payment{Amount: 1000},
-                Items:       []entity.Item{{ChrtID: 1, Price: 500}},
-                DateCreated: "2025-08-09T10:30:00Z",
+                Payment:     entity.Payment{Amount: entity.NewMoney(1000)},
+                Items:       []entity.Item{{ChrtID: 1, Price: entity.NewMoney(500)}},
+                DateCreated: time.Date(2025, 8, 9, 10, 30, 0, 0, time.UTC),
             },
             {
                 OrderUID:    "uid2",
                 Delivery:    entity.Delivery{Name: "Jane", Phone: "0987654321"},
-                Payment:     entity.Payment{Amount: 2000},
-                Items:       []entity.Item{{ChrtID: 2, Price: 1000}},
-                DateCreated: "2025-08-09T10:30:00Z",
+                Payment:     entity.Payment{Amount: entity.NewMoney(2000)},
+                Items:       []entity.Item{{ChrtID: 2, Price: entity.NewMoney(1000)}},
+                DateCreated: time.Date(2025, 8, 9, 10, 30, 0, 0, time.UTC),
             },
         }
-        mockStore.EXPECT().GetAllOrders(ctx).Return(orders, nil)
+        mockStore.EXPECT().Orders(ctx, storage.Filter{}).Return(ordersOf(orders, nil))
 
         err := svc.LoadCacheFromDB(ctx)
         assert.NoError(t, err)
@@ -231,7 +244,7 @@ func TestService_LoadCacheFromDB(t *testing.T) {
         svc, mockStore, ctrl := setupService(t)
         defer ctrl.Finish()
 
-        mockStore.EXPECT().GetAllOrders(ctx).Return(nil, errors.New("db error"))
+        mockStore.EXPECT().Orders(ctx, storage.Filter{}).Return(ordersOf(nil, errors.New("db error")))
 
         err := svc.LoadCacheFromDB(ctx)
         assert.Error(t, err)
@@ -243,10 +256,491 @@ func TestService_LoadCacheFromDB(t *testing.T) {
         svc, mockStore, ctrl := setupService(t)
         defer ctrl.Finish()
 
-        mockStore.EXPECT().GetAllOrders(ctx).Return([]entity.Order{}, nil)
+        mockStore.EXPECT().Orders(ctx, storage.Filter{}).Return(ordersOf([]entity.Order{}, nil))
 
         err := svc.LoadCacheFromDB(ctx)
         assert.NoError(t, err)
         assert.Equal(t, 0, svc.cache.Len())
     })
 }
+
+func TestService_ReprocessOrder(t *testing.T) {
+    ctx := context.Background()
+
+    order := entity.Order{
+        OrderUID:    "test-uid",
+        Delivery:    entity.Delivery{Name: "John", Phone: "1234567890"},
+        Payment:     entity.Payment{Amount: entity.NewMoney(1000)},
+        Items:       []entity.Item{{ChrtID: 1, Price: entity.NewMoney(500)}},
+        DateCreated: time.Date(2025, 8, 9, 10, 30, 0, 0, time.FixedZone("MSK", 3*60*60)),
+    }
+
+    t.Run("New order", func(t *testing.T) {
+        svc, mockStore, ctrl := setupService(t)
+        defer ctrl.Finish()
+
+        mockStore.EXPECT().GetOrder(ctx, order.OrderUID).
+            Return(entity.Order{}, fmt.Errorf("order %s %w", order.OrderUID, storage.ErrNotFound))
+        mockStore.EXPECT().SaveOrder(ctx, order).Return(nil)
+
+        outcome, err := svc.ReprocessOrder(ctx, order)
+        assert.NoError(t, err)
+        assert.Equal(t, OutcomeNew, outcome)
+        _, ok := svc.cache.Get(order.OrderUID)
+        assert.True(t, ok)
+    })
+
+    t.Run("Unchanged order", func(t *testing.T) {
+        svc, mockStore, ctrl := setupService(t)
+        defer ctrl.Finish()
+
+        // БД возвращает тот же момент в UTC
+        stored := order
+        stored.DateCreated = time.Date(2025, 8, 9, 7, 30, 0, 0, time.UTC)
+        mockStore.EXPECT().GetOrder(ctx, order.OrderUID).Return(stored, nil)
+
+        outcome, err := svc.ReprocessOrder(ctx, order)
+        assert.NoError(t, err)
+        assert.Equal(t, OutcomeUnchanged, outcome)
+    })
+
+    t.Run("Updated order", func(t *testing.T) {
+        svc, mockStore, ctrl := setupService(t)
+        defer ctrl.Finish()
+
+        stored := order
+        stored.Payment.Amount = entity.NewMoney(500)
+        mockStore.EXPECT().GetOrder(ctx, order.OrderUID).Return(stored, nil)
+        mockStore.EXPECT().UpsertOrder(ctx, order).Return(nil)
+
+        outcome, err := svc.ReprocessOrder(ctx, order)
+        assert.NoError(t, err)
+        assert.Equal(t, OutcomeUpdated, outcome)
+        cachedOrder, ok := svc.cache.Get(order.OrderUID)
+        assert.True(t, ok)
+        assert.Equal(t, order, cachedOrder)
+    })
+
+    t.Run("Incomplete order is repaired", func(t *testing.T) {
+        svc, mockStore, ctrl := setupService(t)
+        defer ctrl.Finish()
+
+        mockStore.EXPECT().GetOrder(ctx, order.OrderUID).
+            Return(entity.Order{}, storage.IncompleteOrder{OrderUID: order.OrderUID, Missing: []string{storage.PartPayment}})
+        mockStore.EXPECT().UpsertOrder(ctx, order).Return(nil)
+
+        outcome, err := svc.ReprocessOrder(ctx, order)
+        assert.NoError(t, err)
+        assert.Equal(t, OutcomeUpdated, outcome)
+        _, ok := svc.cache.Get(order.OrderUID)
+        assert.True(t, ok)
+    })
+
+    t.Run("DB error", func(t *testing.T) {
+        svc, mockStore, ctrl := setupService(t)
+        defer ctrl.Finish()
+
+        mockStore.EXPECT().GetOrder(ctx, order.OrderUID).Return(entity.Order{}, errors.New("db error"))
+
+        _, err := svc.ReprocessOrder(ctx, order)
+        assert.Error(t, err)
+        assert.Equal(t, 0, svc.cache.Len())
+    })
+}
+
+func TestService_ProcessBatch(t *testing.T) {
+    ctx := context.Background()
+    orders := []entity.Order{{OrderUID: "uid-1"}, {OrderUID: "uid-2"}, {OrderUID: "uid-3"}}
+
+    t.Run("Whole batch saved", func(t *testing.T) {
+        svc, mockStore, ctrl := setupService(t)
+        defer ctrl.Finish()
+
+        mockStore.EXPECT().SaveOrders(ctx, orders).Return(nil)
+
+        results, err := svc.ProcessBatch(ctx, orders)
+        assert.NoError(t, err)
+        assert.Equal(t, []error{nil, nil, nil}, results)
+        assert.Equal(t, 3, svc.cache.Len())
+    })
+
+    t.Run("Bad order isolated", func(t *testing.T) {
+        svc, mockStore, ctrl := setupService(t)
+        defer ctrl.Finish()
+
+        badOrder := errors.New("value too long for type character varying(255)")
+        mockStore.EXPECT().SaveOrders(ctx, orders).Return(badOrder)
+        mockStore.EXPECT().SaveOrder(ctx, orders[0]).Return(nil)
+        mockStore.EXPECT().SaveOrder(ctx, orders[1]).Return(badOrder)
+        mockStore.EXPECT().SaveOrder(ctx, orders[2]).Return(nil)
+
+        results, err := svc.ProcessBatch(ctx, orders)
+        assert.NoError(t, err)
+        assert.Equal(t, []error{nil, badOrder, nil}, results)
+        _, ok := svc.cache.Get("uid-2")
+        assert.False(t, ok)
+        assert.Equal(t, 2, svc.cache.Len())
+    })
+
+    t.Run("Storage unavailable", func(t *testing.T) {
+        svc, mockStore, ctrl := setupService(t)
+        defer ctrl.Finish()
+
+        dbDown := errors.New("connection refused")
+        mockStore.EXPECT().SaveOrders(ctx, orders).Return(dbDown)
+        mockStore.EXPECT().SaveOrder(ctx, gomock.Any()).Return(dbDown).Times(3)
+
+        _, err := svc.ProcessBatch(ctx, orders)
+        assert.ErrorIs(t, err, dbDown)
+        assert.Equal(t, 0, svc.cache.Len())
+    })
+
+    t.Run("Invalid money rejected", func(t *testing.T) {
+        svc, mockStore, ctrl := setupService(t)
+        defer ctrl.Finish()
+
+        batch := []entity.Order{
+            {OrderUID: "uid-1", Payment: entity.Payment{Currency: "RUR", Amount: entity.NewMoney(10)}},
+            {OrderUID: "uid-2", Payment: entity.Payment{Currency: "JPY", Amount: entity.MoneyFromScaled(5000)}},
+            {OrderUID: "uid-3", Payment: entity.Payment{Currency: "USD", Amount: entity.MoneyFromScaled(5000)}},
+        }
+        mockStore.EXPECT().SaveOrders(ctx, batch[2:]).Return(nil)
+
+        results, err := svc.ProcessBatch(ctx, batch)
+        assert.NoError(t, err)
+        assert.ErrorIs(t, results[0], entity.ErrUnknownCurrency)
+        assert.ErrorIs(t, results[0], ErrInvalidOrder)
+        assert.ErrorIs(t, results[1], entity.ErrMoneyPrecision)
+        assert.NoError(t, results[2])
+    })
+}
+
+func TestService_ImportOrders(t *testing.T) {
+    ctx := context.Background()
+    orders := []entity.Order{{OrderUID: "uid-1"}, {OrderUID: "uid-2"}, {OrderUID: "uid-1", TrackNumber: "NEW"}}
+    uids := []string{"uid-1", "uid-2", "uid-1"}
+
+    t.Run("Conflict policies", func(t *testing.T) {
+        tests := []struct {
+            policy   Conflict
+            outcomes []Outcome
+            written  []entity.Order
+        }{
+            {ConflictSkip, []Outcome{OutcomeNew, OutcomeSkipped, OutcomeSkipped}, orders[:1]},
+            {ConflictOverwrite, []Outcome{OutcomeNew, OutcomeUpdated, OutcomeUpdated}, orders},
+            {ConflictFail, []Outcome{OutcomeNew, OutcomeRejected, OutcomeRejected}, orders[:1]},
+        }
+        for _, tt := range tests {
+            t.Run(string(tt.policy), func(t *testing.T) {
+                svc, mockStore, ctrl := setupService(t)
+                defer ctrl.Finish()
+
+                // uid-2 уже сохранён, второй uid-1 повторяет заказ из той же пачки
+                mockStore.EXPECT().ExistingOrders(ctx, uids).Return([]string{"uid-2"}, nil)
+                mockStore.EXPECT().UpsertOrders(ctx, tt.written).Return(nil)
+
+                results, err := svc.ImportOrders(ctx, orders, tt.policy, false)
+                assert.NoError(t, err)
+                for i, result := range results {
+                    assert.Equal(t, tt.outcomes[i], result.Outcome)
+                    if result.Outcome == OutcomeRejected {
+                        assert.ErrorIs(t, result.Err, ErrConflict)
+                    }
+                }
+                cached, ok := svc.cache.Get("uid-1")
+                assert.True(t, ok)
+                assert.Equal(t, tt.written[len(tt.written)-1].TrackNumber, cached.TrackNumber)
+            })
+        }
+    })
+
+    t.Run("Dry run writes nothing", func(t *testing.T) {
+        svc, mockStore, ctrl := setupService(t)
+        defer ctrl.Finish()
+
+        mockStore.EXPECT().ExistingOrders(ctx, uids).Return(nil, nil)
+
+        results, err := svc.ImportOrders(ctx, orders, ConflictOverwrite, true)
+        assert.NoError(t, err)
+        assert.Equal(t, []ImportResult{{Outcome: OutcomeNew}, {Outcome: OutcomeNew}, {Outcome: OutcomeUpdated}}, results)
+        assert.Equal(t, 0, svc.cache.Len())
+    })
+
+    t.Run("Bad order isolated", func(t *testing.T) {
+        svc, mockStore, ctrl := setupService(t)
+        defer ctrl.Finish()
+
+        badOrder := errors.New("value too long for type character varying(255)")
+        mockStore.EXPECT().ExistingOrders(ctx, uids[:2]).Return(nil, nil)
+        mockStore.EXPECT().UpsertOrders(ctx, orders[:2]).Return(badOrder)
+        mockStore.EXPECT().UpsertOrder(ctx, orders[0]).Return(nil)
+        mockStore.EXPECT().UpsertOrder(ctx, orders[1]).Return(badOrder)
+
+        results, err := svc.ImportOrders(ctx, orders[:2], ConflictFail, false)
+        assert.NoError(t, err)
+        assert.Equal(t, []ImportResult{{Outcome: OutcomeNew}, {Outcome: OutcomeRejected, Err: badOrder}}, results)
+        assert.Equal(t, 1, svc.cache.Len())
+    })
+
+    t.Run("Storage unavailable", func(t *testing.T) {
+        svc, mockStore, ctrl := setupService(t)
+        defer ctrl.Finish()
+
+        dbDown := errors.New("connection refused")
+        mockStore.EXPECT().ExistingOrders(ctx, uids).Return(nil, dbDown)
+
+        _, err := svc.ImportOrders(ctx, orders, ConflictSkip, false)
+        assert.ErrorIs(t, err, dbDown)
+    })
+
+    t.Run("Unknown policy", func(t *testing.T) {
+        svc, _, ctrl := setupService(t)
+        defer ctrl.Finish()
+
+        _, err := svc.ImportOrders(ctx, orders, "merge", false)
+        assert.Error(t, err)
+    })
+}
+
+func TestService_SetOwnership(t *testing.T) {
+    ctx := context.Background()
+    owns := func(uid string) bool { return uid != "foreign" }
+
+    t.Run("Evict and warm", func(t *testing.T) {
+        svc, mockStore, ctrl := setupService(t)
+        defer ctrl.Finish()
+
+        svc.cache.Add("foreign", entity.Order{OrderUID: "foreign"})
+        svc.cache.Add("own-1", entity.Order{OrderUID: "own-1"})
+        mockStore.EXPECT().Orders(ctx, storage.Filter{}).Return(ordersOf([]entity.Order{{OrderUID: "own-1"}, {OrderUID: "own-2"}, {OrderUID: "foreign"}}, nil))
+
+        assert.NoError(t, svc.SetOwnership(ctx, owns, true))
+        assert.ElementsMatch(t, []string{"own-1", "own-2"}, svc.cache.Keys())
+    })
+
+    t.Run("Evict only", func(t *testing.T) {
+        svc, _, ctrl := setupService(t)
+        defer ctrl.Finish()
+
+        svc.cache.Add("foreign", entity.Order{OrderUID: "foreign"})
+        assert.NoError(t, svc.SetOwnership(ctx, owns, false))
+        assert.Equal(t, 0, svc.cache.Len())
+    })
+
+    t.Run("Foreign orders are not cached", func(t *testing.T) {
+        svc, mockStore, ctrl := setupService(t)
+        defer ctrl.Finish()
+
+        assert.NoError(t, svc.SetOwnership(ctx, owns, false))
+        mockStore.EXPECT().GetOrder(ctx, "foreign").Return(entity.Order{OrderUID: "foreign"}, nil).Times(2)
+        for i := 0; i < 2; i++ {
+            _, err := svc.GetOrder(ctx, "foreign")
+            assert.NoError(t, err)
+        }
+        assert.Equal(t, 0, svc.cache.Len())
+    })
+}
+
+func TestService_FlushCache(t *testing.T) {
+    svc, mockStore, ctrl := setupService(t)
+    defer ctrl.Finish()
+
+    svc.cache.Add("uid-1", entity.Order{OrderUID: "uid-1"})
+    svc.cache.Add("uid-2", entity.Order{OrderUID: "uid-2"})
+
+    assert.Equal(t, 2, svc.FlushCache())
+    assert.Equal(t, 0, svc.CacheSize())
+
+    // После сброса заказ читается из БД
+    mockStore.EXPECT().GetOrder(context.Background(), "uid-1").Return(entity.Order{OrderUID: "uid-1"}, nil)
+    _, err := svc.GetOrder(context.Background(), "uid-1")
+    assert.NoError(t, err)
+    assert.Equal(t, 1, svc.CacheSize())
+}
+
+func TestService_Evict(t *testing.T) {
+    svc, _, ctrl := setupService(t)
+    defer ctrl.Finish()
+
+    svc.cache.Add("uid-1", entity.Order{OrderUID: "uid-1"})
+    svc.cache.Add("uid-2", entity.Order{OrderUID: "uid-2"})
+
+    svc.Evict("uid-1")
+    svc.Evict("unknown")
+    assert.Equal(t, []string{"uid-2"}, svc.cache.Keys())
+}
+
+func TestService_Refresh(t *testing.T) {
+    ctx := context.Background()
+
+    t.Run("Cached order is reloaded", func(t *testing.T) {
+        svc, mockStore, ctrl := setupService(t)
+        defer ctrl.Finish()
+
+        svc.cache.Add("uid-1", entity.Order{OrderUID: "uid-1", TrackNumber: "old"})
+        mockStore.EXPECT().GetOrder(ctx, "uid-1").Return(entity.Order{OrderUID: "uid-1", TrackNumber: "new"}, nil)
+
+        assert.NoError(t, svc.Refresh(ctx, "uid-1"))
+        order, _ := svc.cache.Get("uid-1")
+        assert.Equal(t, "new", order.TrackNumber)
+    })
+
+    t.Run("Not cached order is ignored", func(t *testing.T) {
+        svc, _, ctrl := setupService(t)
+        defer ctrl.Finish()
+
+        assert.NoError(t, svc.Refresh(ctx, "uid-1"))
+        assert.Equal(t, 0, svc.cache.Len())
+    })
+
+    t.Run("Deleted order is evicted", func(t *testing.T) {
+        svc, mockStore, ctrl := setupService(t)
+        defer ctrl.Finish()
+
+        svc.cache.Add("uid-1", entity.Order{OrderUID: "uid-1"})
+        mockStore.EXPECT().GetOrder(ctx, "uid-1").Return(entity.Order{}, storage.ErrNotFound)
+
+        assert.NoError(t, svc.Refresh(ctx, "uid-1"))
+        assert.Equal(t, 0, svc.cache.Len())
+    })
+
+    t.Run("Storage error evicts stale copy", func(t *testing.T) {
+        svc, mockStore, ctrl := setupService(t)
+        defer ctrl.Finish()
+
+        svc.cache.Add("uid-1", entity.Order{OrderUID: "uid-1"})
+        mockStore.EXPECT().GetOrder(ctx, "uid-1").Return(entity.Order{}, errors.New("connection refused"))
+
+        assert.Error(t, svc.Refresh(ctx, "uid-1"))
+        assert.Equal(t, 0, svc.cache.Len())
+    })
+}
+
+func TestService_Resync(t *testing.T) {
+    ctx := context.Background()
+    svc, mockStore, ctrl := setupService(t)
+    defer ctrl.Finish()
+
+    svc.cache.Add("deleted", entity.Order{OrderUID: "deleted"})
+    svc.cache.Add("uid-1", entity.Order{OrderUID: "uid-1", TrackNumber: "old"})
+    mockStore.EXPECT().Orders(ctx, storage.Filter{}).Return(ordersOf([]entity.Order{{OrderUID: "uid-1", TrackNumber: "new"}, {OrderUID: "uid-2"}}, nil))
+
+    assert.NoError(t, svc.Resync(ctx))
+    assert.ElementsMatch(t, []string{"uid-1", "uid-2"}, svc.cache.Keys())
+    order, _ := svc.cache.Get("uid-1")
+    assert.Equal(t, "new", order.TrackNumber)
+
+    // При ошибке кэш не трогается
+    mockStore.EXPECT().Orders(ctx, storage.Filter{}).Return(ordersOf(nil, errors.New("connection refused")))
+    assert.Error(t, svc.Resync(ctx))
+    assert.Equal(t, 2, svc.cache.Len())
+}
+
+func TestService_FindOrders(t *testing.T) {
+    ctx := context.Background()
+    lookup := storage.Lookup{Field: storage.ByCustomer, Value: "customer-1"}
+    orders := []entity.Order{
+        {OrderUID: "uid-2", CustomerID: "customer-1", DateCreated: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+        {OrderUID: "uid-1", CustomerID: "customer-1", DateCreated: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+    }
+
+    t.Run("Complete key is served from cache", func(t *testing.T) {
+        svc, mockStore, ctrl := setupService(t)
+        defer ctrl.Finish()
+
+        mockStore.EXPECT().FindOrders(ctx, lookup).Return(orders, nil).Times(1)
+
+        result, err := svc.FindOrders(ctx, lookup)
+        assert.NoError(t, err)
+        assert.Equal(t, orders, result)
+        assert.Equal(t, 2, svc.cache.Len())
+
+        // Повторный поиск не ходит в БД, а новый заказ клиента сразу попадает в индекс
+        svc.addToCache(entity.Order{OrderUID: "uid-3", CustomerID: "customer-1", DateCreated: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)})
+        result, err = svc.FindOrders(ctx, storage.Lookup{Field: storage.ByCustomer, Value: "customer-1", Limit: 2})
+        assert.NoError(t, err)
+        assert.Equal(t, []string{"uid-3", "uid-2"}, []string{result[0].OrderUID, result[1].OrderUID})
+    })
+
+    t.Run("Eviction and TTL make key incomplete", func(t *testing.T) {
+        svc, mockStore, ctrl := setupService(t)
+        defer ctrl.Finish()
+
+        mockStore.EXPECT().FindOrders(ctx, lookup).Return(orders, nil).Times(3)
+
+        _, err := svc.FindOrders(ctx, lookup)
+        assert.NoError(t, err)
+        svc.Evict("uid-1")
+        _, err = svc.FindOrders(ctx, lookup)
+        assert.NoError(t, err)
+
+        now := time.Now()
+        svc.now = func() time.Time { return now.Add(lookupTTL + time.Second) }
+        result, err := svc.FindOrders(ctx, lookup)
+        assert.NoError(t, err)
+        assert.Len(t, result, 2)
+    })
+
+    t.Run("Updated order leaves old key", func(t *testing.T) {
+        svc, mockStore, ctrl := setupService(t)
+        defer ctrl.Finish()
+
+        mockStore.EXPECT().FindOrders(ctx, lookup).Return(orders, nil)
+
+        _, err := svc.FindOrders(ctx, lookup)
+        assert.NoError(t, err)
+        svc.addToCache(entity.Order{OrderUID: "uid-1", CustomerID: "customer-2"})
+
+        result, err := svc.FindOrders(ctx, lookup)
+        assert.NoError(t, err)
+        assert.Equal(t, []entity.Order{orders[0]}, result)
+    })
+
+    t.Run("Not owned orders are not cached", func(t *testing.T) {
+        svc, mockStore, ctrl := setupService(t)
+        defer ctrl.Finish()
+
+        svc.owns = func(orderUID string) bool { return orderUID == "uid-1" }
+        mockStore.EXPECT().FindOrders(ctx, lookup).Return(orders, nil).Times(2)
+
+        for range 2 {
+            result, err := svc.FindOrders(ctx, lookup)
+            assert.NoError(t, err)
+            assert.Equal(t, orders, result)
+        }
+        assert.Equal(t, []string{"uid-1"}, svc.cache.Keys())
+    })
+
+    t.Run("Invalid lookup", func(t *testing.T) {
+        svc, _, ctrl := setupService(t)
+        defer ctrl.Finish()
+
+        _, err := svc.FindOrders(ctx, storage.Lookup{Field: storage.ByChrtID, Value: "abc"})
+        assert.ErrorIs(t, err, storage.ErrInvalidLookup)
+    })
+
+    t.Run("Storage error", func(t *testing.T) {
+        svc, mockStore, ctrl := setupService(t)
+        defer ctrl.Finish()
+
+        mockStore.EXPECT().FindOrders(ctx, lookup).Return(nil, errors.New("connection refused"))
+
+        _, err := svc.FindOrders(ctx, lookup)
+        assert.Error(t, err)
+    })
+}
+
+func TestService_ResyncKeepsLastOrders(t *testing.T) {
+    ctx := context.Background()
+    ctrl := gomock.NewController(t)
+    defer ctrl.Finish()
+    mockStore := mock.NewMockStore(ctrl)
+    svc := newService(mockStore, 2)
+
+    // Как и при добавлении в LRU по очереди, в кэше остаются последние заказы обхода
+    orders := []entity.Order{{OrderUID: "uid-1"}, {OrderUID: "uid-2"}, {OrderUID: "uid-3"}, {OrderUID: "uid-4"}, {OrderUID: "uid-5"}}
+    mockStore.EXPECT().Orders(ctx, storage.Filter{}).Return(ordersOf(orders, nil))
+
+    assert.NoError(t, svc.Resync(ctx))
+    assert.Equal(t, []string{"uid-4", "uid-5"}, svc.cache.Keys())
+}
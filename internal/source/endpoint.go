@@ -0,0 +1,74 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Partition — партиция топика (или её аналог у другого брокера)
+type Partition struct {
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+}
+
+func (p Partition) String() string {
+	return fmt.Sprintf("%s[%d]", p.Topic, p.Partition)
+}
+
+// Message — сообщение источника, не зависящее от конкретного брокера
+type Message struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+	Key       []byte
+	Value     []byte
+	Headers   map[string]string
+	Timestamp time.Time
+}
+
+func (m *Message) TopicPartition() Partition {
+	return Partition{Topic: m.Topic, Partition: m.Partition}
+}
+
+func (m *Message) String() string {
+	return fmt.Sprintf("%s@%d", m.TopicPartition(), m.Offset)
+}
+
+// Offsets — смещения партиции для оценки отставания группы
+type Offsets struct {
+	Partition
+	Committed int64 `json:"committed"` // следующее смещение группы; -1, если коммитов не было
+	Low       int64 `json:"low"`       // первое доступное смещение
+	High      int64 `json:"high"`      // смещение, следующее за последним сообщением
+}
+
+// Lag — сколько сообщений партиции группе ещё предстоит обработать
+func (o Offsets) Lag() int64 {
+	from := max(o.Committed, o.Low)
+	return max(o.High-from, 0)
+}
+
+// RebalanceHandler получает уведомления об изменении назначенных партиций
+type RebalanceHandler interface {
+	Assigned(partitions []Partition)
+	Revoked(partitions []Partition)
+}
+
+// MessageSource — источник сообщений с партициями и смещениями
+type MessageSource interface {
+	// Subscribe подписывает источник на топики; handler может быть nil
+	Subscribe(topics []string, handler RebalanceHandler) error
+	// Fetch возвращает следующее сообщение или nil, если за timeout сообщений не было
+	Fetch(ctx context.Context, timeout time.Duration) (*Message, error)
+	// Commit подтверждает обработку сообщения (и всех предыдущих в его партиции)
+	Commit(ctx context.Context, msg *Message) error
+	Pause(partitions []Partition) error
+	Resume(partitions []Partition) error
+	Assignment() ([]Partition, error)
+	// Partitions возвращает число партиций топика
+	Partitions(topic string) (int, error)
+	// Offsets возвращает закоммиченные смещения группы и границы партиций
+	Offsets(ctx context.Context, partitions []Partition) ([]Offsets, error)
+	Close() error
+}
@@ -0,0 +1,300 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrClosed возвращается при обращении к закрытому источнику
+var ErrClosed = errors.New("source is closed")
+
+// MemoryBroker — брокер в памяти с топиками, партициями и смещениями групп.
+// Нужен для тестов: консюмер работает с ним через MessageSource так же, как с Kafka
+type MemoryBroker struct {
+	mu        sync.Mutex
+	topics    map[string][][]Message
+	committed map[string]map[Partition]int64 // группа -> партиция -> следующее смещение
+	produced  chan struct{}                  // закрывается и пересоздаётся при каждой записи
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		topics:    make(map[string][][]Message),
+		committed: make(map[string]map[Partition]int64),
+		produced:  make(chan struct{}),
+	}
+}
+
+// CreateTopic создаёт топик с заданным числом партиций
+func (b *MemoryBroker) CreateTopic(topic string, partitions int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.topics[topic]; !ok {
+		b.topics[topic] = make([][]Message, partitions)
+	}
+}
+
+// Produce записывает сообщение в партицию, выбранную по ключу (см. PartitionFor)
+func (b *MemoryBroker) Produce(topic string, key, value []byte, headers map[string]string) (*Message, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	partitions, ok := b.topics[topic]
+	if !ok {
+		return nil, fmt.Errorf("unknown topic %s", topic)
+	}
+	p := PartitionFor(key, len(partitions))
+
+	msg := Message{
+		Topic:     topic,
+		Partition: p,
+		Offset:    int64(len(partitions[p])),
+		Key:       key,
+		Value:     value,
+		Headers:   headers,
+		Timestamp: time.Now(),
+	}
+	partitions[p] = append(partitions[p], msg)
+
+	close(b.produced)
+	b.produced = make(chan struct{})
+	return &msg, nil
+}
+
+// Committed возвращает следующее смещение группы в партиции (0, если коммитов не было)
+func (b *MemoryBroker) Committed(group string, p Partition) int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.committed[group][p]
+}
+
+// NewSource создаёт консюмера группы. Все партиции подписанных топиков
+// назначаются ему целиком, чтение начинается с закоммиченного смещения
+func (b *MemoryBroker) NewSource(group string) *MemorySource {
+	return &MemorySource{
+		broker:   b,
+		group:    group,
+		position: make(map[Partition]int64),
+		paused:   make(map[Partition]bool),
+	}
+}
+
+// MemorySource — MessageSource поверх MemoryBroker
+type MemorySource struct {
+	broker *MemoryBroker
+	group  string
+
+	// Поля защищены broker.mu
+	handler  RebalanceHandler
+	assigned []Partition
+	position map[Partition]int64
+	paused   map[Partition]bool
+	next     int
+	closed   bool
+}
+
+func (s *MemorySource) Subscribe(topics []string, handler RebalanceHandler) error {
+	s.broker.mu.Lock()
+	var partitions []Partition
+	for topic, parts := range s.broker.topics {
+		match, err := matchTopic(topics, topic)
+		if err != nil {
+			s.broker.mu.Unlock()
+			return err
+		}
+		if match {
+			for p := range parts {
+				partitions = append(partitions, Partition{Topic: topic, Partition: int32(p)})
+			}
+		}
+	}
+	s.handler = handler
+	s.broker.mu.Unlock()
+
+	s.Reassign(partitions)
+	return nil
+}
+
+// Reassign имитирует ребалансировку: отзывает текущие партиции и назначает новые
+func (s *MemorySource) Reassign(partitions []Partition) {
+	sort.Slice(partitions, func(i, j int) bool {
+		if partitions[i].Topic != partitions[j].Topic {
+			return partitions[i].Topic < partitions[j].Topic
+		}
+		return partitions[i].Partition < partitions[j].Partition
+	})
+
+	s.broker.mu.Lock()
+	revoked := s.assigned
+	handler := s.handler
+	s.assigned = partitions
+	s.position = make(map[Partition]int64, len(partitions))
+	for _, p := range partitions {
+		s.position[p] = s.broker.committed[s.group][p]
+	}
+	s.paused = make(map[Partition]bool)
+	s.broker.mu.Unlock()
+
+	if handler != nil {
+		if len(revoked) > 0 {
+			handler.Revoked(revoked)
+		}
+		handler.Assigned(partitions)
+	}
+}
+
+func (s *MemorySource) Fetch(ctx context.Context, timeout time.Duration) (*Message, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		s.broker.mu.Lock()
+		if s.closed {
+			s.broker.mu.Unlock()
+			return nil, ErrClosed
+		}
+		msg := s.nextMessage()
+		produced := s.broker.produced
+		s.broker.mu.Unlock()
+		if msg != nil {
+			return msg, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+			return nil, nil
+		case <-produced:
+		}
+	}
+}
+
+// nextMessage по кругу обходит незапаузенные партиции; вызывается под broker.mu
+func (s *MemorySource) nextMessage() *Message {
+	for i := 0; i < len(s.assigned); i++ {
+		p := s.assigned[(s.next+i)%len(s.assigned)]
+		if s.paused[p] {
+			continue
+		}
+		records := s.broker.topics[p.Topic][p.Partition]
+		pos := s.position[p]
+		if pos >= int64(len(records)) {
+			continue
+		}
+		s.position[p] = pos + 1
+		s.next = (s.next + i + 1) % len(s.assigned)
+		msg := records[pos]
+		return &msg
+	}
+	return nil
+}
+
+func (s *MemorySource) Commit(_ context.Context, msg *Message) error {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	offsets, ok := s.broker.committed[s.group]
+	if !ok {
+		offsets = make(map[Partition]int64)
+		s.broker.committed[s.group] = offsets
+	}
+	offsets[msg.TopicPartition()] = msg.Offset + 1
+	return nil
+}
+
+func (s *MemorySource) Pause(partitions []Partition) error {
+	return s.setPaused(partitions, true)
+}
+
+func (s *MemorySource) Resume(partitions []Partition) error {
+	return s.setPaused(partitions, false)
+}
+
+func (s *MemorySource) setPaused(partitions []Partition, paused bool) error {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	for _, p := range partitions {
+		if _, ok := s.position[p]; !ok {
+			return fmt.Errorf("partition %s is not assigned", p)
+		}
+		s.paused[p] = paused
+	}
+	return nil
+}
+
+func (s *MemorySource) Assignment() ([]Partition, error) {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	return append([]Partition(nil), s.assigned...), nil
+}
+
+func (s *MemorySource) Partitions(topic string) (int, error) {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	partitions, ok := s.broker.topics[topic]
+	if !ok {
+		return 0, fmt.Errorf("unknown topic %s", topic)
+	}
+	return len(partitions), nil
+}
+
+func (s *MemorySource) Offsets(_ context.Context, partitions []Partition) ([]Offsets, error) {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	offsets := make([]Offsets, 0, len(partitions))
+	for _, p := range partitions {
+		log, ok := s.broker.topics[p.Topic]
+		if !ok || int(p.Partition) >= len(log) {
+			return nil, fmt.Errorf("unknown partition %s", p)
+		}
+		committed, ok := s.broker.committed[s.group][p]
+		if !ok {
+			committed = -1
+		}
+		offsets = append(offsets, Offsets{Partition: p, Committed: committed, High: int64(len(log[p.Partition]))})
+	}
+	return offsets, nil
+}
+
+func (s *MemorySource) Close() error {
+	s.broker.mu.Lock()
+	s.closed = true
+	revoked := s.assigned
+	s.assigned = nil
+	handler := s.handler
+	s.broker.mu.Unlock()
+
+	if handler != nil && len(revoked) > 0 {
+		handler.Revoked(revoked)
+	}
+	return nil
+}
+
+// matchTopic сравнивает топик с подпиской; элементы с "^" — регулярные выражения, как в librdkafka
+func matchTopic(subscription []string, topic string) (bool, error) {
+	for _, t := range subscription {
+		if !strings.HasPrefix(t, "^") {
+			if t == topic {
+				return true, nil
+			}
+			continue
+		}
+		re, err := regexp.Compile(t)
+		if err != nil {
+			return false, fmt.Errorf("invalid topic pattern %q: %w", t, err)
+		}
+		if re.MatchString(topic) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,121 @@
+package source
+
+import (
+    "context"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+type recordingHandler struct {
+    assigned [][]Partition
+    revoked  [][]Partition
+}
+
+func (h *recordingHandler) Assigned(p []Partition) { h.assigned = append(h.assigned, p) }
+func (h *recordingHandler) Revoked(p []Partition)  { h.revoked = append(h.revoked, p) }
+
+func TestMemorySource(t *testing.T) {
+    ctx := context.Background()
+    broker := NewMemoryBroker()
+    broker.CreateTopic("orders", 2)
+    broker.CreateTopic("other", 1)
+
+    p0 := Partition{Topic: "orders", Partition: 0}
+    p1 := Partition{Topic: "orders", Partition: 1}
+
+    t.Run("Subscribe and rebalance", func(t *testing.T) {
+        handler := &recordingHandler{}
+        src := broker.NewSource("group")
+        require.NoError(t, src.Subscribe([]string{"^ord.*"}, handler))
+
+        assignment, err := src.Assignment()
+        require.NoError(t, err)
+        assert.Equal(t, []Partition{p0, p1}, assignment)
+
+        src.Reassign([]Partition{p1})
+        require.NoError(t, src.Close())
+        assert.Equal(t, [][]Partition{{p0, p1}, {p1}}, handler.assigned)
+        assert.Equal(t, [][]Partition{{p0, p1}, {p1}}, handler.revoked)
+    })
+
+    t.Run("Fetch, pause and commit", func(t *testing.T) {
+        src := broker.NewSource("group")
+        require.NoError(t, src.Subscribe([]string{"orders"}, nil))
+        defer src.Close()
+
+        msg, err := src.Fetch(ctx, 10*time.Millisecond)
+        require.NoError(t, err)
+        assert.Nil(t, msg) // Таймаут на пустом топике
+
+        require.NoError(t, src.Pause([]Partition{p0, p1}))
+        produced, err := broker.Produce("orders", []byte("k"), []byte("v"), nil)
+        require.NoError(t, err)
+        msg, err = src.Fetch(ctx, 10*time.Millisecond)
+        require.NoError(t, err)
+        assert.Nil(t, msg)
+
+        require.NoError(t, src.Resume([]Partition{p0, p1}))
+        msg, err = src.Fetch(ctx, time.Second)
+        require.NoError(t, err)
+        require.NotNil(t, msg)
+        assert.Equal(t, produced, msg)
+
+        require.NoError(t, src.Commit(ctx, msg))
+        assert.Equal(t, int64(1), broker.Committed("group", msg.TopicPartition()))
+        assert.Error(t, src.Pause([]Partition{{Topic: "other"}}))
+    })
+
+    t.Run("Fetch wakes up on produce", func(t *testing.T) {
+        src := broker.NewSource("late")
+        require.NoError(t, src.Subscribe([]string{"other"}, nil))
+        defer src.Close()
+
+        go func() {
+            time.Sleep(20 * time.Millisecond)
+            broker.Produce("other", nil, []byte("v"), nil)
+        }()
+        msg, err := src.Fetch(ctx, 5*time.Second)
+        require.NoError(t, err)
+        require.NotNil(t, msg)
+        assert.Equal(t, "other", msg.Topic)
+    })
+
+    t.Run("Closed source", func(t *testing.T) {
+        src := broker.NewSource("group")
+        require.NoError(t, src.Close())
+        _, err := src.Fetch(ctx, time.Millisecond)
+        assert.ErrorIs(t, err, ErrClosed)
+    })
+}
+
+func TestMemorySource_Offsets(t *testing.T) {
+    ctx := context.Background()
+    broker := NewMemoryBroker()
+    broker.CreateTopic("orders", 1)
+    p0 := Partition{Topic: "orders", Partition: 0}
+
+    src := broker.NewSource("group")
+    require.NoError(t, src.Subscribe([]string{"orders"}, nil))
+    for range 3 {
+        _, err := broker.Produce("orders", nil, []byte("value"), nil)
+        require.NoError(t, err)
+    }
+
+    offsets, err := src.Offsets(ctx, []Partition{p0})
+    require.NoError(t, err)
+    assert.Equal(t, []Offsets{{Partition: p0, Committed: -1, High: 3}}, offsets)
+    assert.Equal(t, int64(3), offsets[0].Lag())
+
+    msg, err := src.Fetch(ctx, time.Second)
+    require.NoError(t, err)
+    require.NoError(t, src.Commit(ctx, msg))
+    offsets, err = src.Offsets(ctx, []Partition{p0})
+    require.NoError(t, err)
+    assert.Equal(t, int64(2), offsets[0].Lag())
+
+    _, err = src.Offsets(ctx, []Partition{{Topic: "orders", Partition: 5}})
+    assert.Error(t, err)
+}
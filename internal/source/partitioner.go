@@ -0,0 +1,48 @@
+package source
+
+// Murmur2 — хэш ключа, которым партиционирует Java-клиент Kafka по умолчанию
+// и librdkafka с partitioner=murmur2_random
+func Murmur2(data []byte) uint32 {
+	const (
+		seed = 0x9747b28c
+		m    = 0x5bd1e995
+		r    = 24
+	)
+	length := len(data)
+	h := uint32(seed) ^ uint32(length)
+
+	for i := 0; i+4 <= length; i += 4 {
+		k := uint32(data[i]) | uint32(data[i+1])<<8 | uint32(data[i+2])<<16 | uint32(data[i+3])<<24
+		k *= m
+		k ^= k >> r
+		k *= m
+		h *= m
+		h ^= k
+	}
+
+	tail := data[length&^3:]
+	switch len(tail) {
+	case 3:
+		h ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(tail[0])
+		h *= m
+	}
+
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+	return h
+}
+
+// PartitionFor возвращает партицию ключа так же, как партиционер Kafka по умолчанию
+func PartitionFor(key []byte, partitions int) int32 {
+	if partitions <= 0 {
+		return 0
+	}
+	return int32((Murmur2(key) & 0x7fffffff) % uint32(partitions))
+}
@@ -0,0 +1,31 @@
+package source
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func TestMurmur2(t *testing.T) {
+    // Эталонные значения из тестов Java-клиента Kafka (Utils.murmur2)
+    cases := map[string]int32{
+        "21":          -973932308,
+        "foobar":      -790332482,
+        "a-little-bit-long-string":          -985981536,
+        "a-little-bit-longer-string":        -1486304829,
+        "lkjh234lh9fiuh90y23oiuhsafujhadof229phr9h19h89h8": -58897971,
+        "abc": 479470107,
+    }
+    for key, want := range cases {
+        assert.Equal(t, want, int32(Murmur2([]byte(key))), key)
+    }
+}
+
+func TestPartitionFor(t *testing.T) {
+    assert.Equal(t, int32(0), PartitionFor([]byte("any"), 0))
+    for _, key := range []string{"21", "foobar", "abc", "b563feb7b2b84b6test"} {
+        p := PartitionFor([]byte(key), 3)
+        assert.True(t, p >= 0 && p < 3)
+        assert.Equal(t, int32((Murmur2([]byte(key))&0x7fffffff)%3), p)
+    }
+}
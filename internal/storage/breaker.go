@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"order/internal/breaker"
+	"order/internal/entity"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// breakerStore пропускает вызовы хранилища через предохранитель: пока база недоступна,
+// вызовы сразу возвращают breaker.ErrOpen, не дожидаясь таймаутов соединения
+type breakerStore struct {
+	store   Store
+	breaker *breaker.Breaker
+}
+
+func NewBreakerStore(store Store, b *breaker.Breaker) Store {
+	return &breakerStore{store: store, breaker: b}
+}
+
+// IsUnavailable отличает сбой базы от ошибок конкретного заказа:
+// отсутствие или неполнота заказа, некорректный поиск или запрос, ошибки данных (класс 22) и нарушения ограничений (класс 23) предохранитель не размыкают.
+// Отмена и истечение контекста вызывающего (см. CallerError) — тоже не сбой базы; а вот
+// context.DeadlineExceeded при живом контексте вызывающего — собственный таймаут хранилища
+// (соединения, сети, драйвера), и он предохранитель размыкает
+func IsUnavailable(err error) bool {
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrIncomplete) || errors.Is(err, ErrInvalidLookup) || errors.Is(err, ErrInvalidSearch) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var callerErr *CallerError
+	if errors.As(err, &callerErr) {
+		return false
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Class() {
+		case "22", "23":
+			return false
+		}
+	}
+	return true
+}
+
+// CallerError — ошибка вызова, завершившегося после отмены или истечения контекста вызывающего:
+// ушедший клиент или остановка сервиса ничего не говорят о состоянии базы
+type CallerError struct {
+	Err error
+}
+
+func (e *CallerError) Error() string { return e.Err.Error() }
+
+func (e *CallerError) Unwrap() error { return e.Err }
+
+// do выполняет вызов через предохранитель; ошибку после завершения ctx помечает как CallerError
+func (s *breakerStore) do(ctx context.Context, call func() error) error {
+	return s.breaker.Do(func() error {
+		err := call()
+		if err != nil && ctx.Err() != nil {
+			return &CallerError{Err: err}
+		}
+		return err
+	})
+}
+
+func (s *breakerStore) SaveOrder(ctx context.Context, order entity.Order) error {
+	return s.do(ctx, func() error { return s.store.SaveOrder(ctx, order) })
+}
+
+func (s *breakerStore) SaveOrders(ctx context.Context, orders []entity.Order) error {
+	return s.do(ctx, func() error { return s.store.SaveOrders(ctx, orders) })
+}
+
+func (s *breakerStore) UpsertOrder(ctx context.Context, order entity.Order) error {
+	return s.do(ctx, func() error { return s.store.UpsertOrder(ctx, order) })
+}
+
+func (s *breakerStore) UpsertOrders(ctx context.Context, orders []entity.Order) error {
+	return s.do(ctx, func() error { return s.store.UpsertOrders(ctx, orders) })
+}
+
+func (s *breakerStore) ExistingOrders(ctx context.Context, uids []string) ([]string, error) {
+	var existing []string
+	err := s.do(ctx, func() (err error) {
+		existing, err = s.store.ExistingOrders(ctx, uids)
+		return err
+	})
+	return existing, err
+}
+
+func (s *breakerStore) GetOrder(ctx context.Context, orderUID string) (entity.Order, error) {
+	var order entity.Order
+	err := s.do(ctx, func() (err error) {
+		order, err = s.store.GetOrder(ctx, orderUID)
+		return err
+	})
+	return order, err
+}
+
+// Orders пропускает через предохранитель получение каждого заказа: разомкнутый
+// предохранитель прерывает обход с ErrOpen
+func (s *breakerStore) Orders(ctx context.Context, filter Filter) iter.Seq2[entity.Order, error] {
+	return func(yield func(entity.Order, error) bool) {
+		next, stop := iter.Pull2(s.store.Orders(ctx, filter))
+		defer stop()
+		for {
+			var order entity.Order
+			var ok bool
+			err := s.do(ctx, func() (err error) {
+				order, err, ok = next()
+				return err
+			})
+			if err != nil {
+				yield(entity.Order{}, err)
+				return
+			}
+			if !ok || !yield(order, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (s *breakerStore) FindOrders(ctx context.Context, lookup Lookup) ([]entity.Order, error) {
+	var orders []entity.Order
+	err := s.do(ctx, func() (err error) {
+		orders, err = s.store.FindOrders(ctx, lookup)
+		return err
+	})
+	return orders, err
+}
+
+func (s *breakerStore) SearchOrders(ctx context.Context, search Search) (SearchPage, error) {
+	var page SearchPage
+	err := s.do(ctx, func() (err error) {
+		page, err = s.store.SearchOrders(ctx, search)
+		return err
+	})
+	return page, err
+}
+
+func (s *breakerStore) IncompleteOrders(ctx context.Context) ([]IncompleteOrder, error) {
+	var orders []IncompleteOrder
+	err := s.do(ctx, func() (err error) {
+		orders, err = s.store.IncompleteOrders(ctx)
+		return err
+	})
+	return orders, err
+}
+
+func (s *breakerStore) SaveRates(ctx context.Context, rates []entity.ExchangeRate) error {
+	return s.do(ctx, func() error { return s.store.SaveRates(ctx, rates) })
+}
+
+func (s *breakerStore) Rates(ctx context.Context, from, to time.Time) ([]entity.ExchangeRate, error) {
+	var rates []entity.ExchangeRate
+	err := s.do(ctx, func() (err error) {
+		rates, err = s.store.Rates(ctx, from, to)
+		return err
+	})
+	return rates, err
+}
+
+func (s *breakerStore) DailyRevenue(ctx context.Context, from, to time.Time) ([]DailyRevenue, error) {
+	var revenue []DailyRevenue
+	err := s.do(ctx, func() (err error) {
+		revenue, err = s.store.DailyRevenue(ctx, from, to)
+		return err
+	})
+	return revenue, err
+}
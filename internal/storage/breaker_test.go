@@ -0,0 +1,133 @@
+package storage
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "iter"
+    "order/internal/breaker"
+    "order/internal/entity"
+    "testing"
+    "time"
+
+    "github.com/lib/pq"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestIsUnavailable(t *testing.T) {
+    tests := []struct {
+        name string
+        err  error
+        want bool
+    }{
+        {"Not found", fmt.Errorf("get order: %w", ErrNotFound), false},
+        {"Invalid lookup", fmt.Errorf("find orders: %w", ErrInvalidLookup), false},
+        {"Canceled", context.Canceled, false},
+        {"Caller deadline", &CallerError{Err: fmt.Errorf("failed to query order uid-1: %w", context.DeadlineExceeded)}, false},
+        {"Storage timeout", fmt.Errorf("failed to query order uid-1: %w", context.DeadlineExceeded), true},
+        {"Wrapped data exception", fmt.Errorf("failed to query order uid-1: %w", &pq.Error{Code: "22P02"}), false},
+        {"Data exception", &pq.Error{Code: "22001"}, false},
+        {"Constraint violation", &pq.Error{Code: "23505"}, false},
+        {"Connection failure", &pq.Error{Code: "08006"}, true},
+        {"Admin shutdown", &pq.Error{Code: "57P01"}, true},
+        {"Network error", errors.New("dial tcp: connection refused"), true},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            assert.Equal(t, tt.want, IsUnavailable(tt.err))
+        })
+    }
+}
+
+// iterStore отдаёт из Orders заданные заказы, а затем ошибку
+type iterStore struct {
+    Store
+    orders []entity.Order
+    err    error
+}
+
+func (s *iterStore) Orders(context.Context, Filter) iter.Seq2[entity.Order, error] {
+    return func(yield func(entity.Order, error) bool) {
+        for _, order := range s.orders {
+            if !yield(order, nil) {
+                return
+            }
+        }
+        if s.err != nil {
+            yield(entity.Order{}, s.err)
+        }
+    }
+}
+
+func TestBreakerStore_Orders(t *testing.T) {
+    ctx := context.Background()
+    b := breaker.New(breaker.Config{Name: "database", FailureThreshold: 1, OpenTimeout: time.Minute, IsFailure: IsUnavailable})
+    store := NewBreakerStore(&iterStore{
+        orders: []entity.Order{{OrderUID: "uid-1"}, {OrderUID: "uid-2"}},
+        err:    errors.New("connection refused"),
+    }, b)
+
+    var uids []string
+    var last error
+    for order, err := range store.Orders(ctx, Filter{}) {
+        if err != nil {
+            last = err
+            continue
+        }
+        uids = append(uids, order.OrderUID)
+    }
+    assert.Equal(t, []string{"uid-1", "uid-2"}, uids)
+    assert.EqualError(t, last, "connection refused")
+
+    // Обрыв посреди обхода размыкает предохранитель: следующий обход сразу получает ErrOpen
+    _, err := Collect(store.Orders(ctx, Filter{}))
+    assert.ErrorIs(t, err, breaker.ErrOpen)
+}
+
+// slowStore отвечает ошибкой ctx, когда контекст вызова завершён, иначе — err
+type slowStore struct {
+    Store
+    err error
+}
+
+func (s *slowStore) GetOrder(ctx context.Context, _ string) (entity.Order, error) {
+    if s.err != nil {
+        return entity.Order{}, s.err
+    }
+    <-ctx.Done()
+    return entity.Order{}, fmt.Errorf("failed to query order uid-1: %w", ctx.Err())
+}
+
+func TestBreakerStore_CallerContext(t *testing.T) {
+    newStore := func(err error) (Store, *breaker.Breaker) {
+        b := breaker.New(breaker.Config{Name: "database", FailureThreshold: 1, OpenTimeout: time.Minute, IsFailure: IsUnavailable})
+        return NewBreakerStore(&slowStore{err: err}, b), b
+    }
+
+    t.Run("Caller canceled", func(t *testing.T) {
+        store, b := newStore(nil)
+        ctx, cancel := context.WithCancel(context.Background())
+        cancel()
+        _, err := store.GetOrder(ctx, "uid-1")
+        assert.ErrorIs(t, err, context.Canceled)
+        assert.True(t, b.Ready())
+    })
+
+    t.Run("Caller deadline", func(t *testing.T) {
+        store, b := newStore(nil)
+        ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+        defer cancel()
+        _, err := store.GetOrder(ctx, "uid-1")
+        assert.ErrorIs(t, err, context.DeadlineExceeded)
+        assert.EqualError(t, err, "failed to query order uid-1: context deadline exceeded")
+        assert.True(t, b.Ready())
+    })
+
+    t.Run("Storage timeout", func(t *testing.T) {
+        // Таймаут самого хранилища при живом контексте вызывающего — сбой базы
+        store, b := newStore(fmt.Errorf("failed to query order uid-1: %w", context.DeadlineExceeded))
+        _, err := store.GetOrder(context.Background(), "uid-1")
+        assert.ErrorIs(t, err, context.DeadlineExceeded)
+        assert.False(t, b.Ready())
+    })
+}
@@ -20,23 +20,15 @@ func NewDatabaseConnection(cfg *config.Config) (*sql.DB, Store, error) {
 
 // NewPostgresRepository создает подключение к Postgres
 func NewPostgresRepository(cfg *config.Config) (*sql.DB, Store, error) {
-	connStr := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
-		cfg.DB.User,
-		cfg.DB.Password,
-		cfg.DB.Host,
-		cfg.DB.Port,
-		cfg.DB.Name,
-		cfg.DB.Mode,
-	)
-	db, err := sql.Open(cfg.DB.SType, connStr)
+	db, err := sql.Open(cfg.DB.SType, cfg.DB.DSN())
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to connect to database: %v", err)
+		return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
 	err = db.Ping()
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to ping database: %v", err)
+		return nil, nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return db, &Storage{db: db}, nil
+	return db, NewStorage(db), nil
 }
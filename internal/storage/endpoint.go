@@ -2,11 +2,41 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"iter"
 	"order/internal/entity"
+	"time"
 )
 
+// ErrNotFound возвращается, когда заказа нет в хранилище
+var ErrNotFound = errors.New("not found")
+
 type Store interface {
 	SaveOrder(ctx context.Context, order entity.Order) error
+	// SaveOrders сохраняет пачку заказов в одной транзакции: либо все, либо ни одного
+	SaveOrders(ctx context.Context, orders []entity.Order) error
+	UpsertOrder(ctx context.Context, order entity.Order) error
+	// UpsertOrders заменяет пачку заказов в одной транзакции: либо все, либо ни одного
+	UpsertOrders(ctx context.Context, orders []entity.Order) error
+	// ExistingOrders возвращает те из uids, что уже есть в хранилище (в том числе неполные)
+	ExistingOrders(ctx context.Context, uids []string) ([]string, error)
+	// GetOrder возвращает ErrNotFound для отсутствующего заказа и IncompleteOrder (ErrIncomplete)
+	// для заказа без доставки или оплаты
 	GetOrder(ctx context.Context, orderUID string) (entity.Order, error)
-	GetAllOrders(ctx context.Context) ([]entity.Order, error)
+	// Orders обходит полные заказы, прошедшие filter, по возрастанию order_uid с ограниченным
+	// расходом памяти; неполные пропускаются. Ошибка передаётся последним элементом, после неё
+	// обход прекращается
+	Orders(ctx context.Context, filter Filter) iter.Seq2[entity.Order, error]
+	// IncompleteOrders перечисляет заказы без доставки или оплаты
+	IncompleteOrders(ctx context.Context) ([]IncompleteOrder, error)
+	// FindOrders ищет заказы по вторичному ключу (см. Lookup); новые заказы первыми
+	FindOrders(ctx context.Context, lookup Lookup) ([]entity.Order, error)
+	// SearchOrders — полнотекстовый поиск заказов (см. Search) с ранжированием и подсветкой
+	SearchOrders(ctx context.Context, search Search) (SearchPage, error)
+	// SaveRates записывает курсы валют; курс той же пары на тот же день заменяется
+	SaveRates(ctx context.Context, rates []entity.ExchangeRate) error
+	// Rates возвращает курсы за дни с from по to включительно
+	Rates(ctx context.Context, from, to time.Time) ([]entity.ExchangeRate, error)
+	// DailyRevenue суммирует оплаты по дням (UTC) с from по to включительно и валютам
+	DailyRevenue(ctx context.Context, from, to time.Time) ([]DailyRevenue, error)
 }
@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrIncomplete возвращается для заказа, у которого в БД есть строка orders,
+// но нет доставки или оплаты (частичная запись или конфликт transaction с другим заказом)
+var ErrIncomplete = errors.New("incomplete order")
+
+// Части заказа, без которых он считается неполным. Заказ без товаров неполным не считается
+const (
+	PartDelivery = "delivery"
+	PartPayment  = "payment"
+)
+
+// IncompleteOrder описывает неполный заказ; как ошибка совпадает с ErrIncomplete
+type IncompleteOrder struct {
+	OrderUID string   `json:"order_uid"`
+	Missing  []string `json:"missing"`
+}
+
+func (o IncompleteOrder) Error() string {
+	return fmt.Sprintf("order %s is incomplete: missing %s", o.OrderUID, strings.Join(o.Missing, ", "))
+}
+
+func (o IncompleteOrder) Is(target error) bool {
+	return target == ErrIncomplete
+}
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"order/internal/entity"
+)
+
+// DefaultChunkSize — сколько заказов Orders читает одним запросом
+const DefaultChunkSize = 500
+
+// Filter отбирает заказы для обхода по одному ключу поиска, как Lookup; нулевой — все заказы
+type Filter struct {
+	Field LookupField
+	Value string
+}
+
+func (f Filter) Validate() error {
+	if f == (Filter{}) {
+		return nil
+	}
+	return Lookup{Field: f.Field, Value: f.Value}.Validate()
+}
+
+// Matches сообщает, проходит ли заказ фильтр
+func (f Filter) Matches(order entity.Order) bool {
+	return f == (Filter{}) || Lookup{Field: f.Field, Value: f.Value}.Matches(order)
+}
+
+// Collect читает последовательность целиком. Только для заведомо небольших выборок и тестов
+func Collect(orders iter.Seq2[entity.Order, error]) ([]entity.Order, error) {
+	var result []entity.Order
+	for order, err := range orders {
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, order)
+	}
+	return result, nil
+}
+
+// Orders обходит полные заказы, прошедшие filter, по возрастанию order_uid порциями по s.chunk (keyset-пагинация):
+// в памяти одновременно не больше одной порции, соединение между порциями не удерживается.
+// Заказы, записанные во время обхода, могут попасть или не попасть в него, но не повторяются
+func (s *Storage) Orders(ctx context.Context, filter Filter) iter.Seq2[entity.Order, error] {
+	return func(yield func(entity.Order, error) bool) {
+		if err := filter.Validate(); err != nil {
+			yield(entity.Order{}, err)
+			return
+		}
+		after := ""
+		for {
+			orders, last, err := s.ordersChunk(ctx, filter, after)
+			if err != nil {
+				yield(entity.Order{}, err)
+				return
+			}
+			for _, order := range orders {
+				if !yield(order, nil) {
+					return
+				}
+			}
+			if last == "" {
+				return
+			}
+			after = last
+		}
+	}
+}
+
+// ordersChunk читает до s.chunk заказов после after. last — order_uid, с которого продолжать;
+// пустой, если заказы закончились
+func (s *Storage) ordersChunk(ctx context.Context, filter Filter, after string) ([]entity.Order, string, error) {
+	where, args := "", []any{after, s.chunk}
+	if filter != (Filter{}) {
+		where = ` AND order_uid IN (` + lookupCondition(filter.Field, "$3") + `)`
+		args = append(args, filter.Value)
+	}
+	rows, err := s.db.QueryContext(ctx, `
+        WITH page AS (
+            SELECT order_uid
+            FROM orders
+            WHERE order_uid > $1`+where+`
+            ORDER BY order_uid
+            LIMIT $2
+        )`+selectOrders+`
+        INNER JOIN page ON o.order_uid = page.order_uid
+        ORDER BY o.order_uid, i.id`, args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to query orders after %q: %w", after, err)
+	}
+	defer rows.Close()
+
+	// Курсор — order_uid последней строки в порядке базы: неполные заказы тоже занимают место в порции
+	orders, incomplete, last, err := scanOrderRows(rows)
+	if err != nil {
+		return nil, "", err
+	}
+	logIncomplete(incomplete)
+
+	if len(orders)+len(incomplete) < s.chunk {
+		return orders, "", nil
+	}
+	return orders, last, nil
+}
@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// OrdersChannel — канал NOTIFY, в который Storage публикует order_uid записанных заказов
+const OrdersChannel = "order_changes"
+
+const (
+	// pingInterval — как часто проверяется соединение слушателя, если уведомлений нет
+	pingInterval = 90 * time.Second
+	// resyncRetry — пауза перед повторной попыткой полной пересинхронизации
+	resyncRetry = 5 * time.Second
+)
+
+// CacheInvalidator — кэш, который слушатель поддерживает в актуальном состоянии
+// (ему удовлетворяет service.Service)
+type CacheInvalidator interface {
+	// Evict убирает заказ из кэша
+	Evict(orderUID string)
+	// Refresh перечитывает заказ из БД, если он есть в кэше
+	Refresh(ctx context.Context, orderUID string) error
+	// Resync заменяет содержимое кэша актуальными данными из БД
+	Resync(ctx context.Context) error
+	// FlushCache очищает кэш
+	FlushCache() int
+}
+
+// Listener получает order_uid изменённых заказов из OrdersChannel и вытесняет
+// или обновляет их в кэше. После разрыва соединения уведомления за время разрыва
+// потеряны, поэтому кэш пересинхронизируется целиком
+type Listener struct {
+	notify  <-chan *pq.Notification
+	ping    func() error
+	close   func() error
+	cache   CacheInvalidator
+	refresh bool
+
+	resyncRetry time.Duration
+}
+
+// NewListener подписывается на OrdersChannel отдельным соединением. С refresh изменённые
+// заказы перечитываются из БД, без него — вытесняются из кэша
+func NewListener(dsn string, cache CacheInvalidator, refresh bool) (*Listener, error) {
+	listener := pq.NewListener(dsn, time.Second, 30*time.Second, func(event pq.ListenerEventType, err error) {
+		switch event {
+		case pq.ListenerEventDisconnected:
+			log.Printf("Order notifications listener disconnected: %v", err)
+		case pq.ListenerEventConnectionAttemptFailed:
+			log.Printf("Order notifications listener failed to reconnect: %v", err)
+		case pq.ListenerEventReconnected:
+			log.Printf("Order notifications listener reconnected")
+		}
+	})
+	if err := listener.Listen(OrdersChannel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen on %s: %w", OrdersChannel, err)
+	}
+	return newListener(listener.Notify, listener.Ping, listener.Close, cache, refresh), nil
+}
+
+func newListener(notify <-chan *pq.Notification, ping, close func() error, cache CacheInvalidator, refresh bool) *Listener {
+	return &Listener{
+		notify:      notify,
+		ping:        ping,
+		close:       close,
+		cache:       cache,
+		refresh:     refresh,
+		resyncRetry: resyncRetry,
+	}
+}
+
+// Run обрабатывает уведомления до отмены ctx
+func (l *Listener) Run(ctx context.Context) error {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	// retry срабатывает, пока не удалась пересинхронизация после разрыва
+	var retry <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case n, ok := <-l.notify:
+			if !ok {
+				return fmt.Errorf("notifications channel closed")
+			}
+			if n == nil {
+				// pq присылает nil после переподключения
+				retry = l.resync(ctx)
+				continue
+			}
+			l.invalidate(ctx, n.Extra)
+		case <-retry:
+			retry = l.resync(ctx)
+		case <-ticker.C:
+			if err := l.ping(); err != nil {
+				log.Printf("Order notifications listener ping failed: %v", err)
+			}
+		}
+	}
+}
+
+func (l *Listener) invalidate(ctx context.Context, orderUID string) {
+	if !l.refresh {
+		l.cache.Evict(orderUID)
+		return
+	}
+	if err := l.cache.Refresh(ctx, orderUID); err != nil {
+		log.Printf("Failed to refresh order %s: %v", orderUID, err)
+	}
+}
+
+// resync пересинхронизирует кэш; при неудаче очищает его, чтобы не отдавать устаревшие
+// заказы, и возвращает таймер повторной попытки
+func (l *Listener) resync(ctx context.Context) <-chan time.Time {
+	if err := l.cache.Resync(ctx); err != nil {
+		log.Printf("Failed to resync cache after missed notifications, retrying in %s: %v", l.resyncRetry, err)
+		l.cache.FlushCache()
+		return time.After(l.resyncRetry)
+	}
+	log.Printf("Cache resynced after missed notifications")
+	return nil
+}
+
+func (l *Listener) Close() error {
+	return l.close()
+}
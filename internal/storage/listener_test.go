@@ -0,0 +1,103 @@
+package storage
+
+import (
+    "context"
+    "errors"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/lib/pq"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+// fakeCache записывает вызовы слушателя
+type fakeCache struct {
+    mu        sync.Mutex
+    calls     []string
+    resyncErr []error // ошибки последовательных вызовов Resync
+}
+
+func (c *fakeCache) record(call string) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.calls = append(c.calls, call)
+}
+
+func (c *fakeCache) Evict(uid string) { c.record("evict " + uid) }
+
+func (c *fakeCache) Refresh(_ context.Context, uid string) error {
+    c.record("refresh " + uid)
+    return nil
+}
+
+func (c *fakeCache) Resync(context.Context) error {
+    c.record("resync")
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if len(c.resyncErr) == 0 {
+        return nil
+    }
+    err := c.resyncErr[0]
+    c.resyncErr = c.resyncErr[1:]
+    return err
+}
+
+func (c *fakeCache) FlushCache() int {
+    c.record("flush")
+    return 0
+}
+
+func (c *fakeCache) history() []string {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    return append([]string(nil), c.calls...)
+}
+
+func runListener(t *testing.T, cache *fakeCache, refresh bool) chan<- *pq.Notification {
+    notify := make(chan *pq.Notification)
+    noop := func() error { return nil }
+    l := newListener(notify, noop, noop, cache, refresh)
+    l.resyncRetry = 10 * time.Millisecond
+
+    ctx, cancel := context.WithCancel(context.Background())
+    done := make(chan error, 1)
+    go func() { done <- l.Run(ctx) }()
+    t.Cleanup(func() {
+        cancel()
+        require.NoError(t, <-done)
+    })
+    return notify
+}
+
+func TestListener(t *testing.T) {
+    t.Run("Evict", func(t *testing.T) {
+        cache := &fakeCache{}
+        notify := runListener(t, cache, false)
+
+        notify <- &pq.Notification{Channel: OrdersChannel, Extra: "uid-1"}
+        notify <- &pq.Notification{Channel: OrdersChannel, Extra: "uid-2"}
+        require.Eventually(t, func() bool { return len(cache.history()) == 2 }, time.Second, time.Millisecond)
+        assert.Equal(t, []string{"evict uid-1", "evict uid-2"}, cache.history())
+    })
+
+    t.Run("Refresh", func(t *testing.T) {
+        cache := &fakeCache{}
+        notify := runListener(t, cache, true)
+
+        notify <- &pq.Notification{Channel: OrdersChannel, Extra: "uid-1"}
+        require.Eventually(t, func() bool { return len(cache.history()) == 1 }, time.Second, time.Millisecond)
+        assert.Equal(t, []string{"refresh uid-1"}, cache.history())
+    })
+
+    t.Run("Resync after reconnect", func(t *testing.T) {
+        cache := &fakeCache{resyncErr: []error{errors.New("connection refused")}}
+        notify := runListener(t, cache, false)
+
+        // Первая пересинхронизация не удалась: кэш очищается, попытка повторяется
+        notify <- nil
+        require.Eventually(t, func() bool { return len(cache.history()) == 3 }, time.Second, time.Millisecond)
+        assert.Equal(t, []string{"resync", "flush", "resync"}, cache.history())
+    })
+}
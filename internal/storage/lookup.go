@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"order/internal/entity"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// LookupField — вторичный ключ поиска заказов
+type LookupField string
+
+const (
+	ByTrackNumber LookupField = "track_number"
+	ByCustomer    LookupField = "customer_id"
+	ByRid         LookupField = "rid"     // rid любого товара заказа
+	ByChrtID      LookupField = "chrt_id" // chrt_id любого товара заказа
+	ByNmID        LookupField = "nm_id"   // nm_id любого товара заказа
+	ByPhone       LookupField = "phone"   // телефон получателя
+	ByEmail       LookupField = "email"   // email получателя, без учёта регистра
+)
+
+// LookupFields — все поддерживаемые ключи поиска
+var LookupFields = []LookupField{ByTrackNumber, ByCustomer, ByRid, ByChrtID, ByNmID, ByPhone, ByEmail}
+
+const (
+	DefaultLookupLimit = 100
+	MaxLookupLimit     = 1000
+)
+
+// ErrInvalidLookup возвращается для неизвестного ключа или некорректного значения
+var ErrInvalidLookup = errors.New("invalid lookup")
+
+// LookupKey — нормализованная пара ключ-значение
+type LookupKey struct {
+	Field LookupField
+	Value string
+}
+
+// Lookup — поиск заказов по вторичному ключу; результат упорядочен от новых заказов к старым
+type Lookup struct {
+	Field LookupField
+	Value string
+	// Limit — максимум заказов в ответе; 0 — DefaultLookupLimit
+	Limit int
+}
+
+func (l Lookup) Validate() error {
+	if !slices.Contains(LookupFields, l.Field) {
+		return fmt.Errorf("%w: unknown field %q", ErrInvalidLookup, l.Field)
+	}
+	if l.Value == "" {
+		return fmt.Errorf("%w: empty %s", ErrInvalidLookup, l.Field)
+	}
+	if l.Field == ByChrtID || l.Field == ByNmID {
+		if _, err := strconv.ParseInt(l.Value, 10, 64); err != nil {
+			return fmt.Errorf("%w: %s must be an integer", ErrInvalidLookup, l.Field)
+		}
+	}
+	if l.Limit < 0 || l.Limit > MaxLookupLimit {
+		return fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidLookup, MaxLookupLimit)
+	}
+	return nil
+}
+
+// Key возвращает нормализованный ключ поиска
+func (l Lookup) Key() LookupKey {
+	value := l.Value
+	if l.Field == ByEmail {
+		value = strings.ToLower(value)
+	}
+	return LookupKey{Field: l.Field, Value: value}
+}
+
+// Size возвращает лимит с учётом значения по умолчанию
+func (l Lookup) Size() int {
+	if l.Limit == 0 {
+		return DefaultLookupLimit
+	}
+	return l.Limit
+}
+
+// Matches сообщает, находит ли поиск заказ
+func (l Lookup) Matches(order entity.Order) bool {
+	return slices.Contains(KeysOf(order), l.Key())
+}
+
+// KeysOf возвращает все ключи поиска, по которым находится заказ
+func KeysOf(order entity.Order) []LookupKey {
+	keys := make([]LookupKey, 0, 4+3*len(order.Items))
+	add := func(field LookupField, value string) {
+		if value == "" {
+			return
+		}
+		key := Lookup{Field: field, Value: value}.Key()
+		if !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	add(ByTrackNumber, order.TrackNumber)
+	add(ByCustomer, order.CustomerID)
+	add(ByPhone, order.Delivery.Phone)
+	add(ByEmail, order.Delivery.Email)
+	for _, item := range order.Items {
+		add(ByRid, item.Rid)
+		add(ByChrtID, strconv.FormatInt(item.ChrtID, 10))
+		add(ByNmID, strconv.FormatInt(item.NmID, 10))
+	}
+	return keys
+}
+
+// SortNewestFirst упорядочивает заказы так же, как FindOrders: по дате создания
+// от новых к старым, при равенстве — по order_uid
+func SortNewestFirst(orders []entity.Order) {
+	slices.SortStableFunc(orders, func(a, b entity.Order) int {
+		if c := b.DateCreated.Compare(a.DateCreated); c != 0 {
+			return c
+		}
+		return strings.Compare(a.OrderUID, b.OrderUID)
+	})
+}
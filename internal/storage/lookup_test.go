@@ -0,0 +1,62 @@
+package storage
+
+import (
+    "order/internal/entity"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func TestLookup_Validate(t *testing.T) {
+    tests := []struct {
+        name   string
+        lookup Lookup
+        valid  bool
+    }{
+        {"Track number", Lookup{Field: ByTrackNumber, Value: "WBILMTESTTRACK"}, true},
+        {"Item chrt_id", Lookup{Field: ByChrtID, Value: "9934930", Limit: 10}, true},
+        {"Unknown field", Lookup{Field: "name", Value: "John"}, false},
+        {"Empty value", Lookup{Field: ByPhone}, false},
+        {"Non-integer nm_id", Lookup{Field: ByNmID, Value: "abc"}, false},
+        {"Limit too large", Lookup{Field: ByRid, Value: "r", Limit: MaxLookupLimit + 1}, false},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            err := tt.lookup.Validate()
+            if tt.valid {
+                assert.NoError(t, err)
+            } else {
+                assert.ErrorIs(t, err, ErrInvalidLookup)
+            }
+        })
+    }
+}
+
+func TestLookup_Matches(t *testing.T) {
+    order := entity.Order{
+        OrderUID:    "uid-1",
+        TrackNumber: "TRACK",
+        CustomerID:  "test",
+        Delivery:    entity.Delivery{Phone: "+9720000000", Email: "Test@Gmail.com"},
+        Items:       []entity.Item{{ChrtID: 9934930, NmID: 2389212, Rid: "ab4219087a764ae0btest"}},
+    }
+
+    assert.True(t, Lookup{Field: ByEmail, Value: "test@gmail.COM"}.Matches(order))
+    assert.True(t, Lookup{Field: ByNmID, Value: "2389212"}.Matches(order))
+    assert.True(t, Lookup{Field: ByRid, Value: "ab4219087a764ae0btest"}.Matches(order))
+    assert.False(t, Lookup{Field: ByTrackNumber, Value: "track"}.Matches(order))
+    assert.False(t, Lookup{Field: ByCustomer, Value: "other"}.Matches(order))
+}
+
+func TestSortNewestFirst(t *testing.T) {
+    orders := []entity.Order{
+        {OrderUID: "b", DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC)},
+        {OrderUID: "c", DateCreated: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+        {OrderUID: "a", DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC)},
+    }
+    SortNewestFirst(orders)
+    assert.Equal(t, "c", orders[0].OrderUID)
+    assert.Equal(t, "a", orders[1].OrderUID)
+    assert.Equal(t, "b", orders[2].OrderUID)
+}
@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"order/internal/entity"
+	"order/internal/storage"
+	"slices"
+	"time"
+)
+
+// rateKey — первичный ключ exchange_rates
+type rateKey struct {
+	base, currency string
+	day            time.Time
+}
+
+func (s *Store) SaveRates(_ context.Context, rates []entity.ExchangeRate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, rate := range rates {
+		rate.Day = entity.Day(rate.Day)
+		s.rates[rateKey{base: rate.Base, currency: rate.Currency, day: rate.Day}] = rate
+	}
+	return nil
+}
+
+func (s *Store) Rates(_ context.Context, from, to time.Time) ([]entity.ExchangeRate, error) {
+	from, to = entity.Day(from), entity.Day(to)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var rates []entity.ExchangeRate
+	for _, rate := range s.rates {
+		if !rate.Day.Before(from) && !rate.Day.After(to) {
+			rates = append(rates, rate)
+		}
+	}
+	slices.SortFunc(rates, func(a, b entity.ExchangeRate) int {
+		return cmp.Or(a.Day.Compare(b.Day), cmp.Compare(a.Base, b.Base), cmp.Compare(a.Currency, b.Currency))
+	})
+	return rates, nil
+}
+
+// DailyRevenue группирует оплаты так же, как Postgres-хранилище: по дню payment_dt в UTC
+// (без него — date_created) и валюте
+func (s *Store) DailyRevenue(_ context.Context, from, to time.Time) ([]storage.DailyRevenue, error) {
+	from, to = entity.Day(from), entity.Day(to)
+	type group struct {
+		day      time.Time
+		currency string
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sums := make(map[group]*storage.DailyRevenue)
+	for _, uid := range s.uids {
+		order := s.orders[uid]
+		paidAt := order.Payment.PaymentDt
+		if paidAt.IsZero() {
+			paidAt = order.DateCreated
+		}
+		day := entity.Day(paidAt)
+		if paidAt.IsZero() || day.Before(from) || day.After(to) {
+			continue
+		}
+		g := group{day: day, currency: order.Payment.Currency}
+		sum, ok := sums[g]
+		if !ok {
+			sum = &storage.DailyRevenue{Day: day, Currency: g.currency}
+			sums[g] = sum
+		}
+		amount, err := sum.Amount.Add(order.Payment.Amount)
+		if err != nil {
+			return nil, err
+		}
+		sum.Amount = amount
+		sum.Orders++
+	}
+
+	revenue := make([]storage.DailyRevenue, 0, len(sums))
+	for _, sum := range sums {
+		revenue = append(revenue, *sum)
+	}
+	slices.SortFunc(revenue, func(a, b storage.DailyRevenue) int {
+		return cmp.Or(a.Day.Compare(b.Day), cmp.Compare(a.Currency, b.Currency))
+	})
+	return revenue, nil
+}
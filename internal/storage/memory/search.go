@@ -0,0 +1,218 @@
+package memory
+
+import (
+	"context"
+	"html"
+	"order/internal/entity"
+	"order/internal/storage"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// SearchOrders — упрощённый аналог полнотекстового поиска Postgres: слова сравниваются целиком
+// без учёта регистра, поддерживаются "фразы", -исключения и or. Ранг — число совпавших слов
+func (s *Store) SearchOrders(_ context.Context, search storage.Search) (storage.SearchPage, error) {
+	if err := search.Validate(); err != nil {
+		return storage.SearchPage{}, err
+	}
+	query := parseQuery(search.Text)
+
+	s.mu.RLock()
+	var hits []storage.SearchHit
+	for _, uid := range s.uids {
+		order := s.orders[uid]
+		if hit, ok := query.match(order); ok {
+			hit.Order = clone(order)
+			hits = append(hits, hit)
+		}
+	}
+	s.mu.RUnlock()
+
+	slices.SortStableFunc(hits, func(a, b storage.SearchHit) int {
+		if a.Rank != b.Rank {
+			if a.Rank > b.Rank {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Order.OrderUID, b.Order.OrderUID)
+	})
+	page := storage.SearchPage{Total: len(hits), Limit: search.Size(), Offset: search.Offset}
+	if search.Offset < len(hits) {
+		page.Hits = hits[search.Offset:min(search.Offset+page.Limit, len(hits))]
+	}
+	return page, nil
+}
+
+// term — слово или фраза запроса
+type term struct {
+	words   []string
+	negated bool
+}
+
+// query — альтернативы (через or), каждая из которых — набор обязательных условий
+type query [][]term
+
+func parseQuery(text string) query {
+	var q query
+	var group []term
+	for len(text) > 0 {
+		text = strings.TrimLeftFunc(text, unicode.IsSpace)
+		if text == "" {
+			break
+		}
+		negated := strings.HasPrefix(text, "-")
+		if negated {
+			text = text[1:]
+		}
+		var raw string
+		if strings.HasPrefix(text, `"`) {
+			end := strings.Index(text[1:], `"`)
+			if end < 0 {
+				raw, text = text[1:], ""
+			} else {
+				raw, text = text[1:end+1], text[end+2:]
+			}
+		} else {
+			end := strings.IndexFunc(text, unicode.IsSpace)
+			if end < 0 {
+				end = len(text)
+			}
+			raw, text = text[:end], text[end:]
+			if !negated && strings.EqualFold(raw, "or") {
+				if len(group) > 0 {
+					q = append(q, group)
+				}
+				group = nil
+				continue
+			}
+		}
+		var words []string
+		for _, token := range tokenize(raw) {
+			words = append(words, token.word)
+		}
+		if len(words) > 0 {
+			group = append(group, term{words: words, negated: negated})
+		}
+	}
+	if len(group) > 0 {
+		q = append(q, group)
+	}
+	return q
+}
+
+// match ищет запрос в получателе и в каждом товаре по отдельности, как Postgres по строкам таблиц
+func (q query) match(order entity.Order) (storage.SearchHit, bool) {
+	docs := [][]string{{order.Delivery.Name, order.Delivery.City, order.Delivery.Address}}
+	for _, item := range order.Items {
+		docs = append(docs, []string{item.Name, item.Brand})
+	}
+
+	var hit storage.SearchHit
+	found := false
+	for _, fields := range docs {
+		var tokens []string
+		for _, field := range fields {
+			for _, token := range tokenize(field) {
+				tokens = append(tokens, token.word)
+			}
+		}
+		words, ok := q.matchTokens(tokens)
+		if !ok {
+			continue
+		}
+		found = true
+		rank := 0
+		for _, token := range tokens {
+			if words[token] {
+				rank++
+			}
+		}
+		hit.Rank = max(hit.Rank, float64(rank))
+		for _, field := range fields {
+			if h, ok := highlight(field, words); ok && !slices.Contains(hit.Highlights, h) {
+				hit.Highlights = append(hit.Highlights, h)
+			}
+		}
+	}
+	slices.Sort(hit.Highlights)
+	return hit, found
+}
+
+// matchTokens возвращает слова совпавших альтернатив для подсветки
+func (q query) matchTokens(tokens []string) (map[string]bool, bool) {
+	words := make(map[string]bool)
+	found := false
+	for _, group := range q {
+		matched := true
+		for _, t := range group {
+			if containsPhrase(tokens, t.words) == t.negated {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		found = true
+		for _, t := range group {
+			if !t.negated {
+				for _, word := range t.words {
+					words[word] = true
+				}
+			}
+		}
+	}
+	return words, found
+}
+
+func containsPhrase(tokens, phrase []string) bool {
+	for i := 0; i+len(phrase) <= len(tokens); i++ {
+		if slices.Equal(tokens[i:i+len(phrase)], phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+type token struct {
+	word       string
+	start, end int // байтовые границы в исходном тексте
+}
+
+// tokenize разбивает текст на слова из букв и цифр в нижнем регистре
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		letter := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case letter && start < 0:
+			start = i
+		case !letter && start >= 0:
+			tokens = append(tokens, token{word: strings.ToLower(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{word: strings.ToLower(text[start:]), start: start, end: len(text)})
+	}
+	return tokens
+}
+
+// highlight экранирует поле как HTML и оборачивает совпавшие слова
+func highlight(field string, words map[string]bool) (string, bool) {
+	var b strings.Builder
+	last, found := 0, false
+	for _, t := range tokenize(field) {
+		if !words[t.word] {
+			continue
+		}
+		b.WriteString(html.EscapeString(field[last:t.start]))
+		b.WriteString(storage.HighlightStart + html.EscapeString(field[t.start:t.end]) + storage.HighlightStop)
+		last, found = t.end, true
+	}
+	b.WriteString(html.EscapeString(field[last:]))
+	return b.String(), found
+}
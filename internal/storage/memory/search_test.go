@@ -0,0 +1,84 @@
+package memory
+
+import (
+    "context"
+    "order/internal/entity"
+    "order/internal/storage"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func TestStore_SearchOrders(t *testing.T) {
+    ctx := context.Background()
+    store := NewStore()
+    orders := []entity.Order{
+        {
+            OrderUID: "uid-1",
+            Delivery: entity.Delivery{Name: "Test Testov", City: "Kiryat Mozkin", Address: "Ploshad Mira 15"},
+            Items:    []entity.Item{{Name: "Mascaras", Brand: "Vivienne Sabo"}},
+        },
+        {
+            OrderUID: "uid-2",
+            Delivery: entity.Delivery{Name: "Ivan <Ivanov>", City: "Moscow"},
+            Items:    []entity.Item{{Name: "Mascaras Mascaras", Brand: "Maybelline"}, {Name: "Lipstick", Brand: "Vivienne Sabo"}},
+        },
+    }
+    assert.NoError(t, store.SaveOrders(ctx, orders))
+
+    search := func(text string) storage.SearchPage {
+        page, err := store.SearchOrders(ctx, storage.Search{Text: text})
+        assert.NoError(t, err)
+        return page
+    }
+    uids := func(page storage.SearchPage) []string {
+        var uids []string
+        for _, hit := range page.Hits {
+            uids = append(uids, hit.Order.OrderUID)
+        }
+        return uids
+    }
+
+    t.Run("Ranked by matched words", func(t *testing.T) {
+        page := search("mascaras")
+        assert.Equal(t, 2, page.Total)
+        assert.Equal(t, []string{"uid-2", "uid-1"}, uids(page))
+        assert.Equal(t, []string{"<mark>Mascaras</mark> <mark>Mascaras</mark>"}, page.Hits[0].Highlights)
+    })
+
+    t.Run("All words must match one row", func(t *testing.T) {
+        assert.Equal(t, []string{"uid-2"}, uids(search("lipstick sabo")))
+        assert.Empty(t, search("moscow lipstick").Hits)
+    })
+
+    t.Run("Phrases, exclusions and or", func(t *testing.T) {
+        assert.Equal(t, []string{"uid-1"}, uids(search(`"ploshad mira"`)))
+        assert.Empty(t, search(`"mira ploshad"`).Hits)
+        assert.Equal(t, []string{"uid-1"}, uids(search("vivienne -lipstick")))
+        assert.Equal(t, []string{"uid-1", "uid-2"}, uids(search("mozkin or moscow")))
+    })
+
+    t.Run("Highlights are escaped", func(t *testing.T) {
+        page := search("ivanov")
+        assert.Equal(t, []string{"Ivan &lt;<mark>Ivanov</mark>&gt;"}, page.Hits[0].Highlights)
+    })
+
+    t.Run("Pagination", func(t *testing.T) {
+        page, err := store.SearchOrders(ctx, storage.Search{Text: "mascaras", Limit: 1, Offset: 1})
+        assert.NoError(t, err)
+        assert.Equal(t, 2, page.Total)
+        assert.Equal(t, []string{"uid-1"}, uids(page))
+
+        page, err = store.SearchOrders(ctx, storage.Search{Text: "mascaras", Offset: 5})
+        assert.NoError(t, err)
+        assert.Equal(t, 2, page.Total)
+        assert.Empty(t, page.Hits)
+    })
+
+    t.Run("Invalid search", func(t *testing.T) {
+        _, err := store.SearchOrders(ctx, storage.Search{Text: "  "})
+        assert.ErrorIs(t, err, storage.ErrInvalidSearch)
+        _, err = store.SearchOrders(ctx, storage.Search{Text: "a", Limit: storage.MaxSearchLimit + 1})
+        assert.ErrorIs(t, err, storage.ErrInvalidSearch)
+    })
+}
@@ -0,0 +1,149 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"order/internal/entity"
+	"order/internal/storage"
+	"slices"
+	"sync"
+)
+
+// Store — хранилище заказов в памяти с тем же поведением, что и Postgres-хранилище.
+// Используется в тестах и при локальном запуске без базы
+type Store struct {
+	mu     sync.RWMutex
+	orders map[string]entity.Order
+	uids   []string // порядок вставки
+	rates  map[rateKey]entity.ExchangeRate
+}
+
+func NewStore() storage.Store {
+	return &Store{orders: make(map[string]entity.Order), rates: make(map[rateKey]entity.ExchangeRate)}
+}
+
+// SaveOrder не перезаписывает существующий заказ, как ON CONFLICT DO NOTHING в Postgres
+func (s *Store) SaveOrder(_ context.Context, order entity.Order) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.insert(order)
+	return nil
+}
+
+func (s *Store) SaveOrders(_ context.Context, orders []entity.Order) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, order := range orders {
+		s.insert(order)
+	}
+	return nil
+}
+
+// insert вызывается под s.mu
+func (s *Store) insert(order entity.Order) {
+	if _, ok := s.orders[order.OrderUID]; ok {
+		return
+	}
+	s.orders[order.OrderUID] = clone(order)
+	s.uids = append(s.uids, order.OrderUID)
+}
+
+func (s *Store) UpsertOrder(_ context.Context, order entity.Order) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.orders[order.OrderUID]; !ok {
+		s.uids = append(s.uids, order.OrderUID)
+	}
+	s.orders[order.OrderUID] = clone(order)
+	return nil
+}
+
+func (s *Store) UpsertOrders(_ context.Context, orders []entity.Order) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, order := range orders {
+		if _, ok := s.orders[order.OrderUID]; !ok {
+			s.uids = append(s.uids, order.OrderUID)
+		}
+		s.orders[order.OrderUID] = clone(order)
+	}
+	return nil
+}
+
+func (s *Store) ExistingOrders(_ context.Context, uids []string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var existing []string
+	for _, uid := range uids {
+		if _, ok := s.orders[uid]; ok {
+			existing = append(existing, uid)
+		}
+	}
+	slices.Sort(existing)
+	return existing, nil
+}
+
+func (s *Store) GetOrder(_ context.Context, orderUID string) (entity.Order, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	order, ok := s.orders[orderUID]
+	if !ok {
+		return entity.Order{}, fmt.Errorf("order %s %w", orderUID, storage.ErrNotFound)
+	}
+	return clone(order), nil
+}
+
+// Orders обходит снимок заказов, прошедших filter, по возрастанию order_uid, как Postgres-хранилище
+func (s *Store) Orders(_ context.Context, filter storage.Filter) iter.Seq2[entity.Order, error] {
+	return func(yield func(entity.Order, error) bool) {
+		if err := filter.Validate(); err != nil {
+			yield(entity.Order{}, err)
+			return
+		}
+		s.mu.RLock()
+		uids := slices.Sorted(slices.Values(s.uids))
+		s.mu.RUnlock()
+		for _, uid := range uids {
+			s.mu.RLock()
+			order := clone(s.orders[uid])
+			s.mu.RUnlock()
+			if !filter.Matches(order) {
+				continue
+			}
+			if !yield(order, nil) {
+				return
+			}
+		}
+	}
+}
+
+// IncompleteOrders всегда пуст: заказ в памяти сохраняется целиком
+func (s *Store) IncompleteOrders(context.Context) ([]storage.IncompleteOrder, error) {
+	return nil, nil
+}
+
+func (s *Store) FindOrders(_ context.Context, lookup storage.Lookup) ([]entity.Order, error) {
+	if err := lookup.Validate(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var orders []entity.Order
+	for _, uid := range s.uids {
+		if lookup.Matches(s.orders[uid]) {
+			orders = append(orders, clone(s.orders[uid]))
+		}
+	}
+	storage.SortNewestFirst(orders)
+	if len(orders) > lookup.Size() {
+		orders = orders[:lookup.Size()]
+	}
+	return orders, nil
+}
+
+// clone копирует товары, чтобы вызывающий код не менял сохранённый заказ
+func clone(order entity.Order) entity.Order {
+	order.Items = append([]entity.Item(nil), order.Items...)
+	return order
+}
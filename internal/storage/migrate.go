@@ -0,0 +1,202 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"order/migrations"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/lib/pq"
+)
+
+// ErrSchemaOutdated возвращается CheckSchema, если схема БД отстаёт от встроенных миграций
+// или последняя миграция прервалась
+var ErrSchemaOutdated = errors.New("database schema is not up to date")
+
+// InitDB применяет все неприменённые миграции
+func InitDB(ctx context.Context, db *sql.DB) error {
+	m, err := NewMigrator(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Up(); err != nil {
+		return err
+	}
+	log.Println("Database migrations applied successfully")
+	return nil
+}
+
+// PrepareDB готовит БД к работе процесса: с autoMigrate применяет миграции (InitDB),
+// без него — только проверяет, что их уже применили (CheckSchema)
+func PrepareDB(ctx context.Context, db *sql.DB, autoMigrate bool) error {
+	if autoMigrate {
+		return InitDB(ctx, db)
+	}
+	return CheckSchema(ctx, db)
+}
+
+// CheckSchema проверяет, что применены все встроенные миграции. Схема новее бинарника
+// (миграции следующего релиза) допустима
+func CheckSchema(ctx context.Context, db *sql.DB) error {
+	status, err := GetMigrationStatus(ctx, db)
+	if err != nil {
+		return err
+	}
+	if status.Dirty || status.Pending() {
+		return fmt.Errorf("%w: version %d of %d (dirty: %t), run `orderctl migrate up`",
+			ErrSchemaOutdated, status.Version, status.Latest, status.Dirty)
+	}
+	return nil
+}
+
+// MigrationStatus — состояние схемы БД
+type MigrationStatus struct {
+	Version uint `json:"version"` // последняя применённая миграция; 0 — ни одной
+	Dirty   bool `json:"dirty"`   // миграция прервалась, схему нужно проверить вручную
+	Latest  uint `json:"latest"`  // последняя встроенная миграция
+}
+
+// Pending сообщает, есть ли неприменённые миграции
+func (s MigrationStatus) Pending() bool {
+	return s.Version < s.Latest
+}
+
+// GetMigrationStatus читает версию схемы, ничего не меняя в БД (в отличие от NewMigrator,
+// который создаёт служебную таблицу schema_migrations)
+func GetMigrationStatus(ctx context.Context, db *sql.DB) (MigrationStatus, error) {
+	var status MigrationStatus
+	err := db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).
+		Scan(&status.Version, &status.Dirty)
+	var pqErr *pq.Error
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case errors.As(err, &pqErr) && pqErr.Code == "42P01": // undefined_table: миграции не применялись
+	case err != nil:
+		return status, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	status.Latest, err = latestMigration()
+	return status, err
+}
+
+// latestMigration возвращает версию последней встроенной миграции
+func latestMigration() (uint, error) {
+	src, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return 0, fmt.Errorf("failed to open migrations: %w", err)
+	}
+	defer src.Close()
+
+	var latest uint
+	version, err := src.First()
+	for err == nil {
+		latest = version
+		version, err = src.Next(version)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return 0, fmt.Errorf("failed to list migrations: %w", err)
+	}
+	return latest, nil
+}
+
+// Migrator меняет схему по встроенным миграциям (migrations.FS). Занимает одно соединение
+// пула до Close; одновременно схему меняет только один Migrator (advisory lock Postgres),
+// поэтому экземпляры, стартующие вместе, не мешают друг другу
+type Migrator struct {
+	db *sql.DB
+	m  *migrate.Migrate
+}
+
+func NewMigrator(ctx context.Context, db *sql.DB) (*Migrator, error) {
+	src, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open migrations: %w", err)
+	}
+	// Отдельное соединение, а не WithInstance: Close мигратора закрыл бы весь пул db
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		src.Close()
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	driver, err := postgres.WithConnection(ctx, conn, &postgres.Config{})
+	if err != nil {
+		src.Close()
+		conn.Close()
+		return nil, fmt.Errorf("failed to create migrate driver: %w", err)
+	}
+	m, err := migrate.NewWithInstance("iofs", src, "postgres", driver)
+	if err != nil {
+		src.Close()
+		driver.Close()
+		return nil, fmt.Errorf("failed to create migrator: %w", err)
+	}
+	m.Log = migrateLogger{}
+	return &Migrator{db: db, m: m}, nil
+}
+
+// Up применяет все неприменённые миграции
+func (m *Migrator) Up() error {
+	return noChange(m.m.Up(), "apply migrations")
+}
+
+// Down откатывает steps последних миграций
+func (m *Migrator) Down(steps int) error {
+	if steps <= 0 {
+		return fmt.Errorf("steps must be positive, got %d", steps)
+	}
+	return noChange(m.m.Steps(-steps), "roll back migrations")
+}
+
+// To переводит схему к версии version вверх или вниз; 0 откатывает все миграции
+func (m *Migrator) To(version uint) error {
+	if version == 0 {
+		return noChange(m.m.Down(), "roll back migrations")
+	}
+	return noChange(m.m.Migrate(version), fmt.Sprintf("migrate to version %d", version))
+}
+
+// Force записывает version как применённую и снимает признак dirty, не выполняя SQL.
+// Нужен после ручного исправления прерванной миграции; -1 — «ни одной миграции»
+func (m *Migrator) Force(version int) error {
+	if err := m.m.Force(version); err != nil {
+		return fmt.Errorf("failed to force version %d: %w", version, err)
+	}
+	return nil
+}
+
+func (m *Migrator) Status(ctx context.Context) (MigrationStatus, error) {
+	return GetMigrationStatus(ctx, m.db)
+}
+
+// Close возвращает соединение в пул; сам пул db остаётся открытым
+func (m *Migrator) Close() error {
+	sourceErr, dbErr := m.m.Close()
+	return errors.Join(sourceErr, dbErr)
+}
+
+// noChange считает отсутствие изменений успехом
+func noChange(err error, action string) error {
+	if err == nil || errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return fmt.Errorf("failed to %s: %w", action, err)
+}
+
+// migrateLogger пишет в лог применённые миграции
+type migrateLogger struct{}
+
+func (migrateLogger) Printf(format string, v ...any) {
+	log.Printf("migrate: "+format, v...)
+}
+
+func (migrateLogger) Verbose() bool {
+	return false
+}
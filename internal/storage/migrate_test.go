@@ -0,0 +1,94 @@
+package storage
+
+import (
+    "context"
+    "io/fs"
+    "order/internal/entity"
+    "order/migrations"
+    "regexp"
+    "strconv"
+    "strings"
+    "testing"
+
+    "github.com/DATA-DOG/go-sqlmock"
+    "github.com/lib/pq"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestMigrations_Embedded(t *testing.T) {
+    ups, err := fs.Glob(migrations.FS, "*.up.sql")
+    assert.NoError(t, err)
+    assert.NotEmpty(t, ups)
+    // Каждую миграцию можно откатить
+    for _, up := range ups {
+        _, err := fs.Stat(migrations.FS, strings.TrimSuffix(up, ".up.sql")+".down.sql")
+        assert.NoError(t, err, up)
+    }
+
+    latest, err := latestMigration()
+    assert.NoError(t, err)
+    assert.EqualValues(t, len(ups), latest)
+}
+
+// Таблица currencies должна совпадать с показателями валют entity.Money
+func TestMigrations_Currencies(t *testing.T) {
+    data, err := fs.ReadFile(migrations.FS, "000004_money.up.sql")
+    assert.NoError(t, err)
+    rows := regexp.MustCompile(`\('([A-Z]{3})', (\d)\)`).FindAllStringSubmatch(string(data), -1)
+    assert.NotEmpty(t, rows)
+    for _, row := range rows {
+        exp, err := entity.CurrencyExponent(row[1])
+        assert.NoError(t, err)
+        assert.Equal(t, row[2], strconv.Itoa(exp), row[1])
+    }
+}
+
+func TestCheckSchema(t *testing.T) {
+    ctx := context.Background()
+    latest, err := latestMigration()
+    assert.NoError(t, err)
+
+    versionRows := func(version uint, dirty bool) *sqlmock.Rows {
+        return sqlmock.NewRows([]string{"version", "dirty"}).AddRow(version, dirty)
+    }
+
+    t.Run("Up to date", func(t *testing.T) {
+        store, mock := setupStorage(t)
+        mock.ExpectQuery("FROM schema_migrations").WillReturnRows(versionRows(latest, false))
+
+        assert.NoError(t, CheckSchema(ctx, store.db))
+    })
+
+    t.Run("Newer than the binary", func(t *testing.T) {
+        store, mock := setupStorage(t)
+        mock.ExpectQuery("FROM schema_migrations").WillReturnRows(versionRows(latest+1, false))
+
+        assert.NoError(t, CheckSchema(ctx, store.db))
+    })
+
+    t.Run("Pending", func(t *testing.T) {
+        store, mock := setupStorage(t)
+        mock.ExpectQuery("FROM schema_migrations").WillReturnRows(versionRows(latest-1, false))
+
+        assert.ErrorIs(t, CheckSchema(ctx, store.db), ErrSchemaOutdated)
+    })
+
+    t.Run("Dirty", func(t *testing.T) {
+        store, mock := setupStorage(t)
+        mock.ExpectQuery("FROM schema_migrations").WillReturnRows(versionRows(latest, true))
+
+        assert.ErrorIs(t, CheckSchema(ctx, store.db), ErrSchemaOutdated)
+    })
+
+    t.Run("Never migrated", func(t *testing.T) {
+        store, mock := setupStorage(t)
+        mock.ExpectQuery("FROM schema_migrations").WillReturnError(&pq.Error{Code: "42P01"})
+
+        status, err := GetMigrationStatus(ctx, store.db)
+        assert.NoError(t, err)
+        assert.Equal(t, MigrationStatus{Latest: latest}, status)
+
+        mock.ExpectQuery("FROM schema_migrations").WillReturnError(&pq.Error{Code: "42P01"})
+        assert.ErrorIs(t, CheckSchema(ctx, store.db), ErrSchemaOutdated)
+    })
+}
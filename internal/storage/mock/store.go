@@ -11,8 +11,11 @@ package mock
 
 import (
 	context "context"
+	iter "iter"
 	entity "order/internal/entity"
+	storage "order/internal/storage"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -41,19 +44,49 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
-// GetAllOrders mocks base method.
-func (m *MockStore) GetAllOrders(ctx context.Context) ([]entity.Order, error) {
+// DailyRevenue mocks base method.
+func (m *MockStore) DailyRevenue(ctx context.Context, from, to time.Time) ([]storage.DailyRevenue, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllOrders", ctx)
+	ret := m.ctrl.Call(m, "DailyRevenue", ctx, from, to)
+	ret0, _ := ret[0].([]storage.DailyRevenue)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DailyRevenue indicates an expected call of DailyRevenue.
+func (mr *MockStoreMockRecorder) DailyRevenue(ctx, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DailyRevenue", reflect.TypeOf((*MockStore)(nil).DailyRevenue), ctx, from, to)
+}
+
+// ExistingOrders mocks base method.
+func (m *MockStore) ExistingOrders(ctx context.Context, uids []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExistingOrders", ctx, uids)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExistingOrders indicates an expected call of ExistingOrders.
+func (mr *MockStoreMockRecorder) ExistingOrders(ctx, uids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExistingOrders", reflect.TypeOf((*MockStore)(nil).ExistingOrders), ctx, uids)
+}
+
+// FindOrders mocks base method.
+func (m *MockStore) FindOrders(ctx context.Context, lookup storage.Lookup) ([]entity.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOrders", ctx, lookup)
 	ret0, _ := ret[0].([]entity.Order)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAllOrders indicates an expected call of GetAllOrders.
-func (mr *MockStoreMockRecorder) GetAllOrders(ctx any) *gomock.Call {
+// FindOrders indicates an expected call of FindOrders.
+func (mr *MockStoreMockRecorder) FindOrders(ctx, lookup any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllOrders", reflect.TypeOf((*MockStore)(nil).GetAllOrders), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOrders", reflect.TypeOf((*MockStore)(nil).FindOrders), ctx, lookup)
 }
 
 // GetOrder mocks base method.
@@ -71,6 +104,50 @@ func (mr *MockStoreMockRecorder) GetOrder(ctx, orderUID any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockStore)(nil).GetOrder), ctx, orderUID)
 }
 
+// IncompleteOrders mocks base method.
+func (m *MockStore) IncompleteOrders(ctx context.Context) ([]storage.IncompleteOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncompleteOrders", ctx)
+	ret0, _ := ret[0].([]storage.IncompleteOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncompleteOrders indicates an expected call of IncompleteOrders.
+func (mr *MockStoreMockRecorder) IncompleteOrders(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncompleteOrders", reflect.TypeOf((*MockStore)(nil).IncompleteOrders), ctx)
+}
+
+// Orders mocks base method.
+func (m *MockStore) Orders(ctx context.Context, filter storage.Filter) iter.Seq2[entity.Order, error] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Orders", ctx, filter)
+	ret0, _ := ret[0].(iter.Seq2[entity.Order, error])
+	return ret0
+}
+
+// Orders indicates an expected call of Orders.
+func (mr *MockStoreMockRecorder) Orders(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Orders", reflect.TypeOf((*MockStore)(nil).Orders), ctx, filter)
+}
+
+// Rates mocks base method.
+func (m *MockStore) Rates(ctx context.Context, from, to time.Time) ([]entity.ExchangeRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rates", ctx, from, to)
+	ret0, _ := ret[0].([]entity.ExchangeRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rates indicates an expected call of Rates.
+func (mr *MockStoreMockRecorder) Rates(ctx, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rates", reflect.TypeOf((*MockStore)(nil).Rates), ctx, from, to)
+}
+
 // SaveOrder mocks base method.
 func (m *MockStore) SaveOrder(ctx context.Context, order entity.Order) error {
 	m.ctrl.T.Helper()
@@ -84,3 +161,74 @@ func (mr *MockStoreMockRecorder) SaveOrder(ctx, order any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOrder", reflect.TypeOf((*MockStore)(nil).SaveOrder), ctx, order)
 }
+
+// SaveOrders mocks base method.
+func (m *MockStore) SaveOrders(ctx context.Context, orders []entity.Order) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOrders", ctx, orders)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveOrders indicates an expected call of SaveOrders.
+func (mr *MockStoreMockRecorder) SaveOrders(ctx, orders any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOrders", reflect.TypeOf((*MockStore)(nil).SaveOrders), ctx, orders)
+}
+
+// SaveRates mocks base method.
+func (m *MockStore) SaveRates(ctx context.Context, rates []entity.ExchangeRate) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRates", ctx, rates)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveRates indicates an expected call of SaveRates.
+func (mr *MockStoreMockRecorder) SaveRates(ctx, rates any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRates", reflect.TypeOf((*MockStore)(nil).SaveRates), ctx, rates)
+}
+
+// SearchOrders mocks base method.
+func (m *MockStore) SearchOrders(ctx context.Context, search storage.Search) (storage.SearchPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchOrders", ctx, search)
+	ret0, _ := ret[0].(storage.SearchPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchOrders indicates an expected call of SearchOrders.
+func (mr *MockStoreMockRecorder) SearchOrders(ctx, search any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchOrders", reflect.TypeOf((*MockStore)(nil).SearchOrders), ctx, search)
+}
+
+// UpsertOrder mocks base method.
+func (m *MockStore) UpsertOrder(ctx context.Context, order entity.Order) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertOrder", ctx, order)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertOrder indicates an expected call of UpsertOrder.
+func (mr *MockStoreMockRecorder) UpsertOrder(ctx, order any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertOrder", reflect.TypeOf((*MockStore)(nil).UpsertOrder), ctx, order)
+}
+
+// UpsertOrders mocks base method.
+func (m *MockStore) UpsertOrders(ctx context.Context, orders []entity.Order) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertOrders", ctx, orders)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertOrders indicates an expected call of UpsertOrders.
+func (mr *MockStoreMockRecorder) UpsertOrders(ctx, orders any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertOrders", reflect.TypeOf((*MockStore)(nil).UpsertOrders), ctx, orders)
+}
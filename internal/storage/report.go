@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"order/internal/entity"
+	"time"
+)
+
+// DailyRevenue — сумма оплат за день в одной валюте. День оплаты — payment_dt в UTC,
+// для оплаты без времени — дата создания заказа
+type DailyRevenue struct {
+	Day      time.Time // полночь UTC
+	Currency string
+	Orders   int
+	Amount   entity.Money
+}
+
+// SaveRates записывает курсы одной транзакцией; курс на тот же день заменяется
+func (s *Storage) SaveRates(ctx context.Context, rates []entity.ExchangeRate) error {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		log.Printf("Failed to start transaction: %v", err)
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("Failed to rollback: %v", err)
+		}
+	}()
+
+	for _, rate := range rates {
+		_, err := tx.ExecContext(ctx, `
+            INSERT INTO exchange_rates (day, base, currency, rate)
+            VALUES ($1, $2, $3, $4)
+            ON CONFLICT (base, currency, day) DO UPDATE SET rate = EXCLUDED.rate`,
+			rate.Day, rate.Base, rate.Currency, entity.FormatRate(rate.Rate))
+		if err != nil {
+			return fmt.Errorf("failed to save rate %s/%s on %s: %w",
+				rate.Base, rate.Currency, rate.Day.Format(time.DateOnly), err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return err
+	}
+	return nil
+}
+
+// Rates возвращает курсы за дни с from по to включительно, по возрастанию дня
+func (s *Storage) Rates(ctx context.Context, from, to time.Time) ([]entity.ExchangeRate, error) {
+	rows, err := s.db.QueryContext(ctx, `
+        SELECT day, base, currency, rate::text
+        FROM exchange_rates
+        WHERE day BETWEEN $1 AND $2
+        ORDER BY day, base, currency`, entity.Day(from), entity.Day(to))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rates: %w", err)
+	}
+	defer rows.Close()
+
+	var rates []entity.ExchangeRate
+	for rows.Next() {
+		var day time.Time
+		var base, currency, rate string
+		if err := rows.Scan(&day, &base, &currency, &rate); err != nil {
+			return nil, fmt.Errorf("failed to scan rate: %w", err)
+		}
+		r, err := entity.ParseRate(rate)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, entity.ExchangeRate{Day: entity.Day(day), Base: base, Currency: currency, Rate: r})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return rates, nil
+}
+
+// DailyRevenue суммирует оплаты по дням с from по to включительно и валютам
+func (s *Storage) DailyRevenue(ctx context.Context, from, to time.Time) ([]DailyRevenue, error) {
+	rows, err := s.db.QueryContext(ctx, `
+        WITH paid AS (
+            SELECT COALESCE(p.payment_dt, o.date_created) AS paid_at,
+                COALESCE(p.currency, '') AS currency, COALESCE(p.amount, 0) AS amount
+            FROM payments p
+            INNER JOIN orders o ON o.order_uid = p.order_uid
+        )
+        SELECT (paid_at AT TIME ZONE 'UTC')::date, currency, COUNT(*), SUM(amount)::text
+        FROM paid
+        WHERE paid_at >= $1 AND paid_at < $2
+        GROUP BY 1, 2
+        ORDER BY 1, 2`, entity.Day(from), entity.Day(to).AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query revenue: %w", err)
+	}
+	defer rows.Close()
+
+	var revenue []DailyRevenue
+	for rows.Next() {
+		var r DailyRevenue
+		if err := rows.Scan(&r.Day, &r.Currency, &r.Orders, &r.Amount); err != nil {
+			return nil, fmt.Errorf("failed to scan revenue: %w", err)
+		}
+		r.Day = entity.Day(r.Day)
+		revenue = append(revenue, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return revenue, nil
+}
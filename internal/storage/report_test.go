@@ -0,0 +1,61 @@
+package storage
+
+import (
+    "context"
+    "order/internal/entity"
+    "testing"
+    "time"
+
+    "github.com/DATA-DOG/go-sqlmock"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func TestStorage_SaveRates(t *testing.T) {
+    store, mock := setupStorage(t)
+    rate, err := entity.NewExchangeRate(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), "EUR", "USD", "1.0876")
+    require.NoError(t, err)
+
+    mock.ExpectBegin()
+    mock.ExpectExec("INSERT INTO exchange_rates").
+        WithArgs(rate.Day, "EUR", "USD", "1.0876").
+        WillReturnResult(sqlmock.NewResult(0, 1))
+    mock.ExpectCommit()
+
+    assert.NoError(t, store.SaveRates(context.Background(), []entity.ExchangeRate{rate}))
+    assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStorage_Rates(t *testing.T) {
+    store, mock := setupStorage(t)
+    day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+    mock.ExpectQuery("FROM exchange_rates").WithArgs(day, day.AddDate(0, 0, 6)).
+        WillReturnRows(sqlmock.NewRows([]string{"day", "base", "currency", "rate"}).
+            AddRow(day, "EUR", "USD", "1.087600000000"))
+
+    rates, err := store.Rates(context.Background(), day.Add(10*time.Hour), day.AddDate(0, 0, 6))
+    require.NoError(t, err)
+    require.Len(t, rates, 1)
+    assert.Equal(t, "1.0876", entity.FormatRate(rates[0].Rate))
+    assert.Equal(t, day, rates[0].Day)
+}
+
+func TestStorage_DailyRevenue(t *testing.T) {
+    store, mock := setupStorage(t)
+    from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+    to := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+
+    // Верхняя граница — начало следующего дня: to входит в отчёт целиком
+    mock.ExpectQuery("FROM paid").WithArgs(from, to.AddDate(0, 0, 1)).
+        WillReturnRows(sqlmock.NewRows([]string{"day", "currency", "count", "sum"}).
+            AddRow(from, "EUR", 2, "30.5000").
+            AddRow(from, "USD", 1, "1817.0000"))
+
+    revenue, err := store.DailyRevenue(context.Background(), from, to)
+    require.NoError(t, err)
+    assert.Equal(t, []DailyRevenue{
+        {Day: from, Currency: "EUR", Orders: 2, Amount: entity.MoneyFromScaled(305_000)},
+        {Day: from, Currency: "USD", Orders: 1, Amount: entity.NewMoney(1817)},
+    }, revenue)
+}
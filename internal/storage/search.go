@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"order/internal/entity"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+	maxSearchQuery     = 256 // символов
+)
+
+// Поиск подсвечивает совпадения этими тегами; остальной текст поля экранируется как HTML
+const (
+	HighlightStart = "<mark>"
+	HighlightStop  = "</mark>"
+)
+
+// ErrInvalidSearch возвращается для пустого или слишком длинного запроса и неверной страницы
+var ErrInvalidSearch = errors.New("invalid search")
+
+// Search — полнотекстовый поиск по названиям и брендам товаров, имени, городу и адресу получателя.
+// Text разбирается как websearch_to_tsquery: слова через пробел (все обязательны), "фраза", -исключение
+type Search struct {
+	Text string
+	// Limit — размер страницы; 0 — DefaultSearchLimit
+	Limit  int
+	Offset int
+}
+
+func (s Search) Validate() error {
+	text := strings.TrimSpace(s.Text)
+	if text == "" {
+		return fmt.Errorf("%w: empty query", ErrInvalidSearch)
+	}
+	if utf8.RuneCountInString(text) > maxSearchQuery {
+		return fmt.Errorf("%w: query is longer than %d characters", ErrInvalidSearch, maxSearchQuery)
+	}
+	if s.Limit < 0 || s.Limit > MaxSearchLimit {
+		return fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidSearch, MaxSearchLimit)
+	}
+	if s.Offset < 0 {
+		return fmt.Errorf("%w: offset must not be negative", ErrInvalidSearch)
+	}
+	return nil
+}
+
+// Size возвращает размер страницы с учётом значения по умолчанию
+func (s Search) Size() int {
+	if s.Limit == 0 {
+		return DefaultSearchLimit
+	}
+	return s.Limit
+}
+
+// SearchHit — найденный заказ. Заказ находится, если весь запрос совпал с получателем
+// или с одним из товаров; Highlights — совпавшие поля с подсветкой
+type SearchHit struct {
+	Order      entity.Order `json:"order"`
+	Rank       float64      `json:"rank"`
+	Highlights []string     `json:"highlights"`
+}
+
+// SearchPage — страница результатов по убыванию релевантности; Total — всего найдено заказов
+type SearchPage struct {
+	Hits   []SearchHit `json:"hits"`
+	Total  int         `json:"total"`
+	Limit  int         `json:"limit"`
+	Offset int         `json:"offset"`
+}
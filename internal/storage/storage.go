@@ -6,15 +6,19 @@ import (
 	"fmt"
 	"log"
 	"order/internal/entity"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 // Реализация репозитория
 type Storage struct {
-	db *sql.DB
+	db    *sql.DB
+	chunk int // размер порции Orders
 }
 
 func NewStorage(db *sql.DB) Store {
-	return &Storage{db: db}
+	return &Storage{db: db, chunk: DefaultChunkSize}
 }
 
 func (s *Storage) SaveOrder(ctx context.Context, order entity.Order) error {
@@ -30,15 +34,168 @@ func (s *Storage) SaveOrder(ctx context.Context, order entity.Order) error {
 		}
 	}()
 
+	if err := insertOrder(ctx, tx, order); err != nil {
+		return err
+	}
+	if err := notifyOrders(ctx, tx, order.OrderUID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return err
+	}
+
+	log.Printf("Order %s saved successfully", order.OrderUID)
+	return nil
+}
+
+func (s *Storage) SaveOrders(ctx context.Context, orders []entity.Order) error {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		log.Printf("Failed to start transaction: %v", err)
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("Failed to rollback: %v", err)
+		}
+	}()
+
+	uids := make([]string, 0, len(orders))
+	for _, order := range orders {
+		if err := insertOrder(ctx, tx, order); err != nil {
+			return fmt.Errorf("order %s: %w", order.OrderUID, err)
+		}
+		uids = append(uids, order.OrderUID)
+	}
+	if err := notifyOrders(ctx, tx, uids...); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return err
+	}
+	return nil
+}
+
+// UpsertOrder полностью заменяет сохранённый заказ (вместе с доставкой, оплатой и товарами)
+func (s *Storage) UpsertOrder(ctx context.Context, order entity.Order) error {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		log.Printf("Failed to start transaction: %v", err)
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("Failed to rollback: %v", err)
+		}
+	}()
+
+	if err := replaceOrder(ctx, tx, order); err != nil {
+		return err
+	}
+	if err := notifyOrders(ctx, tx, order.OrderUID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return err
+	}
+
+	log.Printf("Order %s upserted successfully", order.OrderUID)
+	return nil
+}
+
+// UpsertOrders заменяет пачку заказов в одной транзакции: либо все, либо ни одного
+func (s *Storage) UpsertOrders(ctx context.Context, orders []entity.Order) error {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		log.Printf("Failed to start transaction: %v", err)
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("Failed to rollback: %v", err)
+		}
+	}()
+
+	uids := make([]string, 0, len(orders))
+	for _, order := range orders {
+		if err := replaceOrder(ctx, tx, order); err != nil {
+			return fmt.Errorf("order %s: %w", order.OrderUID, err)
+		}
+		uids = append(uids, order.OrderUID)
+	}
+	if err := notifyOrders(ctx, tx, uids...); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return err
+	}
+	return nil
+}
+
+// ExistingOrders возвращает те из uids, что уже есть в хранилище (в том числе неполные)
+func (s *Storage) ExistingOrders(ctx context.Context, uids []string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT order_uid FROM orders WHERE order_uid = ANY($1) ORDER BY order_uid`, pq.Array(uids))
+	if err != nil {
+		log.Printf("Failed to check existing orders: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var existing []string
+	for rows.Next() {
+		var uid string
+		if err := rows.Scan(&uid); err != nil {
+			return nil, err
+		}
+		existing = append(existing, uid)
+	}
+	return existing, rows.Err()
+}
+
+// replaceOrder удаляет заказ, если он есть, и записывает заново в рамках переданной транзакции
+func replaceOrder(ctx context.Context, tx *sql.Tx, order entity.Order) error {
+	// Связанные строки удаляются каскадно
+	_, err := tx.ExecContext(ctx, `DELETE FROM orders WHERE order_uid = $1`, order.OrderUID)
+	if err != nil {
+		log.Printf("Failed to delete order %s: %v", order.OrderUID, err)
+		return err
+	}
+	return insertOrder(ctx, tx, order)
+}
+
+// notifyOrders публикует order_uid в OrdersChannel. Уведомление отправляется в транзакции
+// записи: PostgreSQL доставит его слушателям только после коммита и не доставит при откате
+func notifyOrders(ctx context.Context, tx *sql.Tx, uids ...string) error {
+	_, err := tx.ExecContext(ctx,
+		`SELECT pg_notify($1, uid) FROM unnest($2::text[]) AS uid`,
+		OrdersChannel, pq.Array(uids))
+	if err != nil {
+		log.Printf("Failed to notify about orders %v: %v", uids, err)
+		return err
+	}
+	return nil
+}
+
+// insertOrder записывает заказ во все таблицы в рамках переданной транзакции
+func insertOrder(ctx context.Context, tx *sql.Tx, order entity.Order) error {
 	// Вставка в таблицу orders
-	_, err = tx.ExecContext(ctx, `
+	_, err := tx.ExecContext(ctx, `
         INSERT INTO orders (
             order_uid, track_number, entry, locale, internal_signature,
             customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard
         ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
         ON CONFLICT (order_uid) DO NOTHING`,
 		order.OrderUID, order.TrackNumber, order.Entry, order.Locale, order.InternalSignature,
-		order.CustomerID, order.DeliveryService, order.Shardkey, order.SmID, order.DateCreated, order.OofShard)
+		order.CustomerID, order.DeliveryService, order.Shardkey, order.SmID, nullTime(order.DateCreated), order.OofShard)
 	if err != nil {
 		log.Printf("Failed to insert order: %v", err)
 		return err
@@ -65,22 +222,23 @@ func (s *Storage) SaveOrder(ctx context.Context, order entity.Order) error {
         ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
         ON CONFLICT (transaction) DO NOTHING`,
 		order.Payment.Transaction, order.OrderUID, order.Payment.RequestID, order.Payment.Currency,
-		order.Payment.Provider, order.Payment.Amount, order.Payment.PaymentDt, order.Payment.Bank,
+		order.Payment.Provider, order.Payment.Amount, nullTime(order.Payment.PaymentDt), order.Payment.Bank,
 		order.Payment.DeliveryCost, order.Payment.GoodsTotal, order.Payment.CustomFee)
 	if err != nil {
 		log.Printf("Failed to insert payment: %v", err)
 		return err
 	}
 
-	// Вставка в таблицу items
-	for _, item := range order.Items {
+	// Вставка в таблицу items: позиция товара в заказе — часть уникального ключа,
+	// поэтому повторное сохранение того же заказа не дублирует товары
+	for position, item := range order.Items {
 		_, err = tx.ExecContext(ctx, `
             INSERT INTO items (
-                order_uid, chrt_id, track_number, price, rid, name,
+                order_uid, position, chrt_id, track_number, price, rid, name,
                 sale, size, total_price, nm_id, brand, status
-            ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
-            ON CONFLICT DO NOTHING`,
-			order.OrderUID, item.ChrtID, item.TrackNumber, item.Price, item.Rid, item.Name,
+            ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+            ON CONFLICT (order_uid, position) DO NOTHING`,
+			order.OrderUID, position, item.ChrtID, item.TrackNumber, item.Price, item.Rid, item.Name,
 			item.Sale, item.Size, item.TotalPrice, item.NmID, item.Brand, item.Status)
 		if err != nil {
 			log.Printf("Failed to insert item: %v", err)
@@ -88,139 +246,334 @@ func (s *Storage) SaveOrder(ctx context.Context, order entity.Order) error {
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
-		log.Printf("Failed to commit transaction: %v", err)
-		return err
-	}
-
-	log.Printf("Order %s saved successfully", order.OrderUID)
 	return nil
 }
 
+// GetOrder возвращает ErrNotFound, если строки orders нет, и IncompleteOrder,
+// если у заказа нет доставки или оплаты
 func (s *Storage) GetOrder(ctx context.Context, orderUID string) (entity.Order, error) {
-	rows, err := s.db.QueryContext(ctx, `
-        SELECT 
-            o.order_uid, o.track_number, o.entry, o.locale, o.internal_signature,
-            o.customer_id, o.delivery_service, o.shardkey, o.sm_id, o.date_created, o.oof_shard,
-            d.name, d.phone, d.zip, d.city, d.address, d.region, d.email,
-            p.transaction, p.request_id, p.currency, p.provider, p.amount, p.payment_dt,
-            p.bank, p.delivery_cost, p.goods_total, p.custom_fee,
-            i.chrt_id, i.track_number, i.price, i.rid, i.name, i.sale, i.size,
-            i.total_price, i.nm_id, i.brand, i.status
-        FROM orders o
-        INNER JOIN deliveries d ON o.order_uid = d.order_uid
-        INNER JOIN payments p ON o.order_uid = p.order_uid
-        INNER JOIN items i ON o.order_uid = i.order_uid
+	rows, err := s.db.QueryContext(ctx, selectOrders+`
         WHERE o.order_uid = $1
-        ORDER BY o.order_uid`, orderUID)
+        ORDER BY i.id`, orderUID)
 	if err != nil {
-		return entity.Order{}, fmt.Errorf("failed to query order %s: %v", orderUID, err)
+		return entity.Order{}, fmt.Errorf("failed to query order %s: %w", orderUID, err)
 	}
 	defer rows.Close()
 
-	var order entity.Order
-	var items []entity.Item
-	found := false
+	orders, incomplete, err := scanOrders(rows)
+	if err != nil {
+		return entity.Order{}, err
+	}
+	if len(incomplete) > 0 {
+		return entity.Order{}, incomplete[0]
+	}
+	if len(orders) == 0 {
+		return entity.Order{}, fmt.Errorf("order %s %w", orderUID, ErrNotFound)
+	}
+	return orders[0], nil
+}
+
+func (s *Storage) IncompleteOrders(ctx context.Context) ([]IncompleteOrder, error) {
+	rows, err := s.db.QueryContext(ctx, `
+        SELECT o.order_uid, d.order_uid IS NULL, p.order_uid IS NULL
+        FROM orders o
+        LEFT JOIN deliveries d ON o.order_uid = d.order_uid
+        LEFT JOIN payments p ON o.order_uid = p.order_uid
+        WHERE d.order_uid IS NULL OR p.order_uid IS NULL
+        ORDER BY o.order_uid`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query incomplete orders: %w", err)
+	}
+	defer rows.Close()
 
+	var orders []IncompleteOrder
 	for rows.Next() {
-		var item entity.Item
-		err := rows.Scan(
-			&order.OrderUID, &order.TrackNumber, &order.Entry, &order.Locale,
-			&order.InternalSignature, &order.CustomerID, &order.DeliveryService,
-			&order.Shardkey, &order.SmID, &order.DateCreated, &order.OofShard,
-			&order.Delivery.Name, &order.Delivery.Phone, &order.Delivery.Zip,
-			&order.Delivery.City, &order.Delivery.Address, &order.Delivery.Region,
-			&order.Delivery.Email,
-			&order.Payment.Transaction, &order.Payment.RequestID, &order.Payment.Currency,
-			&order.Payment.Provider, &order.Payment.Amount, &order.Payment.PaymentDt,
-			&order.Payment.Bank, &order.Payment.DeliveryCost, &order.Payment.GoodsTotal,
-			&order.Payment.CustomFee,
-			&item.ChrtID, &item.TrackNumber, &item.Price, &item.Rid, &item.Name,
-			&item.Sale, &item.Size, &item.TotalPrice, &item.NmID, &item.Brand, &item.Status,
-		)
-		if err != nil {
-			return entity.Order{}, fmt.Errorf("failed to scan order %s: %v", orderUID, err)
+		var uid string
+		var noDelivery, noPayment bool
+		if err := rows.Scan(&uid, &noDelivery, &noPayment); err != nil {
+			return nil, fmt.Errorf("failed to scan incomplete order: %w", err)
 		}
-
-		found = true
-		items = append(items, item)
+		orders = append(orders, incompleteOrder(uid, !noDelivery, !noPayment))
 	}
-
 	if err := rows.Err(); err != nil {
-		return entity.Order{}, fmt.Errorf("error iterating rows for order %s: %v", orderUID, err)
+		return nil, fmt.Errorf("error iterating rows: %w", err)
 	}
+	return orders, nil
+}
+
+// lookupConditions — условия отбора order_uid для каждого ключа поиска (%[1]s — параметр со значением)
+var lookupConditions = map[LookupField]string{
+	ByTrackNumber: `SELECT order_uid FROM orders WHERE track_number = %[1]s`,
+	ByCustomer:    `SELECT order_uid FROM orders WHERE customer_id = %[1]s`,
+	ByRid:         `SELECT order_uid FROM items WHERE rid = %[1]s`,
+	ByChrtID:      `SELECT order_uid FROM items WHERE chrt_id = %[1]s::bigint`,
+	ByNmID:        `SELECT order_uid FROM items WHERE nm_id = %[1]s::bigint`,
+	ByPhone:       `SELECT order_uid FROM deliveries WHERE phone = %[1]s`,
+	ByEmail:       `SELECT order_uid FROM deliveries WHERE lower(email) = lower(%[1]s)`,
+}
 
-	if !found {
-		return entity.Order{}, fmt.Errorf("order %s not found", orderUID)
+// lookupCondition возвращает условие для ключа field со значением в параметре param
+func lookupCondition(field LookupField, param string) string {
+	return fmt.Sprintf(lookupConditions[field], param)
+}
+
+// FindOrders ищет заказы по вторичному ключу; новые заказы первыми
+func (s *Storage) FindOrders(ctx context.Context, lookup Lookup) ([]entity.Order, error) {
+	if err := lookup.Validate(); err != nil {
+		return nil, err
+	}
+	rows, err := s.db.QueryContext(ctx, `
+        WITH matched AS (
+            SELECT o.order_uid, o.date_created
+            FROM orders o
+            WHERE o.order_uid IN (`+lookupCondition(lookup.Field, "$1")+`)
+            ORDER BY o.date_created DESC NULLS LAST, o.order_uid
+            LIMIT $2
+        )`+selectOrders+`
+        INNER JOIN matched m ON o.order_uid = m.order_uid
+        ORDER BY m.date_created DESC NULLS LAST, o.order_uid, i.id`, lookup.Value, lookup.Size())
+	if err != nil {
+		return nil, fmt.Errorf("failed to find orders by %s: %w", lookup.Field, err)
 	}
+	defer rows.Close()
 
-	order.Items = items
-	return order, nil
+	orders, incomplete, err := scanOrders(rows)
+	if err != nil {
+		return nil, err
+	}
+	logIncomplete(incomplete)
+	return orders, nil
 }
 
-func (s *Storage) GetAllOrders(ctx context.Context) ([]entity.Order, error) {
+// searchHeadline — параметры ts_headline: поля короткие, поэтому подсвечиваются целиком
+const searchHeadline = `StartSel=` + HighlightStart + `, StopSel=` + HighlightStop + `, HighlightAll=true`
+
+// SearchOrders ищет заказы по tsvector-колонкам search получателей и товаров (миграция 000003)
+func (s *Storage) SearchOrders(ctx context.Context, search Search) (SearchPage, error) {
+	if err := search.Validate(); err != nil {
+		return SearchPage{}, err
+	}
+	page := SearchPage{Limit: search.Size(), Offset: search.Offset}
+
+	// Строка с total возвращается всегда, даже если страница за пределами результатов
 	rows, err := s.db.QueryContext(ctx, `
-        SELECT 
-            o.order_uid, o.track_number, o.entry, o.locale, o.internal_signature,
-            o.customer_id, o.delivery_service, o.shardkey, o.sm_id, o.date_created, o.oof_shard,
-            d.name, d.phone, d.zip, d.city, d.address, d.region, d.email,
-            p.transaction, p.request_id, p.currency, p.provider, p.amount, p.payment_dt,
-            p.bank, p.delivery_cost, p.goods_total, p.custom_fee,
-            i.chrt_id, i.track_number, i.price, i.rid, i.name, i.sale, i.size,
-            i.total_price, i.nm_id, i.brand, i.status
-        FROM orders o
-        INNER JOIN deliveries d ON o.order_uid = d.order_uid
-        INNER JOIN payments p ON o.order_uid = p.order_uid
-        INNER JOIN items i ON o.order_uid = i.order_uid
-        ORDER BY o.order_uid`)
+        WITH query AS (
+            SELECT websearch_to_tsquery('simple', $1) AS q
+        ), matches AS (
+            SELECT d.order_uid, ts_rank(d.search, query.q) AS rank, ARRAY[d.name, d.city, d.address] AS fields
+            FROM deliveries d, query
+            WHERE d.search @@ query.q
+            UNION ALL
+            SELECT i.order_uid, ts_rank(i.search, query.q), ARRAY[i.name, i.brand]
+            FROM items i, query
+            WHERE i.search @@ query.q
+        ), grouped AS (
+            SELECT order_uid, max(rank) AS rank
+            FROM matches
+            GROUP BY order_uid
+        ), ranked AS (
+            SELECT order_uid, rank
+            FROM grouped
+            ORDER BY rank DESC, order_uid
+            LIMIT $2 OFFSET $3
+        )
+        SELECT t.total, r.order_uid, r.rank, ARRAY(
+            SELECT DISTINCT h FROM (
+                SELECT ts_headline('simple',
+                    replace(replace(replace(replace(replace(f, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&#34;'), '''', '&#39;'),
+                    query.q, $4) AS h
+                FROM matches m, unnest(m.fields) AS f, query
+                WHERE m.order_uid = r.order_uid AND f <> ''
+            ) headlines
+            WHERE strpos(h, $5) > 0
+            ORDER BY h
+        )
+        FROM (SELECT count(*) AS total FROM grouped) t
+        LEFT JOIN ranked r ON true
+        ORDER BY r.rank DESC, r.order_uid`, search.Text, page.Limit, page.Offset, searchHeadline, HighlightStart)
+	if err != nil {
+		return SearchPage{}, fmt.Errorf("failed to search orders: %w", err)
+	}
+	defer rows.Close()
+
+	var uids []string
+	for rows.Next() {
+		var uid sql.NullString
+		var rank sql.NullFloat64
+		var highlights []string
+		if err := rows.Scan(&page.Total, &uid, &rank, pq.Array(&highlights)); err != nil {
+			return SearchPage{}, fmt.Errorf("failed to scan search hit: %w", err)
+		}
+		if !uid.Valid {
+			continue
+		}
+		uids = append(uids, uid.String)
+		page.Hits = append(page.Hits, SearchHit{Order: entity.Order{OrderUID: uid.String}, Rank: rank.Float64, Highlights: highlights})
+	}
+	if err := rows.Err(); err != nil {
+		return SearchPage{}, fmt.Errorf("error iterating rows: %w", err)
+	}
+	if len(uids) == 0 {
+		return page, nil
+	}
+
+	orders, err := s.getOrders(ctx, uids)
+	if err != nil {
+		return SearchPage{}, err
+	}
+	hits := page.Hits[:0]
+	for _, hit := range page.Hits {
+		// Неполные заказы (без получателя или оплаты) getOrders не возвращает
+		if order, ok := orders[hit.Order.OrderUID]; ok {
+			hit.Order = order
+			hits = append(hits, hit)
+		}
+	}
+	page.Hits = hits
+	return page, nil
+}
+
+// getOrders читает заказы по списку order_uid
+func (s *Storage) getOrders(ctx context.Context, uids []string) (map[string]entity.Order, error) {
+	rows, err := s.db.QueryContext(ctx, selectOrders+`
+        WHERE o.order_uid = ANY($1)
+        ORDER BY o.order_uid, i.id`, pq.Array(uids))
 	if err != nil {
-		return nil, fmt.Errorf("failed to query all orders: %v", err)
+		return nil, fmt.Errorf("failed to query orders: %w", err)
 	}
 	defer rows.Close()
 
-	ordersMap := make(map[string]*entity.Order)
+	orders, incomplete, err := scanOrders(rows)
+	if err != nil {
+		return nil, err
+	}
+	logIncomplete(incomplete)
+	byUID := make(map[string]entity.Order, len(orders))
+	for _, order := range orders {
+		byUID[order.OrderUID] = order
+	}
+	return byUID, nil
+}
+
+// nullTime записывает нулевое время как NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+// utcTime читает TIMESTAMPTZ в UTC, как его разбирает entity; NULL — нулевое время
+func utcTime(t sql.NullTime) time.Time {
+	if !t.Valid {
+		return time.Time{}
+	}
+	return t.Time.UTC()
+}
+
+// selectOrders — выборка заказов со всеми связанными строками, по строке на товар.
+// Внешние соединения сохраняют заказ без товаров и позволяют заметить недостающие доставку и оплату;
+// NULL в необязательных колонках читаются как нулевые значения
+const selectOrders = `
+        SELECT
+            o.order_uid, COALESCE(o.track_number, ''), COALESCE(o.entry, ''), COALESCE(o.locale, ''),
+            COALESCE(o.internal_signature, ''), COALESCE(o.customer_id, ''), COALESCE(o.delivery_service, ''),
+            COALESCE(o.shardkey, ''), COALESCE(o.sm_id, 0), o.date_created, COALESCE(o.oof_shard, ''),
+            d.order_uid IS NOT NULL,
+            COALESCE(d.name, ''), COALESCE(d.phone, ''), COALESCE(d.zip, ''), COALESCE(d.city, ''),
+            COALESCE(d.address, ''), COALESCE(d.region, ''), COALESCE(d.email, ''),
+            p.order_uid IS NOT NULL,
+            COALESCE(p.transaction, ''), COALESCE(p.request_id, ''), COALESCE(p.currency, ''),
+            COALESCE(p.provider, ''), COALESCE(p.amount, 0), p.payment_dt, COALESCE(p.bank, ''),
+            COALESCE(p.delivery_cost, 0), COALESCE(p.goods_total, 0), COALESCE(p.custom_fee, 0),
+            i.id IS NOT NULL,
+            COALESCE(i.chrt_id, 0), COALESCE(i.track_number, ''), COALESCE(i.price, 0), COALESCE(i.rid, ''),
+            COALESCE(i.name, ''), COALESCE(i.sale, 0), COALESCE(i.size, ''), COALESCE(i.total_price, 0),
+            COALESCE(i.nm_id, 0), COALESCE(i.brand, ''), COALESCE(i.status, 0)
+        FROM orders o
+        LEFT JOIN deliveries d ON o.order_uid = d.order_uid
+        LEFT JOIN payments p ON o.order_uid = p.order_uid
+        LEFT JOIN items i ON o.order_uid = i.order_uid`
+
+// scanOrders собирает заказы из строк selectOrders в порядке их первого появления.
+// Строки одного заказа должны идти подряд. Заказы без доставки или оплаты возвращаются
+// отдельным списком и в orders не попадают
+func scanOrders(rows *sql.Rows) ([]entity.Order, []IncompleteOrder, error) {
+	orders, incomplete, _, err := scanOrderRows(rows)
+	return orders, incomplete, err
+}
+
+// scanOrderRows — scanOrders, который ещё возвращает order_uid последней прочитанной строки.
+// Порядок строк задаёт база по правилам сортировки колонки, и сравнение строк в Go
+// может с ним не совпадать
+func scanOrderRows(rows *sql.Rows) ([]entity.Order, []IncompleteOrder, string, error) {
+	var orders []entity.Order
+	var incomplete []IncompleteOrder
+	var last string
+	skip := false
 	for rows.Next() {
 		var order entity.Order
 		var item entity.Item
+		var dateCreated, paymentDt sql.NullTime
+		var hasDelivery, hasPayment, hasItem bool
 		err := rows.Scan(
 			&order.OrderUID, &order.TrackNumber, &order.Entry, &order.Locale,
 			&order.InternalSignature, &order.CustomerID, &order.DeliveryService,
-			&order.Shardkey, &order.SmID, &order.DateCreated, &order.OofShard,
+			&order.Shardkey, &order.SmID, &dateCreated, &order.OofShard,
+			&hasDelivery,
 			&order.Delivery.Name, &order.Delivery.Phone, &order.Delivery.Zip,
 			&order.Delivery.City, &order.Delivery.Address, &order.Delivery.Region,
 			&order.Delivery.Email,
+			&hasPayment,
 			&order.Payment.Transaction, &order.Payment.RequestID, &order.Payment.Currency,
-			&order.Payment.Provider, &order.Payment.Amount, &order.Payment.PaymentDt,
+			&order.Payment.Provider, &order.Payment.Amount, &paymentDt,
 			&order.Payment.Bank, &order.Payment.DeliveryCost, &order.Payment.GoodsTotal,
 			&order.Payment.CustomFee,
+			&hasItem,
 			&item.ChrtID, &item.TrackNumber, &item.Price, &item.Rid, &item.Name,
 			&item.Sale, &item.Size, &item.TotalPrice, &item.NmID, &item.Brand, &item.Status,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan order: %v", err)
+			return nil, nil, "", fmt.Errorf("failed to scan order: %w", err)
 		}
 
-		existingOrder, exists := ordersMap[order.OrderUID]
-		if !exists {
+		if order.OrderUID == last {
+			if !skip && hasItem {
+				orders[len(orders)-1].Items = append(orders[len(orders)-1].Items, item)
+			}
+			continue
+		}
+		last = order.OrderUID
+		skip = !hasDelivery || !hasPayment
+		if skip {
+			incomplete = append(incomplete, incompleteOrder(order.OrderUID, hasDelivery, hasPayment))
+			continue
+		}
+		order.DateCreated = utcTime(dateCreated)
+		order.Payment.PaymentDt = utcTime(paymentDt)
+		if hasItem {
 			order.Items = []entity.Item{item}
-			ordersMap[order.OrderUID] = &order
-		} else {
-			existingOrder.Items = append(existingOrder.Items, item)
 		}
+		orders = append(orders, order)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating rows: %v", err)
+		return nil, nil, "", fmt.Errorf("error iterating rows: %w", err)
 	}
+	return orders, incomplete, last, nil
+}
 
-	var orders []entity.Order
-	for _, order := range ordersMap {
-		orders = append(orders, *order)
+func incompleteOrder(orderUID string, hasDelivery, hasPayment bool) IncompleteOrder {
+	order := IncompleteOrder{OrderUID: orderUID}
+	if !hasDelivery {
+		order.Missing = append(order.Missing, PartDelivery)
 	}
-
-	if len(orders) == 0 {
-		log.Printf("No complete orders found in database")
+	if !hasPayment {
+		order.Missing = append(order.Missing, PartPayment)
 	}
+	return order
+}
 
-	return orders, nil
+// logIncomplete сообщает о пропущенных неполных заказах
+func logIncomplete(incomplete []IncompleteOrder) {
+	for _, order := range incomplete {
+		log.Printf("Skipping %v", order)
+	}
 }
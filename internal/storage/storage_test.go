@@ -0,0 +1,245 @@
+package storage
+
+import (
+    "context"
+    "database/sql/driver"
+    "errors"
+    "order/internal/entity"
+    "testing"
+    "time"
+
+    "github.com/DATA-DOG/go-sqlmock"
+    "github.com/lib/pq"
+    "github.com/stretchr/testify/assert"
+)
+
+var orderColumns = []string{
+    "order_uid", "track_number", "entry", "locale", "internal_signature", "customer_id",
+    "delivery_service", "shardkey", "sm_id", "date_created", "oof_shard",
+    "has_delivery", "name", "phone", "zip", "city", "address", "region", "email",
+    "has_payment", "transaction", "request_id", "currency", "provider", "amount", "payment_dt",
+    "bank", "delivery_cost", "goods_total", "custom_fee",
+    "has_item", "chrt_id", "track_number", "price", "rid", "name", "sale", "size",
+    "total_price", "nm_id", "brand", "status",
+}
+
+// orderRow — строка selectOrders; пустой rid означает заказ без товаров
+func orderRow(uid string, hasDelivery, hasPayment bool, rid string) []driver.Value {
+    return []driver.Value{
+        uid, "TRACK", "WBIL", "en", "", "test", "meest", "9", 99, time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC), "1",
+        hasDelivery, "Test Testov", "+9720000000", "2639809", "Kiryat Mozkin", "Ploshad Mira 15", "Kraiot", "test@gmail.com",
+        hasPayment, uid, "", "USD", "wbpay", 1817, time.Unix(1637907727, 0), "alpha", 1500, 317, 0,
+        rid != "", 9934930, "TRACK", 453, rid, "Mascaras", 30, "0", 317, 2389212, "Vivienne Sabo", 202,
+    }
+}
+
+func setupStorage(t *testing.T) (*Storage, sqlmock.Sqlmock) {
+    db, mock, err := sqlmock.New()
+    assert.NoError(t, err)
+    t.Cleanup(func() { db.Close() })
+    return &Storage{db: db}, mock
+}
+
+func TestStorage_GetOrder(t *testing.T) {
+    ctx := context.Background()
+
+    t.Run("Order without items", func(t *testing.T) {
+        store, mock := setupStorage(t)
+        mock.ExpectQuery("LEFT JOIN items").WithArgs("uid-1").
+            WillReturnRows(sqlmock.NewRows(orderColumns).AddRow(orderRow("uid-1", true, true, "")...))
+
+        order, err := store.GetOrder(ctx, "uid-1")
+        assert.NoError(t, err)
+        assert.Equal(t, "uid-1", order.OrderUID)
+        assert.Equal(t, "Test Testov", order.Delivery.Name)
+        assert.Nil(t, order.Items)
+    })
+
+    t.Run("Order with items", func(t *testing.T) {
+        store, mock := setupStorage(t)
+        mock.ExpectQuery("LEFT JOIN items").WithArgs("uid-1").
+            WillReturnRows(sqlmock.NewRows(orderColumns).
+                AddRow(orderRow("uid-1", true, true, "rid-1")...).
+                AddRow(orderRow("uid-1", true, true, "rid-2")...))
+
+        order, err := store.GetOrder(ctx, "uid-1")
+        assert.NoError(t, err)
+        assert.Len(t, order.Items, 2)
+        assert.Equal(t, "rid-2", order.Items[1].Rid)
+    })
+
+    t.Run("Missing payment", func(t *testing.T) {
+        store, mock := setupStorage(t)
+        mock.ExpectQuery("LEFT JOIN items").WithArgs("uid-1").
+            WillReturnRows(sqlmock.NewRows(orderColumns).AddRow(orderRow("uid-1", true, false, "rid-1")...))
+
+        _, err := store.GetOrder(ctx, "uid-1")
+        assert.ErrorIs(t, err, ErrIncomplete)
+        assert.Equal(t, IncompleteOrder{OrderUID: "uid-1", Missing: []string{PartPayment}}, err)
+        assert.False(t, IsUnavailable(err))
+    })
+
+    t.Run("Not found", func(t *testing.T) {
+        store, mock := setupStorage(t)
+        mock.ExpectQuery("LEFT JOIN items").WithArgs("uid-1").WillReturnRows(sqlmock.NewRows(orderColumns))
+
+        _, err := store.GetOrder(ctx, "uid-1")
+        assert.ErrorIs(t, err, ErrNotFound)
+    })
+
+    t.Run("Driver error keeps its class", func(t *testing.T) {
+        store, mock := setupStorage(t)
+        mock.ExpectQuery("LEFT JOIN items").WithArgs("uid-1").WillReturnError(&pq.Error{Code: "22P02"})
+
+        _, err := store.GetOrder(ctx, "uid-1")
+        var pqErr *pq.Error
+        assert.ErrorAs(t, err, &pqErr)
+        assert.False(t, IsUnavailable(err))
+    })
+}
+
+func TestStorage_Orders(t *testing.T) {
+    ctx := context.Background()
+
+    t.Run("Keyset chunks", func(t *testing.T) {
+        store, mock := setupStorage(t)
+        store.chunk = 2
+        mock.ExpectQuery(`WHERE order_uid > \$1`).WithArgs("", 2).
+            WillReturnRows(sqlmock.NewRows(orderColumns).
+                AddRow(orderRow("uid-1", true, true, "rid-1")...).
+                AddRow(orderRow("uid-1", true, true, "rid-2")...).
+                AddRow(orderRow("uid-2", false, false, "rid-3")...))
+        mock.ExpectQuery(`WHERE order_uid > \$1`).WithArgs("uid-2", 2).
+            WillReturnRows(sqlmock.NewRows(orderColumns).AddRow(orderRow("uid-3", true, true, "")...))
+
+        orders, err := Collect(store.Orders(ctx, Filter{}))
+        assert.NoError(t, err)
+        assert.Len(t, orders, 2)
+        assert.Equal(t, "uid-1", orders[0].OrderUID)
+        assert.Len(t, orders[0].Items, 2)
+        assert.Equal(t, "uid-3", orders[1].OrderUID)
+        assert.Equal(t, []entity.Item(nil), orders[1].Items)
+        assert.NoError(t, mock.ExpectationsWereMet())
+    })
+
+    t.Run("Cursor follows database order", func(t *testing.T) {
+        // С правилами сортировки базы (например, en_US) "uid-2" < "UID-3", а в Go — наоборот.
+        // Курсор берётся из последней строки порции, иначе UID-3 читался бы по кругу
+        store, mock := setupStorage(t)
+        store.chunk = 2
+        mock.ExpectQuery(`WHERE order_uid > \$1`).WithArgs("", 2).
+            WillReturnRows(sqlmock.NewRows(orderColumns).
+                AddRow(orderRow("uid-2", true, true, "")...).
+                AddRow(orderRow("UID-3", true, false, "")...))
+        mock.ExpectQuery(`WHERE order_uid > \$1`).WithArgs("UID-3", 2).
+            WillReturnRows(sqlmock.NewRows(orderColumns))
+
+        orders, err := Collect(store.Orders(ctx, Filter{}))
+        assert.NoError(t, err)
+        assert.Len(t, orders, 1)
+        assert.NoError(t, mock.ExpectationsWereMet())
+    })
+
+    t.Run("Stops when consumer stops", func(t *testing.T) {
+        store, mock := setupStorage(t)
+        store.chunk = 1
+        mock.ExpectQuery(`WHERE order_uid > \$1`).WithArgs("", 1).
+            WillReturnRows(sqlmock.NewRows(orderColumns).AddRow(orderRow("uid-1", true, true, "")...))
+
+        for order, err := range store.Orders(ctx, Filter{}) {
+            assert.NoError(t, err)
+            assert.Equal(t, "uid-1", order.OrderUID)
+            break
+        }
+        assert.NoError(t, mock.ExpectationsWereMet())
+    })
+
+    t.Run("Error ends iteration", func(t *testing.T) {
+        store, mock := setupStorage(t)
+        mock.ExpectQuery(`WHERE order_uid > \$1`).WillReturnError(errors.New("connection refused"))
+
+        _, err := Collect(store.Orders(ctx, Filter{}))
+        assert.Error(t, err)
+    })
+}
+
+func TestStorage_IncompleteOrders(t *testing.T) {
+    store, mock := setupStorage(t)
+    mock.ExpectQuery("WHERE d.order_uid IS NULL OR p.order_uid IS NULL").
+        WillReturnRows(sqlmock.NewRows([]string{"order_uid", "no_delivery", "no_payment"}).
+            AddRow("uid-1", true, true).
+            AddRow("uid-2", false, true))
+
+    orders, err := store.IncompleteOrders(context.Background())
+    assert.NoError(t, err)
+    assert.Equal(t, []IncompleteOrder{
+        {OrderUID: "uid-1", Missing: []string{PartDelivery, PartPayment}},
+        {OrderUID: "uid-2", Missing: []string{PartPayment}},
+    }, orders)
+    assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStorage_UpsertOrders(t *testing.T) {
+    orders := []entity.Order{{OrderUID: "uid-1"}, {OrderUID: "uid-2"}}
+
+    t.Run("Batch in one transaction", func(t *testing.T) {
+        store, mock := setupStorage(t)
+        mock.ExpectBegin()
+        for _, order := range orders {
+            mock.ExpectExec("DELETE FROM orders").WithArgs(order.OrderUID).WillReturnResult(sqlmock.NewResult(0, 1))
+            mock.ExpectExec("INSERT INTO orders").WillReturnResult(sqlmock.NewResult(0, 1))
+            mock.ExpectExec("INSERT INTO deliveries").WillReturnResult(sqlmock.NewResult(0, 1))
+            mock.ExpectExec("INSERT INTO payments").WillReturnResult(sqlmock.NewResult(0, 1))
+        }
+        mock.ExpectExec("pg_notify").WillReturnResult(sqlmock.NewResult(0, 2))
+        mock.ExpectCommit()
+
+        assert.NoError(t, store.UpsertOrders(context.Background(), orders))
+        assert.NoError(t, mock.ExpectationsWereMet())
+    })
+
+    t.Run("Failed order rolls back the batch", func(t *testing.T) {
+        store, mock := setupStorage(t)
+        mock.ExpectBegin()
+        mock.ExpectExec("DELETE FROM orders").WithArgs("uid-1").WillReturnResult(sqlmock.NewResult(0, 0))
+        mock.ExpectExec("INSERT INTO orders").WillReturnError(errors.New("value too long"))
+        mock.ExpectRollback()
+
+        err := store.UpsertOrders(context.Background(), orders)
+        assert.ErrorContains(t, err, "order uid-1: value too long")
+        assert.NoError(t, mock.ExpectationsWereMet())
+    })
+}
+
+func TestStorage_SaveOrderItemPositions(t *testing.T) {
+    store, mock := setupStorage(t)
+    order := entity.Order{OrderUID: "uid-1", Items: []entity.Item{{ChrtID: 1}, {ChrtID: 2}}}
+
+    // Повторное сохранение не дублирует товары: позиция входит в уникальный ключ
+    mock.ExpectBegin()
+    mock.ExpectExec("INSERT INTO orders").WillReturnResult(sqlmock.NewResult(0, 0))
+    mock.ExpectExec("INSERT INTO deliveries").WillReturnResult(sqlmock.NewResult(0, 0))
+    mock.ExpectExec("INSERT INTO payments").WillReturnResult(sqlmock.NewResult(0, 0))
+    for position, item := range order.Items {
+        mock.ExpectExec(`INSERT INTO items .* ON CONFLICT \(order_uid, position\) DO NOTHING`).
+            WithArgs("uid-1", position, item.ChrtID, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+                sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+            WillReturnResult(sqlmock.NewResult(0, 0))
+    }
+    mock.ExpectExec("pg_notify").WillReturnResult(sqlmock.NewResult(0, 1))
+    mock.ExpectCommit()
+
+    assert.NoError(t, store.SaveOrder(context.Background(), order))
+    assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStorage_ExistingOrders(t *testing.T) {
+    store, mock := setupStorage(t)
+    mock.ExpectQuery(`WHERE order_uid = ANY\(\$1\)`).
+        WillReturnRows(sqlmock.NewRows([]string{"order_uid"}).AddRow("uid-1").AddRow("uid-3"))
+
+    existing, err := store.ExistingOrders(context.Background(), []string{"uid-3", "uid-2", "uid-1"})
+    assert.NoError(t, err)
+    assert.Equal(t, []string{"uid-1", "uid-3"}, existing)
+    assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,8 @@
+-- Dropping lookup indexes
+DROP INDEX IF EXISTS idx_deliveries_email;
+DROP INDEX IF EXISTS idx_deliveries_phone;
+DROP INDEX IF EXISTS idx_items_nm_id;
+DROP INDEX IF EXISTS idx_items_chrt_id;
+DROP INDEX IF EXISTS idx_items_rid;
+DROP INDEX IF EXISTS idx_orders_customer_id;
+DROP INDEX IF EXISTS idx_orders_track_number;
@@ -0,0 +1,8 @@
+-- Creating indexes for order lookups by secondary keys
+CREATE INDEX idx_orders_track_number ON orders(track_number);
+CREATE INDEX idx_orders_customer_id ON orders(customer_id, date_created DESC);
+CREATE INDEX idx_items_rid ON items(rid);
+CREATE INDEX idx_items_chrt_id ON items(chrt_id);
+CREATE INDEX idx_items_nm_id ON items(nm_id);
+CREATE INDEX idx_deliveries_phone ON deliveries(phone);
+CREATE INDEX idx_deliveries_email ON deliveries(lower(email));
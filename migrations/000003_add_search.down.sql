@@ -0,0 +1,6 @@
+-- Dropping full-text search
+DROP INDEX IF EXISTS idx_items_search;
+DROP INDEX IF EXISTS idx_deliveries_search;
+
+ALTER TABLE items DROP COLUMN IF EXISTS search;
+ALTER TABLE deliveries DROP COLUMN IF EXISTS search;
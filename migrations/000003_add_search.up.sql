@@ -0,0 +1,12 @@
+-- Full-text search over delivery and item attributes
+-- The 'simple' configuration does no stemming, so Russian and English texts are handled alike
+ALTER TABLE deliveries ADD COLUMN search tsvector GENERATED ALWAYS AS (
+    to_tsvector('simple', coalesce(name, '') || ' ' || coalesce(city, '') || ' ' || coalesce(address, ''))
+) STORED;
+
+ALTER TABLE items ADD COLUMN search tsvector GENERATED ALWAYS AS (
+    to_tsvector('simple', coalesce(name, '') || ' ' || coalesce(brand, ''))
+) STORED;
+
+CREATE INDEX idx_deliveries_search ON deliveries USING GIN (search);
+CREATE INDEX idx_items_search ON items USING GIN (search);
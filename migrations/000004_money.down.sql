@@ -0,0 +1,12 @@
+-- Fractional amounts are rounded to whole units
+DROP TABLE IF EXISTS currencies;
+
+ALTER TABLE items
+    ALTER COLUMN price TYPE INTEGER USING round(price),
+    ALTER COLUMN total_price TYPE INTEGER USING round(total_price);
+
+ALTER TABLE payments
+    ALTER COLUMN amount TYPE INTEGER USING round(amount),
+    ALTER COLUMN delivery_cost TYPE INTEGER USING round(delivery_cost),
+    ALTER COLUMN goods_total TYPE INTEGER USING round(goods_total),
+    ALTER COLUMN custom_fee TYPE INTEGER USING round(custom_fee);
@@ -0,0 +1,6 @@
+-- Reverting to UTC wall-clock timestamps and Unix seconds
+ALTER TABLE payments
+    ALTER COLUMN payment_dt TYPE BIGINT USING COALESCE(extract(epoch FROM payment_dt)::BIGINT, 0);
+
+ALTER TABLE orders
+    ALTER COLUMN date_created TYPE TIMESTAMP USING date_created AT TIME ZONE 'UTC';
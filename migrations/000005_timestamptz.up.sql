@@ -0,0 +1,7 @@
+-- Times become TIMESTAMPTZ. date_created held RFC 3339 strings with the zone dropped, so the
+-- stored wall clock is taken as UTC; payment_dt held Unix seconds, where 0 meant "not set"
+ALTER TABLE orders
+    ALTER COLUMN date_created TYPE TIMESTAMPTZ USING date_created AT TIME ZONE 'UTC';
+
+ALTER TABLE payments
+    ALTER COLUMN payment_dt TYPE TIMESTAMPTZ USING to_timestamp(NULLIF(payment_dt, 0));
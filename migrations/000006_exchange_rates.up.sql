@@ -0,0 +1,12 @@
+-- Daily exchange rates: one unit of base costs rate units of currency on day.
+-- Loaded from a file or a provider (internal/rates); reports convert payments by the rate
+-- of the payment day or the latest earlier one
+CREATE TABLE exchange_rates (
+    day DATE NOT NULL,
+    base CHAR(3) NOT NULL REFERENCES currencies (code),
+    currency CHAR(3) NOT NULL REFERENCES currencies (code),
+    rate NUMERIC(30, 12) NOT NULL CHECK (rate > 0),
+    PRIMARY KEY (base, currency, day),
+    CHECK (base <> currency)
+);
+
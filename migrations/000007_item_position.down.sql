@@ -0,0 +1,3 @@
+ALTER TABLE items DROP CONSTRAINT IF EXISTS items_order_uid_position_key;
+
+ALTER TABLE items DROP COLUMN IF EXISTS position;
@@ -0,0 +1,15 @@
+-- Position of an item within its order. Together with order_uid it is a unique key, so
+-- re-saving an order (a redelivered message, a resumed backfill) does not duplicate its items.
+-- Existing rows are numbered in insertion order; earlier duplicates keep their higher positions
+ALTER TABLE items ADD COLUMN position INTEGER;
+
+UPDATE items SET position = numbered.position
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY order_uid ORDER BY id) - 1 AS position
+    FROM items
+) numbered
+WHERE items.id = numbered.id;
+
+ALTER TABLE items ALTER COLUMN position SET NOT NULL;
+
+ALTER TABLE items ADD CONSTRAINT items_order_uid_position_key UNIQUE (order_uid, position);
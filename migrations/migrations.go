@@ -0,0 +1,10 @@
+// Package migrations встраивает SQL-миграции схемы в бинарники, чтобы они не зависели
+// от рабочего каталога
+package migrations
+
+import "embed"
+
+// FS — файлы миграций golang-migrate: <версия>_<имя>.up.sql и .down.sql
+//
+//go:embed *.sql
+var FS embed.FS
@@ -6,7 +6,13 @@ export DB_HOST=$(hostname -I | awk '{print $1}')
 export DB_TYPE='postgres'
 export DB_SSLMODE=disable
 export DB_PORT=5432
+export DB_BREAKER_THRESHOLD=5
+export DB_BREAKER_OPEN_TIMEOUT=1s
+export DB_BREAKER_MAX_OPEN_TIMEOUT=30s
 export APP_PORT=':8080'
+export APP_ROLE=all
+export SHUTDOWN_TIMEOUT=30s
+export ADMIN_TOKEN='change-me'
 
 export DB_USER='order_service'
 export DB_NAME='order_service'
@@ -17,7 +23,25 @@ export KAFKA_PORT_1=9091
 export KAFKA_PORT_2=9092
 export KAFKA_PORT_3=9093
 export KAFKA_TOPIC='order'
+# Вместо KAFKA_HOST/KAFKA_PORT_* можно задать произвольный список брокеров
+# export KAFKA_BROKERS=host1:9092,host2:9092
+# Подписка консюмера на несколько топиков или шаблон (начинается с ^)
+# export KAFKA_TOPICS=order,^orders\.eu\..*
 export KAFKA_GROUP_NAME='order-group'
+export KAFKA_VALUE_FORMAT=json
+# export SCHEMA_REGISTRY_URL=http://localhost:8085
+
+# Кэш: all — все заказы, partitioned — только заказы назначенных партиций
+export CACHE_MODE=all
+export CACHE_INVALIDATION=evict
+
+# Источники заказов: kafka, nats или оба через запятую
+export INGEST_SOURCES=kafka
+# export NATS_URL=nats://localhost:4222
+# export NATS_STREAM=ORDERS
+# export NATS_SUBJECTS='orders.>'
+# export NATS_DURABLE=order-service
+# export NATS_QUARANTINE_SUBJECT=orders-quarantine
 
 export FRONT_HOST=localhost
 export FRONT_PORT=8081